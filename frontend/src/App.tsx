@@ -3,13 +3,14 @@ import { useEffect, useRef, useState } from "react";
 import { Badge } from "@/components/ui/badge";
 import { Button } from "@/components/ui/button";
 import { Card } from "@/components/ui/card";
+import { formatBytes } from "@/lib/utils";
 import { ConfigEditor } from "./components/ConfigEditor";
 import { GlobalScripts } from "./components/GlobalScripts";
 import { LogsViewer } from "./components/LogsViewer";
 import { ManagedServices } from "./components/ManagedServices";
 import { ProjectCard } from "./components/ProjectCard";
 import { useProjects } from "./hooks/useProjects";
-import { api } from "./services/wails";
+import { api, type CertificateAuthority } from "./services/wails";
 import type { AppStatus } from "./types/project";
 
 function App() {
@@ -30,6 +31,13 @@ function App() {
 		[],
 	);
 	const [configEditorOpen, setConfigEditorOpen] = useState(false);
+	const [certificateAuthority, setCertificateAuthority] = useState<CertificateAuthority | null>(null);
+
+	useEffect(() => {
+		api.getCertificateAuthority()
+			.then(setCertificateAuthority)
+			.catch(() => setCertificateAuthority(null));
+	}, []);
 
 	useEffect(() => {
 		const loadStatus = async () => {
@@ -64,7 +72,8 @@ function App() {
 	useEffect(() => {
 		const prev = prevStatusesRef.current;
 		for (const project of projects) {
-			if (project.status === "error" && prev[project.id] && prev[project.id] !== "error") {
+			const failed = project.status === "error" || project.status === "crash_loop";
+			if (failed && prev[project.id] && prev[project.id] !== project.status) {
 				setSelectedProjectId(project.id);
 			}
 		}
@@ -105,13 +114,23 @@ function App() {
 		}
 	};
 
-	const handleRestartTraefik = async () => {
+	const handleRestartProxy = async () => {
 		try {
-			await api.restartTraefik();
+			await api.restartProxy();
 			const data = await api.getStatus();
 			setStatus(data);
 		} catch (err) {
-			console.error("Error restarting traefik:", err);
+			console.error("Error restarting proxy:", err);
+		}
+	};
+
+	const handleTrustCertificateAuthority = async () => {
+		try {
+			setCertificateAuthority(await api.trustCertificateAuthority());
+		} catch (err) {
+			console.error("Error trusting certificate authority:", err);
+			const message = err instanceof Error ? err.message : String(err);
+			alert(`Failed to trust the local CA:\n\n${message}`);
 		}
 	};
 
@@ -192,28 +211,63 @@ function App() {
 									<span className="text-gray-400">Stopped:</span>
 									<span className="font-semibold text-white">{status.stopped}</span>
 								</div>
+								{status.running > 0 && (
+									<div className="flex items-center gap-2 text-sm">
+										<span className="text-gray-400">Resources:</span>
+										<span className="font-semibold text-white">
+											{status.cpu_used.toFixed(1)}% CPU · {formatBytes(status.memory_used)}
+										</span>
+									</div>
+								)}
 								<div className="flex items-center gap-2 text-sm">
-									<span className="text-gray-400">Traefik:</span>
+									<span className="text-gray-400">Proxy:</span>
 									<Badge
 										variant="secondary"
+										title={status.proxy_provider === "builtin" ? "Built-in reverse proxy" : "Traefik"}
 										className={
-											status.traefik_running
+											status.proxy_running
 												? "bg-green-500/20 text-green-400 border-green-500/30 font-semibold"
 												: "text-gray-400"
 										}
 									>
-										{status.traefik_running ? "Active" : "Inactive"}
+										{status.proxy_running ? "Active" : "Inactive"}
 									</Badge>
-									{!status.traefik_running && (
+									{!status.proxy_running && (
 										<button
 											type="button"
-											onClick={handleRestartTraefik}
+											onClick={handleRestartProxy}
 											className="text-xs text-yellow-400 underline hover:text-yellow-300 transition-colors"
 										>
 											Fix
 										</button>
 									)}
 								</div>
+								{certificateAuthority && (
+									<div className="flex items-center gap-2 text-sm">
+										<span className="text-gray-400">HTTPS:</span>
+										<Badge
+											variant="secondary"
+											title={`${certificateAuthority.subject}\n${certificateAuthority.path}`}
+											className={
+												certificateAuthority.trusted
+													? "bg-green-500/20 text-green-400 border-green-500/30 font-semibold"
+													: "text-gray-400"
+											}
+										>
+											{certificateAuthority.trusted ? "Trusted" : "Untrusted"}
+										</Badge>
+										{!certificateAuthority.trusted && (
+											<button
+												type="button"
+												onClick={handleTrustCertificateAuthority}
+												title="Instalar a CA local no repositório de confiança do sistema"
+												className="text-xs text-yellow-400 underline hover:text-yellow-300 transition-colors"
+											>
+												Trust
+											</button>
+										)}
+									</div>
+								)}
 							</div>
 						</Card>
 					)}
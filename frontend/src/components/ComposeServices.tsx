@@ -0,0 +1,60 @@
+import { useEffect, useState } from "react";
+import { Badge } from "@/components/ui/badge";
+import { cn } from "@/lib/utils";
+import { api } from "../services/wails";
+import type { ServiceStatus } from "../types/project";
+
+interface ComposeServicesProps {
+	projectId: string;
+}
+
+export function ComposeServices({ projectId }: ComposeServicesProps) {
+	const [services, setServices] = useState<ServiceStatus[]>([]);
+
+	useEffect(() => {
+		const loadServices = async () => {
+			try {
+				const data = await api.getProjectServices(projectId);
+				setServices(data);
+			} catch (err) {
+				console.error("Error loading services:", err);
+			}
+		};
+
+		loadServices();
+		const interval = setInterval(loadServices, 5000);
+
+		return () => clearInterval(interval);
+	}, [projectId]);
+
+	if (services.length === 0) {
+		return null;
+	}
+
+	return (
+		<div className="space-y-1.5 rounded-md border border-zinc-800 bg-zinc-950/50 p-3">
+			{services.map((service) => (
+				<div key={service.name} className="flex items-center justify-between gap-2 text-xs">
+					<div className="flex items-center gap-2 min-w-0">
+						<span
+							className={cn(
+								"h-1.5 w-1.5 rounded-full shrink-0",
+								service.state === "running" ? "bg-green-500" : "bg-zinc-500",
+							)}
+						/>
+						<span className="truncate text-gray-300">{service.name}</span>
+						{service.main && (
+							<Badge variant="secondary" className="text-[10px] bg-blue-600/20 text-blue-400 border-blue-500/40">
+								main
+							</Badge>
+						)}
+					</div>
+					<span className="text-gray-500 shrink-0">
+						{service.port ? `:${service.port} · ` : ""}
+						{service.status || service.state}
+					</span>
+				</div>
+			))}
+		</div>
+	);
+}
@@ -0,0 +1,112 @@
+import { FileCog } from "lucide-react";
+import { useEffect, useState } from "react";
+import { Button } from "@/components/ui/button";
+import {
+	Dialog,
+	DialogContent,
+	DialogDescription,
+	DialogFooter,
+	DialogHeader,
+	DialogTitle,
+} from "@/components/ui/dialog";
+import { ScrollArea } from "@/components/ui/scroll-area";
+import { api, type EnvFilePreview } from "../services/wails";
+
+interface EnvFileModalProps {
+	projectId: string;
+	onClose: () => void;
+}
+
+function diffLineClass(line: string) {
+	if (line.startsWith("+++") || line.startsWith("---")) {
+		return "text-muted-foreground";
+	}
+	if (line.startsWith("@@")) {
+		return "text-blue-400";
+	}
+	if (line.startsWith("+")) {
+		return "text-green-400 bg-green-500/10";
+	}
+	if (line.startsWith("-")) {
+		return "text-red-400 bg-red-500/10";
+	}
+	return "text-gray-400";
+}
+
+export function EnvFileModal({ projectId, onClose }: EnvFileModalProps) {
+	const [preview, setPreview] = useState<EnvFilePreview | null>(null);
+	const [error, setError] = useState<string | null>(null);
+	const [writing, setWriting] = useState(false);
+
+	useEffect(() => {
+		api.previewProjectEnv(projectId)
+			.then(setPreview)
+			.catch((err) => setError(err instanceof Error ? err.message : String(err)));
+	}, [projectId]);
+
+	const handleApply = async () => {
+		setWriting(true);
+		try {
+			await api.setupProjectEnv(projectId);
+			onClose();
+		} catch (err) {
+			setError(err instanceof Error ? err.message : String(err));
+		} finally {
+			setWriting(false);
+		}
+	};
+
+	const changed = preview?.changed ?? [];
+
+	return (
+		<Dialog open onOpenChange={onClose}>
+			<DialogContent className="sm:max-w-2xl">
+				<DialogHeader>
+					<DialogTitle className="flex items-center gap-2">
+						<FileCog className="h-5 w-5" />
+						Sync .env
+					</DialogTitle>
+					<DialogDescription>
+						Only the keys set in the project's <code>env</code> in config.yaml are changed. Comments, order and
+						the other keys stay as they are.
+					</DialogDescription>
+				</DialogHeader>
+
+				{error && <div className="text-sm text-red-400">{error}</div>}
+
+				{preview && (
+					<div className="space-y-2">
+						<div className="text-xs text-muted-foreground font-mono break-all">
+							{preview.path}
+							{!preview.exists && " (new file)"}
+						</div>
+						{changed.length === 0 ? (
+							<div className="rounded-lg border border-border bg-muted/50 p-3 text-sm">
+								.env is already up to date.
+							</div>
+						) : (
+							<ScrollArea className="h-80 rounded-lg border border-border bg-zinc-950">
+								<pre className="p-3 text-xs font-mono">
+									{preview.diff.split("\n").map((line, i) => (
+										<div key={i} className={diffLineClass(line)}>
+											{line || " "}
+										</div>
+									))}
+								</pre>
+							</ScrollArea>
+						)}
+					</div>
+				)}
+
+				<DialogFooter className="gap-2 sm:gap-0">
+					<Button variant="outline" onClick={onClose} disabled={writing}>
+						Cancel
+					</Button>
+					<Button onClick={handleApply} disabled={writing || changed.length === 0}>
+						{writing ? "Writing..." : `Write ${changed.length} ${changed.length === 1 ? "key" : "keys"}`}
+					</Button>
+				</DialogFooter>
+			</DialogContent>
+		</Dialog>
+	);
+}
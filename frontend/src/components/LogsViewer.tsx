@@ -1,10 +1,12 @@
-import { useEffect, useRef, useState } from "react";
+import { useEffect, useMemo, useRef, useState } from "react";
 import { Badge } from "@/components/ui/badge";
 import { Dialog, DialogContent, DialogHeader, DialogTitle } from "@/components/ui/dialog";
 import { ScrollArea } from "@/components/ui/scroll-area";
 import { cn } from "@/lib/utils";
+import { EventsOff, EventsOn } from "../../wailsjs/runtime/runtime";
 import { api } from "../services/wails";
-import type { LogEntry } from "../types/project";
+import type { LogBatch, LogEntry, LogRun } from "../types/project";
+import { RequestsPanel } from "./RequestsPanel";
 
 interface LogsViewerProps {
 	projectId: string;
@@ -12,32 +14,157 @@ interface LogsViewerProps {
 	onClose: () => void;
 }
 
+const PAGE_SIZE = 500;
+const MAX_LOGS = 5000;
+const LEVEL_OPTIONS = ["all", "error", "warn", "info", "debug"];
+const STREAM_OPTIONS = ["all", "stdout", "stderr"];
+const TABS = [
+	{ id: "logs", label: "Logs" },
+	{ id: "requests", label: "Requests" },
+] as const;
+
+// Termos "chave=valor" filtram pelos campos de logs JSON; o resto busca no texto.
+function matchesQuery(log: LogEntry, query: string): boolean {
+	const terms = query.trim().toLowerCase().split(/\s+/).filter(Boolean);
+	return terms.every((term) => {
+		const eq = term.indexOf("=");
+		if (eq > 0) {
+			const key = term.slice(0, eq);
+			const value = term.slice(eq + 1);
+			const field = Object.entries(log.fields ?? {}).find(([k]) => k.toLowerCase() === key);
+			return field !== undefined && field[1].toLowerCase().includes(value);
+		}
+		return log.message.toLowerCase().includes(term);
+	});
+}
+
 export function LogsViewer({ projectId, projectName, onClose }: LogsViewerProps) {
 	const [logs, setLogs] = useState<LogEntry[]>([]);
+	const [hasOlder, setHasOlder] = useState(false);
+	const [runs, setRuns] = useState<LogRun[]>([]);
+	const [runId, setRunId] = useState("");
 	const [autoScroll, setAutoScroll] = useState(true);
+	const [level, setLevel] = useState("all");
+	const [stream, setStream] = useState("all");
+	const [query, setQuery] = useState("");
+	const [tab, setTab] = useState<"logs" | "requests">("logs");
 	const logsEndRef = useRef<HTMLDivElement>(null);
 
+	const visibleLogs = useMemo(
+		() =>
+			logs.filter(
+				(log) =>
+					(level === "all" || log.level === level) &&
+					(stream === "all" || log.stream === stream) &&
+					matchesQuery(log, query),
+			),
+		[logs, level, stream, query],
+	);
+
+	const addFieldFilter = (key: string, value: string) => {
+		const term = `${key}=${value}`;
+		setQuery((current) => (current.includes(term) ? current : `${current} ${term}`.trim()));
+	};
+
+	const loadRuns = async () => {
+		try {
+			setRuns(await api.getProjectRuns(projectId));
+		} catch (err) {
+			console.error("Error loading runs:", err);
+		}
+	};
+
+	// Carrega a página mais recente e depois recebe as linhas novas pelo evento
+	// "logs:<id>", a partir do último ID visto; "Carregar anteriores" segue o
+	// cursor para trás.
 	useEffect(() => {
-		const loadLogs = async () => {
+		let cancelled = false;
+		let lastId = 0;
+		const eventName = `logs:${projectId}`;
+
+		const append = (entries: LogEntry[]) => {
+			const fresh = entries.filter((log) => log.id > lastId);
+			if (fresh.length === 0) return;
+			lastId = fresh[fresh.length - 1].id;
+			const matching = runId ? fresh.filter((log) => log.run_id === runId) : fresh;
+			setLogs((current) => [...current, ...matching]);
+		};
+
+		const onBatch = (batch: LogBatch) => {
+			if (cancelled) return;
+			if (batch.skipped) {
+				// O backend pulou linhas para alcançar o fim; elas continuam em
+				// "Carregar anteriores".
+				lastId = 0;
+				setLogs([]);
+				setHasOlder(true);
+			}
+			append(batch.entries);
+		};
+
+		const subscribe = async () => {
 			try {
-				const data = await api.getProjectLogs(projectId, 500);
-				setLogs(data);
+				const page = await api.queryProjectLogs(projectId, { run_id: runId, limit: PAGE_SIZE });
+				if (cancelled) return;
+				setLogs([]);
+				setHasOlder(Boolean(page.next_cursor));
+				append(page.entries);
+				if (page.entries.length === 0 && runId) {
+					// Execução sem linhas: acompanha a partir do fim do histórico.
+					const latest = await api.queryProjectLogs(projectId, { limit: 1 });
+					lastId = latest.entries[0]?.id ?? 0;
+				}
+				if (cancelled) return;
+				EventsOn(eventName, onBatch);
+				await api.subscribeProjectLogs(projectId, lastId);
 			} catch (err) {
 				console.error("Error loading logs:", err);
 			}
 		};
 
-		loadLogs();
-		const interval = setInterval(loadLogs, 2000);
+		subscribe();
+
+		return () => {
+			cancelled = true;
+			EventsOff(eventName);
+			api.unsubscribeProjectLogs(projectId).catch(() => {});
+		};
+	}, [projectId, runId]);
 
-		return () => clearInterval(interval);
+	useEffect(() => {
+		loadRuns();
 	}, [projectId]);
 
+	// Acompanhando ao vivo, descarta as linhas mais antigas da memória; elas
+	// continuam disponíveis em "Carregar anteriores".
+	useEffect(() => {
+		if (autoScroll && logs.length > MAX_LOGS) {
+			setLogs(logs.slice(logs.length - MAX_LOGS));
+			setHasOlder(true);
+		}
+	}, [logs, autoScroll]);
+
+	const loadOlder = async () => {
+		if (logs.length === 0) return;
+		try {
+			const page = await api.queryProjectLogs(projectId, {
+				run_id: runId,
+				before: logs[0].id,
+				limit: PAGE_SIZE,
+			});
+			setAutoScroll(false);
+			setLogs((current) => [...page.entries, ...current]);
+			setHasOlder(Boolean(page.next_cursor));
+		} catch (err) {
+			console.error("Error loading older logs:", err);
+		}
+	};
+
 	useEffect(() => {
 		if (autoScroll && logs.length > 0) {
 			logsEndRef.current?.scrollIntoView({ behavior: "smooth" });
 		}
-	}, [logs.length, autoScroll]);
+	}, [visibleLogs.length, autoScroll]);
 
 	const getLevelConfig = (level: string) => {
 		const normalizedLevel = level.toLowerCase();
@@ -80,8 +207,68 @@ export function LogsViewer({ projectId, projectName, onClose }: LogsViewerProps)
 			<DialogContent className="max-w-5xl h-[80vh] flex flex-col p-0 bg-zinc-950 border-zinc-800">
 				<DialogHeader className="p-6 pb-4 border-b border-zinc-800">
 					<div className="flex items-center justify-between pr-6">
-						<DialogTitle className="text-xl font-bold text-white">Logs: {projectName}</DialogTitle>
-						<div className="flex items-center gap-3">
+						<div className="flex items-center gap-4">
+							<DialogTitle className="text-xl font-bold text-white">{projectName}</DialogTitle>
+							<div className="flex rounded border border-zinc-700 overflow-hidden">
+								{TABS.map((option) => (
+									<button
+										type="button"
+										key={option.id}
+										onClick={() => setTab(option.id)}
+										className={cn(
+											"px-3 py-1 text-sm",
+											tab === option.id ? "bg-zinc-700 text-white" : "bg-zinc-900 text-gray-400 hover:text-gray-200",
+										)}
+									>
+										{option.label}
+									</button>
+								))}
+							</div>
+						</div>
+						<div className={cn("flex items-center gap-3", tab !== "logs" && "hidden")}>
+							<select
+								value={runId}
+								onFocus={loadRuns}
+								onChange={(e) => setRunId(e.target.value)}
+								className="bg-zinc-800 border border-zinc-700 text-gray-200 text-sm rounded px-2 py-1 max-w-56 focus:outline-none focus:ring-2 focus:ring-blue-500"
+							>
+								<option value="">Todas as execuções</option>
+								{runs.map((run) => (
+									<option key={run.run_id} value={run.run_id}>
+										{new Date(run.started_at).toLocaleString()} ({run.lines} linhas
+										{run.errors > 0 ? `, ${run.errors} erros` : ""})
+									</option>
+								))}
+							</select>
+							<input
+								type="text"
+								value={query}
+								onChange={(e) => setQuery(e.target.value)}
+								placeholder="Buscar (ou chave=valor)"
+								className="bg-zinc-800 border border-zinc-700 text-gray-200 text-sm rounded px-2 py-1 w-56 focus:outline-none focus:ring-2 focus:ring-blue-500"
+							/>
+							<select
+								value={level}
+								onChange={(e) => setLevel(e.target.value)}
+								className="bg-zinc-800 border border-zinc-700 text-gray-200 text-sm rounded px-2 py-1 focus:outline-none focus:ring-2 focus:ring-blue-500"
+							>
+								{LEVEL_OPTIONS.map((opt) => (
+									<option key={opt} value={opt}>
+										{opt === "all" ? "Todos os níveis" : opt}
+									</option>
+								))}
+							</select>
+							<select
+								value={stream}
+								onChange={(e) => setStream(e.target.value)}
+								className="bg-zinc-800 border border-zinc-700 text-gray-200 text-sm rounded px-2 py-1 focus:outline-none focus:ring-2 focus:ring-blue-500"
+							>
+								{STREAM_OPTIONS.map((opt) => (
+									<option key={opt} value={opt}>
+										{opt === "all" ? "stdout + stderr" : opt}
+									</option>
+								))}
+							</select>
 							<label className="flex items-center gap-2 text-sm text-gray-300 cursor-pointer select-none">
 								<input
 									type="checkbox"
@@ -94,12 +281,24 @@ export function LogsViewer({ projectId, projectName, onClose }: LogsViewerProps)
 						</div>
 					</div>
 				</DialogHeader>
-				<ScrollArea className="flex-1 p-6">
+				{tab === "requests" && <RequestsPanel projectId={projectId} />}
+				<ScrollArea className={cn("flex-1 p-6", tab !== "logs" && "hidden")}>
 					<div className="font-mono text-sm space-y-0.5 bg-[#0a1628] rounded-lg p-5 border border-blue-950/50 shadow-inner">
+						{hasOlder && (
+							<button
+								type="button"
+								onClick={loadOlder}
+								className="w-full text-xs text-gray-400 hover:text-gray-200 py-2 mb-2 border border-dashed border-zinc-700 rounded"
+							>
+								Carregar anteriores
+							</button>
+						)}
 						{logs.length === 0 ? (
 							<p className="text-gray-500 text-center py-8">Nenhum log disponível</p>
+						) : visibleLogs.length === 0 ? (
+							<p className="text-gray-500 text-center py-8">Nenhum log corresponde aos filtros</p>
 						) : (
-							logs.map((log) => {
+							visibleLogs.map((log) => {
 								const { color, bg, border } = getLevelConfig(log.level);
 								return (
 									<div
@@ -122,7 +321,28 @@ export function LogsViewer({ projectId, projectName, onClose }: LogsViewerProps)
 										>
 											{log.level}
 										</Badge>
-										<span className="flex-1 text-gray-100 leading-relaxed">{log.message}</span>
+										<div className="flex-1 min-w-0">
+											<span className="text-gray-100 leading-relaxed break-words">
+												{log.message}
+											</span>
+											{log.stream === "stderr" && (
+												<span className="ml-2 text-[10px] uppercase text-red-400/70">stderr</span>
+											)}
+											{log.fields && Object.keys(log.fields).length > 0 && (
+												<div className="flex flex-wrap gap-1 mt-1">
+													{Object.entries(log.fields).map(([key, value]) => (
+														<button
+															type="button"
+															key={key}
+															onClick={() => addFieldFilter(key, value)}
+															className="text-[11px] text-gray-400 bg-zinc-800/80 border border-zinc-700 rounded px-1.5 hover:text-gray-200 hover:border-zinc-500"
+														>
+															{key}={value}
+														</button>
+													))}
+												</div>
+											)}
+										</div>
 									</div>
 								);
 							})
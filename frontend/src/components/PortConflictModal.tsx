@@ -9,7 +9,7 @@ import {
 	DialogHeader,
 	DialogTitle,
 } from "@/components/ui/dialog";
-import type { PortConflict } from "../services/wails";
+import type { PortConflict, PortOwner } from "../services/wails";
 
 interface PortConflictModalProps {
 	conflict: PortConflict;
@@ -17,8 +17,43 @@ interface PortConflictModalProps {
 	onCancel: () => void;
 }
 
+function OwnerDetails({ owner }: { owner: PortOwner }) {
+	return (
+		<div className="space-y-2 rounded-lg border border-border bg-muted/50 p-3">
+			<div className="flex items-center gap-2">
+				<Terminal className="h-4 w-4 text-muted-foreground" />
+				<span className="font-mono font-semibold">{owner.pid > 0 ? `PID ${owner.pid}` : "Unknown process"}</span>
+			</div>
+			{owner.command && <code className="block text-xs font-mono break-all">{owner.command}</code>}
+			{owner.ancestors && owner.ancestors.length > 0 && (
+				<div className="text-xs text-muted-foreground">
+					<div className="mb-1">Started by</div>
+					{owner.ancestors.map((p) => (
+						<div key={p.pid} className="font-mono truncate" title={p.command}>
+							{p.pid} · {p.command}
+						</div>
+					))}
+				</div>
+			)}
+			{owner.descendants && owner.descendants.length > 0 && (
+				<div className="text-xs text-muted-foreground">
+					<div className="mb-1">Child processes</div>
+					{owner.descendants.map((p) => (
+						<div key={p.pid} className="font-mono truncate" title={p.command}>
+							{p.pid} · {p.command}
+						</div>
+					))}
+				</div>
+			)}
+		</div>
+	);
+}
+
 export function PortConflictModal({ conflict, onKill, onCancel }: PortConflictModalProps) {
 	const [killing, setKilling] = useState(false);
+	const owners = conflict.owners?.length
+		? conflict.owners
+		: [{ pid: conflict.pid, ppid: 0, command: conflict.command }];
 
 	const handleKill = async () => {
 		setKilling(true);
@@ -34,15 +69,15 @@ export function PortConflictModal({ conflict, onKill, onCancel }: PortConflictMo
 
 	return (
 		<Dialog open onOpenChange={onCancel}>
-			<DialogContent className="sm:max-w-md">
+			<DialogContent className="sm:max-w-lg">
 				<DialogHeader>
 					<DialogTitle className="flex items-center gap-2">
 						<AlertTriangle className="h-5 w-5 text-amber-500" />
 						Port Already in Use
 					</DialogTitle>
 					<DialogDescription>
-						Another application is already using this port. You can force stop the conflicting
-						process.
+						Another application is already using this port. You can force stop every process
+						listening on it.
 					</DialogDescription>
 				</DialogHeader>
 
@@ -55,20 +90,9 @@ export function PortConflictModal({ conflict, onKill, onCancel }: PortConflictMo
 						</div>
 					</div>
 
-					<div className="flex items-center gap-3 rounded-lg border border-border bg-muted/50 p-3">
-						<Terminal className="h-4 w-4 text-muted-foreground" />
-						<div className="flex-1">
-							<div className="text-xs text-muted-foreground">Process ID</div>
-							<div className="font-mono font-semibold">{conflict.pid}</div>
-						</div>
-					</div>
-
-					{conflict.command && (
-						<div className="rounded-lg border border-border bg-muted/50 p-3">
-							<div className="text-xs text-muted-foreground mb-1">Command</div>
-							<code className="text-xs font-mono break-all">{conflict.command}</code>
-						</div>
-					)}
+					{owners.map((owner) => (
+						<OwnerDetails key={owner.pid} owner={owner} />
+					))}
 				</div>
 
 				<DialogFooter className="gap-2 sm:gap-0">
@@ -76,7 +100,7 @@ export function PortConflictModal({ conflict, onKill, onCancel }: PortConflictMo
 						Cancel
 					</Button>
 					<Button variant="destructive" onClick={handleKill} disabled={killing}>
-						{killing ? "Stopping..." : "Force Stop Process"}
+						{killing ? "Stopping..." : owners.length > 1 ? "Force Stop Processes" : "Force Stop Process"}
 					</Button>
 				</DialogFooter>
 			</DialogContent>
@@ -0,0 +1,80 @@
+import { useEffect, useState } from "react";
+import { formatBytes } from "@/lib/utils";
+import { api } from "../services/wails";
+import type { ProcessMetrics as Metrics } from "../types/project";
+
+interface ProcessMetricsProps {
+	projectId: string;
+}
+
+interface SparklineProps {
+	values: number[];
+	className: string;
+}
+
+function Sparkline({ values, className }: SparklineProps) {
+	if (values.length < 2) {
+		return null;
+	}
+
+	const max = Math.max(...values, 1);
+	const points = values
+		.map((value, i) => `${(i / (values.length - 1)) * 100},${24 - (value / max) * 22}`)
+		.join(" ");
+
+	return (
+		<svg viewBox="0 0 100 24" preserveAspectRatio="none" className="h-6 w-full">
+			<polyline points={points} fill="none" strokeWidth="1.5" vectorEffect="non-scaling-stroke" className={className} />
+		</svg>
+	);
+}
+
+export function ProcessMetrics({ projectId }: ProcessMetricsProps) {
+	const [metrics, setMetrics] = useState<Metrics | null>(null);
+
+	useEffect(() => {
+		const loadMetrics = async () => {
+			try {
+				setMetrics(await api.getProjectMetrics(projectId));
+			} catch (err) {
+				console.error("Error loading metrics:", err);
+			}
+		};
+
+		loadMetrics();
+		const interval = setInterval(loadMetrics, 2000);
+
+		return () => clearInterval(interval);
+	}, [projectId]);
+
+	if (!metrics) {
+		return null;
+	}
+
+	return (
+		<div className="grid grid-cols-2 gap-3 rounded-md border border-zinc-800 bg-zinc-950/50 p-3 text-xs">
+			<div className="min-w-0">
+				<div className="flex justify-between text-gray-400">
+					<span>CPU</span>
+					<span className="text-gray-200">{metrics.cpu_used.toFixed(1)}%</span>
+				</div>
+				<Sparkline values={metrics.history.map((s) => s.cpu_used)} className="stroke-blue-400" />
+			</div>
+			<div className="min-w-0">
+				<div className="flex justify-between text-gray-400">
+					<span>Memory</span>
+					<span className="text-gray-200">{formatBytes(metrics.memory_used)}</span>
+				</div>
+				<Sparkline values={metrics.history.map((s) => s.memory_used)} className="stroke-green-400" />
+			</div>
+			<div className="col-span-2 flex justify-between text-gray-500">
+				<span>
+					{metrics.processes} processes · {metrics.threads} threads
+				</span>
+				{metrics.ports && metrics.ports.length > 0 && (
+					<span>{metrics.ports.map((port) => `:${port}`).join(" ")}</span>
+				)}
+			</div>
+		</div>
+	);
+}
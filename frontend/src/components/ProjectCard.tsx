@@ -1,4 +1,4 @@
-import { AlertCircle, Code, ExternalLink, FileText, FolderOpen, Play, RotateCw, Square, Terminal, Trash2 } from "lucide-react";
+import { AlertCircle, Code, ExternalLink, FileCog, FileText, FolderOpen, History, Play, RotateCw, Square, Terminal, Trash2 } from "lucide-react";
 import { useState } from "react";
 import { Alert, AlertDescription } from "@/components/ui/alert";
 import { Badge } from "@/components/ui/badge";
@@ -7,9 +7,13 @@ import { Card, CardContent, CardFooter, CardHeader } from "@/components/ui/card"
 import { BrowserOpenURL } from "../../wailsjs/runtime/runtime";
 import { api, type PortConflict } from "../services/wails";
 import type { Project } from "../types/project";
+import { ComposeServices } from "./ComposeServices";
 import { DependencyAlert } from "./DependencyAlert";
+import { EnvFileModal } from "./EnvFileModal";
 import { GitControls } from "./GitControls";
 import { PortConflictModal } from "./PortConflictModal";
+import { ProcessMetrics } from "./ProcessMetrics";
+import { RunHistory } from "./RunHistory";
 
 interface ProjectCardProps {
 	project: Project;
@@ -31,6 +35,8 @@ export function ProjectCard({
 	const [loading, setLoading] = useState(false);
 	const [error, setError] = useState<string | null>(null);
 	const [portConflict, setPortConflict] = useState<PortConflict | null>(null);
+	const [showEnvFile, setShowEnvFile] = useState(false);
+	const [showHistory, setShowHistory] = useState(false);
 
 	const handleAction = async (action: () => Promise<void>, actionName: string, openLogsOnError = false) => {
 		try {
@@ -43,11 +49,15 @@ export function ProjectCard({
 			if (errorMsg.startsWith("PORT_IN_USE:")) {
 				const parts = errorMsg.split(":");
 				if (parts.length >= 4) {
+					const port = parseInt(parts[1], 10);
 					setPortConflict({
-						port: parseInt(parts[1], 10),
+						port,
 						pid: parseInt(parts[2], 10),
 						command: parts.slice(3).join(":"),
 					});
+					api.checkPortInUse(port)
+						.then((conflict) => conflict && setPortConflict(conflict))
+						.catch((err) => console.error("Error loading port owners:", err));
 				}
 			} else {
 				setError(errorMsg);
@@ -62,14 +72,15 @@ export function ProjectCard({
 
 	const handleKillProcess = async () => {
 		if (!portConflict) return;
-		await api.killProcessByPID(portConflict.pid);
+		await api.killPortOwners(portConflict.port);
 		setPortConflict(null);
 		await handleAction(onStart, "start", true);
 	};
 
 	const _unsatisfiedDeps = project.dependencies.filter((d) => !d.satisfied);
 	const isRunning = project.status === "running";
-	const isStartable = project.status === "stopped" || project.status === "error";
+	const isActive = isRunning || project.status === "starting";
+	const isStartable = project.status === "stopped" || project.status === "error" || project.status === "crash_loop";
 
 	const getStatusBadge = () => {
 		if (isRunning) {
@@ -81,6 +92,9 @@ export function ProjectCard({
 		if (project.status === "error") {
 			return <Badge className="bg-red-500/20 text-red-400 border-red-500/30">Error</Badge>;
 		}
+		if (project.status === "crash_loop") {
+			return <Badge className="bg-red-500/20 text-red-400 border-red-500/30">Crash loop</Badge>;
+		}
 		return (
 			<Badge variant="secondary" className="text-gray-400 bg-zinc-800/50">
 				Stopped
@@ -138,6 +152,8 @@ export function ProjectCard({
 				</div>
 
 				<GitControls project={project} />
+				{project.type === "docker" && isActive && <ComposeServices projectId={project.id} />}
+				{project.type !== "docker" && isActive && <ProcessMetrics projectId={project.id} />}
 				{_unsatisfiedDeps.length > 0 && <DependencyAlert dependencies={_unsatisfiedDeps} />}
 				{error && (
 					<Alert variant="destructive">
@@ -169,7 +185,7 @@ export function ProjectCard({
 						</Button>
 					)}
 
-					{isRunning && (
+					{isActive && (
 						<>
 							<Button
 								onClick={() => handleAction(onStop, "parar")}
@@ -235,9 +251,29 @@ export function ProjectCard({
 						<Terminal className="h-4 w-4" />
 					</Button>
 
+					<Button
+						onClick={() => setShowEnvFile(true)}
+						size="sm"
+						variant="secondary"
+						className="bg-zinc-800 hover:bg-zinc-700 text-gray-200 border-zinc-700"
+						title="Sincronizar .env"
+					>
+						<FileCog className="h-4 w-4" />
+					</Button>
+
+					<Button
+						onClick={() => setShowHistory(true)}
+						size="sm"
+						variant="secondary"
+						className="bg-zinc-800 hover:bg-zinc-700 text-gray-200 border-zinc-700"
+						title="Histórico de execuções"
+					>
+						<History className="h-4 w-4" />
+					</Button>
+
 					<Button
 						onClick={() => handleAction(onRemove, "remover")}
-						disabled={loading || isRunning}
+						disabled={loading || isActive}
 						size="sm"
 						variant="ghost"
 						className="ml-auto text-red-400 hover:text-red-300 hover:bg-red-500/10"
@@ -255,6 +291,11 @@ export function ProjectCard({
 					onCancel={() => setPortConflict(null)}
 				/>
 			)}
+
+			{showEnvFile && <EnvFileModal projectId={project.id} onClose={() => setShowEnvFile(false)} />}
+			{showHistory && (
+				<RunHistory projectId={project.id} projectName={project.name} onClose={() => setShowHistory(false)} />
+			)}
 		</Card>
 	);
 }
@@ -0,0 +1,238 @@
+import { ChevronDown, ChevronRight, RotateCcw } from "lucide-react";
+import { useEffect, useState } from "react";
+import { Badge } from "@/components/ui/badge";
+import { Button } from "@/components/ui/button";
+import { ScrollArea } from "@/components/ui/scroll-area";
+import { cn } from "@/lib/utils";
+import { EventsOff, EventsOn } from "../../wailsjs/runtime/runtime";
+import { api } from "../services/wails";
+import type { ProxyRequest, ReplayResult } from "../types/project";
+
+interface RequestsPanelProps {
+	projectId: string;
+}
+
+const PAGE_SIZE = 200;
+const STATUS_OPTIONS = ["all", "2xx", "3xx", "4xx", "5xx", "errors"];
+
+function statusClass(status: number) {
+	if (status >= 500) return "bg-red-500/15 text-red-400 border-red-500/30";
+	if (status >= 400) return "bg-amber-500/15 text-amber-400 border-amber-500/30";
+	if (status >= 300) return "bg-blue-500/15 text-blue-400 border-blue-500/30";
+	return "bg-emerald-500/15 text-emerald-400 border-emerald-500/30";
+}
+
+function formatLatency(ms: number) {
+	if (ms < 1) return `${Math.round(ms * 1000)}µs`;
+	if (ms < 1000) return `${ms.toFixed(ms < 10 ? 1 : 0)}ms`;
+	return `${(ms / 1000).toFixed(2)}s`;
+}
+
+function HeaderList({ headers }: { headers?: Record<string, string> }) {
+	const entries = Object.entries(headers ?? {}).sort(([a], [b]) => a.localeCompare(b));
+	if (entries.length === 0) {
+		return <p className="text-xs text-muted-foreground">Nenhum cabeçalho capturado</p>;
+	}
+	return (
+		<dl className="grid grid-cols-[max-content_1fr] gap-x-3 gap-y-0.5 text-xs font-mono">
+			{entries.map(([name, value]) => (
+				<div key={name} className="contents">
+					<dt className="text-gray-400">{name}</dt>
+					<dd className="text-gray-200 break-all">{value}</dd>
+				</div>
+			))}
+		</dl>
+	);
+}
+
+// Requisições atendidas pelo proxy para o projeto, atualizadas pelo evento
+// "requests:<id>". O replay reenvia a requisição pelo proxy e mostra a resposta.
+export function RequestsPanel({ projectId }: RequestsPanelProps) {
+	const [requests, setRequests] = useState<ProxyRequest[]>([]);
+	const [hasOlder, setHasOlder] = useState(false);
+	const [status, setStatus] = useState("all");
+	const [search, setSearch] = useState("");
+	const [error, setError] = useState<string | null>(null);
+	const [expanded, setExpanded] = useState<number | null>(null);
+	const [replaying, setReplaying] = useState<number | null>(null);
+	const [replays, setReplays] = useState<Record<number, ReplayResult | string>>({});
+
+	useEffect(() => {
+		let cancelled = false;
+		const eventName = `requests:${projectId}`;
+
+		const load = async () => {
+			try {
+				const page = await api.getProjectRequests(projectId, { status, search, limit: PAGE_SIZE });
+				if (cancelled) return;
+				setRequests(page);
+				setHasOlder(page.length === PAGE_SIZE);
+				setError(null);
+			} catch (err) {
+				if (!cancelled) setError(err instanceof Error ? err.message : String(err));
+			}
+		};
+
+		load();
+		EventsOn(eventName, load);
+		return () => {
+			cancelled = true;
+			EventsOff(eventName);
+		};
+	}, [projectId, status, search]);
+
+	const loadOlder = async () => {
+		if (requests.length === 0) return;
+		try {
+			const page = await api.getProjectRequests(projectId, {
+				status,
+				search,
+				before: requests[requests.length - 1].id,
+				limit: PAGE_SIZE,
+			});
+			setRequests((current) => [...current, ...page]);
+			setHasOlder(page.length === PAGE_SIZE);
+		} catch (err) {
+			setError(err instanceof Error ? err.message : String(err));
+		}
+	};
+
+	const replay = async (request: ProxyRequest) => {
+		setReplaying(request.id);
+		try {
+			const result = await api.replayRequest(projectId, request.id);
+			setReplays((current) => ({ ...current, [request.id]: result }));
+		} catch (err) {
+			const message = err instanceof Error ? err.message : String(err);
+			setReplays((current) => ({ ...current, [request.id]: message }));
+		} finally {
+			setReplaying(null);
+		}
+	};
+
+	return (
+		<div className="flex-1 flex flex-col min-h-0">
+			<div className="flex items-center gap-3 px-6 py-3 border-b border-zinc-800">
+				<input
+					type="text"
+					value={search}
+					onChange={(e) => setSearch(e.target.value)}
+					placeholder="Filtrar pelo caminho"
+					className="bg-zinc-800 border border-zinc-700 text-gray-200 text-sm rounded px-2 py-1 w-64 focus:outline-none focus:ring-2 focus:ring-blue-500"
+				/>
+				<select
+					value={status}
+					onChange={(e) => setStatus(e.target.value)}
+					className="bg-zinc-800 border border-zinc-700 text-gray-200 text-sm rounded px-2 py-1 focus:outline-none focus:ring-2 focus:ring-blue-500"
+				>
+					{STATUS_OPTIONS.map((opt) => (
+						<option key={opt} value={opt}>
+							{opt === "all" ? "Todos os status" : opt === "errors" ? "Erros (4xx e 5xx)" : opt}
+						</option>
+					))}
+				</select>
+				{error && <span className="text-sm text-red-400 truncate">{error}</span>}
+			</div>
+
+			<ScrollArea className="flex-1 p-6">
+				{requests.length === 0 ? (
+					<p className="text-gray-500 text-center py-8">Nenhuma requisição registrada</p>
+				) : (
+					<div className="space-y-1">
+						{requests.map((request) => {
+							const open = expanded === request.id;
+							const result = replays[request.id];
+							return (
+								<div key={request.id} className="rounded border border-zinc-800 bg-zinc-900/60">
+									<button
+										type="button"
+										onClick={() => setExpanded(open ? null : request.id)}
+										className="w-full flex items-center gap-3 px-3 py-1.5 text-left text-sm hover:bg-zinc-900"
+									>
+										{open ? (
+											<ChevronDown className="h-4 w-4 shrink-0 text-muted-foreground" />
+										) : (
+											<ChevronRight className="h-4 w-4 shrink-0 text-muted-foreground" />
+										)}
+										<span className="text-gray-500 shrink-0 text-xs">
+											{new Date(request.created_at).toLocaleTimeString("en-US", { hour12: false })}
+										</span>
+										<span className="font-mono text-xs font-bold text-gray-300 shrink-0 w-14">
+											{request.method}
+										</span>
+										<span className="font-mono text-xs text-gray-100 truncate flex-1">
+											<span className="text-gray-500">{request.host}</span>
+											{request.path}
+										</span>
+										<Badge variant="outline" className={cn("text-[11px] shrink-0", statusClass(request.status))}>
+											{request.status}
+										</Badge>
+										<span className="text-xs text-muted-foreground shrink-0 w-16 text-right">
+											{formatLatency(request.duration_ms)}
+										</span>
+									</button>
+
+									{open && (
+										<div className="px-3 pb-3 pt-1 space-y-3 border-t border-zinc-800">
+											<div className="flex items-center gap-4 text-xs text-muted-foreground flex-wrap">
+												<span>{request.scheme.toUpperCase()}</span>
+												<span>upstream: {request.upstream || "-"}</span>
+												<span>route: {request.route}</span>
+												{request.response_size ? <span>{request.response_size} bytes</span> : null}
+												<Button
+													size="sm"
+													variant="outline"
+													className="ml-auto h-7"
+													disabled={replaying === request.id}
+													onClick={() => replay(request)}
+													title="Reenviar a requisição"
+												>
+													<RotateCcw className="h-3.5 w-3.5 mr-1" />
+													{replaying === request.id ? "Reenviando..." : "Replay"}
+												</Button>
+											</div>
+											<HeaderList headers={request.headers} />
+
+											{typeof result === "string" && <p className="text-xs text-red-400">{result}</p>}
+											{result && typeof result !== "string" && (
+												<div className="space-y-2 rounded border border-zinc-800 bg-zinc-950 p-2">
+													<div className="flex items-center gap-2 text-xs">
+														<Badge variant="outline" className={cn("text-[11px]", statusClass(result.status))}>
+															{result.status}
+														</Badge>
+														<span className="text-muted-foreground">{formatLatency(result.duration_ms)}</span>
+														{result.body_omitted && (
+															<span className="text-amber-400">
+																A requisição original tinha corpo, que não é capturado e não foi reenviado
+															</span>
+														)}
+													</div>
+													<HeaderList headers={result.headers} />
+													{result.body && (
+														<pre className="text-xs font-mono text-gray-300 whitespace-pre-wrap break-all max-h-64 overflow-auto">
+															{result.body}
+															{result.truncated && "\n…"}
+														</pre>
+													)}
+												</div>
+											)}
+										</div>
+									)}
+								</div>
+							);
+						})}
+						{hasOlder && (
+							<button
+								type="button"
+								onClick={loadOlder}
+								className="w-full text-xs text-gray-400 hover:text-gray-200 py-2 mt-2 border border-dashed border-zinc-700 rounded"
+							>
+								Carregar anteriores
+							</button>
+						)}
+					</div>
+				)}
+			</ScrollArea>
+		</div>
+	);
+}
@@ -0,0 +1,192 @@
+import { ChevronDown, ChevronRight, GitBranch, History } from "lucide-react";
+import { useEffect, useState } from "react";
+import { Badge } from "@/components/ui/badge";
+import { Dialog, DialogContent, DialogDescription, DialogHeader, DialogTitle } from "@/components/ui/dialog";
+import { ScrollArea } from "@/components/ui/scroll-area";
+import { cn } from "@/lib/utils";
+import { api } from "../services/wails";
+import type { Run, RunEvent } from "../types/project";
+
+interface RunHistoryProps {
+	projectId: string;
+	projectName: string;
+	onClose: () => void;
+}
+
+function formatDuration(ms: number) {
+	if (ms < 1000) {
+		return `${ms}ms`;
+	}
+	const seconds = ms / 1000;
+	if (seconds < 60) {
+		return `${seconds.toFixed(seconds < 10 ? 1 : 0)}s`;
+	}
+	const minutes = Math.floor(seconds / 60);
+	if (minutes < 60) {
+		return `${minutes}m ${Math.round(seconds % 60)}s`;
+	}
+	return `${Math.floor(minutes / 60)}h ${minutes % 60}m`;
+}
+
+// Como a execução terminou: sinal, código de saída ou o motivo; enquanto está
+// aberta, o status atual.
+function runResult(run: Run): { label: string; className: string } {
+	if (!run.ended_at) {
+		return run.status === "error"
+			? { label: "error", className: "bg-red-500/15 text-red-400 border-red-500/30" }
+			: { label: run.status, className: "bg-emerald-500/15 text-emerald-400 border-emerald-500/30" };
+	}
+	if (run.end_reason === "start_failed") {
+		return { label: "failed to start", className: "bg-red-500/15 text-red-400 border-red-500/30" };
+	}
+	if (run.end_reason === "interrupted") {
+		return { label: "interrupted", className: "bg-amber-500/15 text-amber-400 border-amber-500/30" };
+	}
+	if (run.end_reason === "stopped") {
+		return {
+			label: run.signal ? `stopped (${run.signal})` : "stopped",
+			className: "bg-muted text-muted-foreground border-border",
+		};
+	}
+	if (run.signal) {
+		return { label: run.signal, className: "bg-red-500/15 text-red-400 border-red-500/30" };
+	}
+	if (run.exit_code !== undefined && run.exit_code !== null) {
+		return run.exit_code === 0
+			? { label: "exit 0", className: "bg-muted text-muted-foreground border-border" }
+			: { label: `exit ${run.exit_code}`, className: "bg-red-500/15 text-red-400 border-red-500/30" };
+	}
+	return { label: run.status, className: "bg-muted text-muted-foreground border-border" };
+}
+
+function eventColor(event: RunEvent) {
+	switch (event.type) {
+		case "ready":
+			return "bg-emerald-400";
+		case "start":
+			return "bg-blue-400";
+		case "restart":
+		case "interrupted":
+			return "bg-amber-400";
+		case "start_failed":
+		case "readiness_failed":
+		case "crash_loop":
+			return "bg-red-400";
+		default:
+			return "bg-zinc-500";
+	}
+}
+
+export function RunHistory({ projectId, projectName, onClose }: RunHistoryProps) {
+	const [runs, setRuns] = useState<Run[] | null>(null);
+	const [error, setError] = useState<string | null>(null);
+	const [expanded, setExpanded] = useState<Record<string, boolean>>({});
+
+	useEffect(() => {
+		api.getProjectHistory(projectId, 100)
+			.then(setRuns)
+			.catch((err) => setError(err instanceof Error ? err.message : String(err)));
+	}, [projectId]);
+
+	const toggle = (id: string) => setExpanded((current) => ({ ...current, [id]: !current[id] }));
+
+	return (
+		<Dialog open onOpenChange={onClose}>
+			<DialogContent className="max-w-3xl h-[80vh] flex flex-col">
+				<DialogHeader>
+					<DialogTitle className="flex items-center gap-2">
+						<History className="h-5 w-5" />
+						History: {projectName}
+					</DialogTitle>
+					<DialogDescription>
+						Every start with the branch and commit it ran, how long it took to become ready, how long it ran and
+						how it ended.
+					</DialogDescription>
+				</DialogHeader>
+
+				{error && <div className="text-sm text-red-400">{error}</div>}
+
+				<ScrollArea className="flex-1 pr-3">
+					{runs && runs.length === 0 && (
+						<p className="text-sm text-muted-foreground text-center py-8">No runs recorded yet</p>
+					)}
+					<ol className="relative border-l border-zinc-800 ml-2 space-y-4">
+						{runs?.map((run) => {
+							const result = runResult(run);
+							const open = expanded[run.id] ?? false;
+							return (
+								<li key={run.id} className="relative pl-4">
+									<span
+										className={cn(
+											"absolute -left-1.5 top-3 h-3 w-3 rounded-full border border-zinc-950",
+											run.ended_at ? "bg-zinc-600" : "bg-emerald-400",
+										)}
+									/>
+									<button
+										type="button"
+										onClick={() => toggle(run.id)}
+										className="w-full text-left rounded-lg border border-zinc-800 bg-zinc-900/60 hover:bg-zinc-900 px-3 py-2"
+									>
+										<div className="flex items-center gap-2 flex-wrap">
+											{open ? (
+												<ChevronDown className="h-4 w-4 text-muted-foreground" />
+											) : (
+												<ChevronRight className="h-4 w-4 text-muted-foreground" />
+											)}
+											<span className="text-sm font-medium text-gray-200">
+												{new Date(run.started_at).toLocaleString()}
+											</span>
+											<Badge variant="outline" className={cn("text-[11px]", result.className)}>
+												{result.label}
+											</Badge>
+											{run.ready_at && (
+												<span className="text-xs text-muted-foreground">
+													ready in {formatDuration(run.ready_ms ?? 0)}
+												</span>
+											)}
+											{run.ended_at && (
+												<span className="text-xs text-muted-foreground">
+													ran {formatDuration(run.duration_ms ?? 0)}
+												</span>
+											)}
+										</div>
+										{(run.git_branch || run.git_commit) && (
+											<div className="flex items-center gap-1.5 mt-1 text-xs text-muted-foreground">
+												<GitBranch className="h-3 w-3" />
+												<span>{run.git_branch || "detached"}</span>
+												{run.git_commit && <span className="font-mono truncate">{run.git_commit}</span>}
+											</div>
+										)}
+										{run.error && <div className="mt-1 text-xs text-red-400 break-words">{run.error}</div>}
+									</button>
+
+									{open && (
+										<div className="mt-2 ml-2 space-y-2">
+											{run.script && (
+												<pre className="text-xs font-mono text-gray-400 bg-zinc-950 border border-zinc-800 rounded p-2 whitespace-pre-wrap break-all">
+													{run.script}
+												</pre>
+											)}
+											<ul className="space-y-1">
+												{run.events.map((event) => (
+													<li key={event.id} className="flex items-start gap-2 text-xs">
+														<span className={cn("mt-1 h-2 w-2 shrink-0 rounded-full", eventColor(event))} />
+														<span className="text-muted-foreground shrink-0">
+															{new Date(event.created_at).toLocaleTimeString("en-US", { hour12: false })}
+														</span>
+														<span className="text-gray-400 shrink-0 w-28">{event.type}</span>
+														<span className="text-gray-200 break-words">{event.message}</span>
+													</li>
+												))}
+											</ul>
+										</div>
+									)}
+								</li>
+							);
+						})}
+					</ol>
+				</ScrollArea>
+			</DialogContent>
+		</Dialog>
+	);
+}
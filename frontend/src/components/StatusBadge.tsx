@@ -36,6 +36,13 @@ export function StatusBadge({ status }: StatusBadgeProps) {
 					label: "Error",
 					pulse: false,
 				};
+			case "crash_loop":
+				return {
+					variant: "destructive" as const,
+					className: "bg-destructive/15 text-destructive border-destructive/30",
+					label: "Crash loop",
+					pulse: false,
+				};
 			default:
 				return {
 					variant: "secondary" as const,
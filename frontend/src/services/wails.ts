@@ -1,10 +1,51 @@
 import * as App from "../../wailsjs/go/app/App";
-import type { AppStatus, LogEntry, Project } from "../types/project";
+import type {
+  AppStatus,
+  LogEntry,
+  LogPage,
+  LogQuery,
+  LogRun,
+  ProcessMetrics,
+  Project,
+  ProjectChange,
+  ProxyRequest,
+  ReplayResult,
+  RequestQuery,
+  Run,
+  ServiceStatus,
+} from "../types/project";
+
+export interface PortProcess {
+  pid: number;
+  ppid: number;
+  command: string;
+}
+
+export interface PortOwner extends PortProcess {
+  ancestors?: PortProcess[];
+  descendants?: PortProcess[];
+}
 
 export interface PortConflict {
   port: number;
   pid: number;
   command: string;
+  owners?: PortOwner[];
+}
+
+export interface EnvFilePreview {
+  path: string;
+  exists: boolean;
+  diff: string;
+  changed: string[];
+}
+
+export interface CertificateAuthority {
+  path: string;
+  subject: string;
+  fingerprint: string;
+  not_after: string;
+  trusted: boolean;
 }
 
 export const api = {
@@ -32,6 +73,50 @@ export const api = {
     return await App.GetProjectLogs(id, tail);
   },
 
+  async getProjectLogsSince(id: string, afterId: number, limit: number = 500): Promise<LogEntry[]> {
+    return await App.GetProjectLogsSince(id, afterId, limit);
+  },
+
+  async queryProjectLogs(id: string, query: LogQuery): Promise<LogPage> {
+    return await App.QueryProjectLogs(id, query);
+  },
+
+  async getProjectRuns(id: string): Promise<LogRun[]> {
+    return await App.GetProjectRuns(id);
+  },
+
+  async getProjectChanges(id: string, limit = 0): Promise<ProjectChange[]> {
+    return await App.GetProjectChanges(id, limit);
+  },
+
+  async getProjectHistory(id: string, limit = 0): Promise<Run[]> {
+    return await App.GetProjectHistory(id, limit);
+  },
+
+  async getProjectRequests(id: string, query: RequestQuery): Promise<ProxyRequest[]> {
+    return await App.GetProjectRequests(id, query);
+  },
+
+  async replayRequest(id: string, requestId: number): Promise<ReplayResult> {
+    return await App.ReplayRequest(id, requestId);
+  },
+
+  async subscribeProjectLogs(id: string, afterId: number): Promise<void> {
+    return await App.SubscribeProjectLogs(id, afterId);
+  },
+
+  async unsubscribeProjectLogs(id: string): Promise<void> {
+    return await App.UnsubscribeProjectLogs(id);
+  },
+
+  async getProjectServices(id: string): Promise<ServiceStatus[]> {
+    return (await App.GetProjectServices(id)) as ServiceStatus[];
+  },
+
+  async getProjectMetrics(id: string): Promise<ProcessMetrics | null> {
+    return (await App.GetProjectMetrics(id)) as ProcessMetrics | null;
+  },
+
   async addLocalProject(path: string): Promise<void> {
     return await App.AddLocalProject(path);
   },
@@ -60,6 +145,18 @@ export const api = {
     return await App.KillProcessByPID(pid);
   },
 
+  async killPortOwners(port: number): Promise<void> {
+    return await App.KillPortOwners(port);
+  },
+
+  async previewProjectEnv(id: string): Promise<EnvFilePreview> {
+    return (await App.PreviewProjectEnv(id)) as EnvFilePreview;
+  },
+
+  async setupProjectEnv(id: string): Promise<void> {
+    return await App.SetupProjectEnv(id);
+  },
+
   async getProjectGitInfo(
     id: string,
   ): Promise<import("../types/project").GitInfo> {
@@ -116,8 +213,16 @@ export const api = {
     return await App.ExecuteGlobalScript(scriptName);
   },
 
-  async restartTraefik(): Promise<void> {
-    return await App.RestartTraefik();
+  async restartProxy(): Promise<void> {
+    return await App.RestartProxy();
+  },
+
+  async getCertificateAuthority(): Promise<CertificateAuthority> {
+    return (await App.GetCertificateAuthority()) as CertificateAuthority;
+  },
+
+  async trustCertificateAuthority(): Promise<CertificateAuthority> {
+    return (await App.TrustCertificateAuthority()) as CertificateAuthority;
   },
 
   async reloadConfig(): Promise<void> {
@@ -2,11 +2,27 @@ import type { domain } from "../../wailsjs/go/models";
 
 export type Project = domain.Project;
 export type LogEntry = domain.LogEntry;
+export type LogPage = domain.LogPage;
+export type LogQuery = domain.LogQuery;
+export type LogRun = domain.LogRun;
 export type Dependency = domain.Dependency;
+export type ProjectChange = domain.ProjectChange;
+export type Run = domain.Run;
+export type RunEvent = domain.Event;
+export type ProxyRequest = domain.ProxyRequest;
+export type RequestQuery = domain.RequestQuery;
+export type ReplayResult = domain.ReplayResult;
+
+// Lote enviado pelo evento "logs:<project_id>" após SubscribeProjectLogs.
+export interface LogBatch {
+	project_id: string;
+	entries: LogEntry[];
+	skipped?: boolean;
+}
 
 export type ProjectType = "docker" | "node" | "python" | "java" | "go" | "ruby";
 
-export type ProjectStatus = "stopped" | "starting" | "running" | "error" | "unknown";
+export type ProjectStatus = "stopped" | "starting" | "running" | "error" | "crash_loop" | "unknown";
 
 export interface GitInfo {
 	is_repository: boolean;
@@ -17,10 +33,39 @@ export interface GitInfo {
 	last_commit?: string;
 }
 
+export interface ServiceStatus {
+	name: string;
+	state: string;
+	status?: string;
+	main: boolean;
+	port?: number;
+	container_id?: string;
+}
+
+export interface MetricsSample {
+	time: string;
+	memory_used: number;
+	cpu_used: number;
+}
+
+export interface ProcessMetrics {
+	memory_used: number;
+	cpu_used: number;
+	threads: number;
+	processes: number;
+	ports: number[] | null;
+	sampled_at: string;
+	history: MetricsSample[];
+}
+
 export interface AppStatus {
 	total_projects: number;
 	running: number;
 	stopped: number;
 	errors: number;
-	traefik_running: boolean;
+	proxy_running: boolean;
+	proxy_provider: "traefik" | "builtin";
+	memory_used: number;
+	cpu_used: number;
+	threads: number;
 }
@@ -0,0 +1,727 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/internal/envvars"
+	"github.com/Maycon-Santos/relief/internal/runner"
+	"github.com/Maycon-Santos/relief/pkg/logger"
+)
+
+const (
+	SocketName = "relief.sock"
+	// TokenName é o arquivo, ao lado do socket, com o token exigido nas
+	// conexões TCP.
+	TokenName = "api-token"
+
+	streamPollInterval = 500 * time.Millisecond
+	streamBatchSize    = 500
+	defaultTail        = 100
+	maxBacklog         = 100000
+)
+
+// Controller é o subconjunto do App exposto pela API de controle.
+type Controller interface {
+	GetProjects() ([]*domain.Project, error)
+	GetProject(id string) (*domain.Project, error)
+	StartProject(id string) error
+	StopProject(id string) error
+	RestartProject(id string) error
+	StopStack(id string) error
+	GetProjectStack(id string) ([]string, error)
+	GetProjectLogs(id string, tail int) ([]domain.LogEntry, error)
+	GetProjectLogsSince(id string, afterID int64, limit int) ([]domain.LogEntry, error)
+	QueryProjectLogs(id string, query domain.LogQuery) (*domain.LogPage, error)
+	GetProjectRuns(id string) ([]domain.LogRun, error)
+	GetProjectServices(id string) ([]runner.ServiceStatus, error)
+	GetProjectMetrics(id string) (*runner.ProcessMetrics, error)
+	GetProjectEnv(id string, includeOS bool) ([]envvars.Variable, error)
+	GetProjectChanges(id string, limit int) ([]domain.ProjectChange, error)
+	GetProjectHistory(id string, limit int) ([]domain.Run, error)
+	GetProjectRequests(id string, query domain.RequestQuery) ([]domain.ProxyRequest, error)
+	ReplayRequest(id string, requestID int64) (*domain.ReplayResult, error)
+	GetStatus() (map[string]interface{}, error)
+	GetManagedServices() []interface{}
+	StartManagedService(name string) error
+	StopManagedService(name string) error
+}
+
+type Server struct {
+	controller Controller
+	logger     *logger.Logger
+	http       *http.Server
+	socketPath string
+	token      string
+	mu         sync.Mutex
+}
+
+// tcpConnKey marca, no contexto da requisição, as conexões que chegaram pelo
+// listener TCP.
+type tcpConnKey struct{}
+
+func NewServer(controller Controller, log *logger.Logger) *Server {
+	s := &Server{
+		controller: controller,
+		logger:     log,
+	}
+	s.http = &http.Server{
+		Handler:           s.localOnly(s.routes()),
+		ReadHeaderTimeout: 5 * time.Second,
+		ConnContext: func(ctx context.Context, conn net.Conn) context.Context {
+			if conn.LocalAddr().Network() == "tcp" {
+				return context.WithValue(ctx, tcpConnKey{}, true)
+			}
+			return ctx
+		},
+	}
+	return s
+}
+
+// ListenUnix escuta no socket informado, removendo um socket antigo deixado
+// por uma execução anterior. O arquivo fica acessível apenas ao usuário.
+func (s *Server) ListenUnix(path string) error {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("outra instância do Relief já está escutando em %s", path)
+	}
+	_ = os.Remove(path)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório do socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir socket da API: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("erro ao ajustar permissões do socket: %w", err)
+	}
+
+	s.mu.Lock()
+	s.socketPath = path
+	s.mu.Unlock()
+
+	s.serve(listener)
+	return nil
+}
+
+// ListenTCP escuta em um endereço TCP de loopback. Endereços que não sejam
+// loopback são recusados. Como a porta é acessível a qualquer usuário da
+// máquina e a páginas abertas no navegador, as requisições TCP precisam do
+// token guardado em tokenPath (criado com permissão 0600 quando não existe)
+// no cabeçalho "Authorization: Bearer <token>".
+func (s *Server) ListenTCP(addr, tokenPath string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("endereço inválido para a API: %w", err)
+	}
+	if !isLoopbackHost(host) {
+		return fmt.Errorf("a API só pode escutar em loopback, recebido: %s", addr)
+	}
+
+	token, err := loadToken(tokenPath)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir porta da API: %w", err)
+	}
+
+	s.mu.Lock()
+	s.token = token
+	s.mu.Unlock()
+
+	s.serve(listener)
+	return nil
+}
+
+// loadToken lê o token da API ou, sem ele, gera um novo. O arquivo fica
+// acessível apenas ao usuário.
+func loadToken(path string) (string, error) {
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			if err := os.Chmod(path, 0600); err != nil {
+				return "", fmt.Errorf("erro ao ajustar permissões do token da API: %w", err)
+			}
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("erro ao ler token da API: %w", err)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("erro ao gerar token da API: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("erro ao criar diretório do token da API: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("erro ao gravar token da API: %w", err)
+	}
+	return token, nil
+}
+
+func (s *Server) serve(listener net.Listener) {
+	s.logger.Info("API de controle escutando", map[string]interface{}{
+		"network": listener.Addr().Network(),
+		"address": listener.Addr().String(),
+	})
+
+	go func() {
+		if err := s.http.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Warn("API de controle encerrada com erro", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := s.http.Shutdown(ctx)
+	if err != nil {
+		err = s.http.Close()
+	}
+
+	s.mu.Lock()
+	if s.socketPath != "" {
+		_ = os.Remove(s.socketPath)
+		s.socketPath = ""
+	}
+	s.mu.Unlock()
+
+	return err
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /v1/status", s.handleStatus)
+	mux.HandleFunc("GET /v1/projects", s.handleListProjects)
+	mux.HandleFunc("GET /v1/projects/{id}", s.handleGetProject)
+	mux.HandleFunc("POST /v1/projects/{id}/start", s.handleProjectAction)
+	mux.HandleFunc("POST /v1/projects/{id}/stop", s.handleProjectAction)
+	mux.HandleFunc("POST /v1/projects/{id}/restart", s.handleProjectAction)
+	mux.HandleFunc("GET /v1/projects/{id}/logs", s.handleLogs)
+	mux.HandleFunc("GET /v1/projects/{id}/logs/stream", s.handleLogStream)
+	mux.HandleFunc("GET /v1/projects/{id}/logs/history", s.handleLogHistory)
+	mux.HandleFunc("GET /v1/projects/{id}/runs", s.handleProjectRuns)
+	mux.HandleFunc("GET /v1/projects/{id}/services", s.handleProjectServices)
+	mux.HandleFunc("GET /v1/projects/{id}/metrics", s.handleProjectMetrics)
+	mux.HandleFunc("GET /v1/projects/{id}/env", s.handleProjectEnv)
+	mux.HandleFunc("GET /v1/projects/{id}/changes", s.handleProjectChanges)
+	mux.HandleFunc("GET /v1/projects/{id}/history", s.handleProjectHistory)
+	mux.HandleFunc("GET /v1/projects/{id}/stack", s.handleProjectStack)
+	mux.HandleFunc("GET /v1/projects/{id}/requests", s.handleProjectRequests)
+	mux.HandleFunc("POST /v1/projects/{id}/requests/{request}/replay", s.handleReplayRequest)
+	mux.HandleFunc("GET /v1/services", s.handleListServices)
+	mux.HandleFunc("POST /v1/services/{name}/start", s.handleServiceAction)
+	mux.HandleFunc("POST /v1/services/{name}/stop", s.handleServiceAction)
+
+	return mux
+}
+
+// localOnly recusa conexões que não venham do socket unix ou de loopback, e
+// requisições cujo Host não seja local (proteção contra DNS rebinding). Pelo
+// TCP, recusa também requisições com Origin, que vêm de páginas no
+// navegador, e as que não trazem o token da API.
+func (s *Server) localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLocalRequest(r) {
+			s.logger.Warn("Requisição não local recusada pela API", map[string]interface{}{
+				"remote": r.RemoteAddr,
+				"host":   r.Host,
+			})
+			writeError(w, http.StatusForbidden, fmt.Errorf("apenas conexões locais são aceitas"))
+			return
+		}
+
+		if tcp, _ := r.Context().Value(tcpConnKey{}).(bool); tcp {
+			if r.Header.Get("Origin") != "" {
+				s.logger.Warn("Requisição de navegador recusada pela API", map[string]interface{}{
+					"origin": r.Header.Get("Origin"),
+				})
+				writeError(w, http.StatusForbidden, fmt.Errorf("requisições de navegador não são aceitas"))
+				return
+			}
+			if !s.validToken(r) {
+				writeError(w, http.StatusUnauthorized, fmt.Errorf("token da API ausente ou inválido"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) validToken(r *http.Request) bool {
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+func isLocalRequest(r *http.Request) bool {
+	// Conexões pelo socket unix chegam sem endereço remoto.
+	if r.RemoteAddr == "" || r.RemoteAddr == "@" {
+		return true
+	}
+
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil || !isLoopbackHost(remote) {
+		return false
+	}
+
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return isLoopbackHost(strings.Trim(host, "[]"))
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Server) findProject(idOrName string) (*domain.Project, error) {
+	if project, err := s.controller.GetProject(idOrName); err == nil && project != nil {
+		return project, nil
+	}
+
+	projects, err := s.controller.GetProjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		if p.Name == idOrName {
+			return p, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+var errNotFound = errors.New("projeto não encontrado")
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.controller.GetStatus()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := s.controller.GetProjects()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, projects)
+}
+
+func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Server) handleProjectAction(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	action := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	switch action {
+	case "start":
+		err = s.controller.StartProject(project.ID)
+	case "stop":
+		if r.URL.Query().Get("stack") == "true" {
+			err = s.controller.StopStack(project.ID)
+		} else {
+			err = s.controller.StopProject(project.ID)
+		}
+	case "restart":
+		err = s.controller.RestartProject(project.ID)
+	}
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.HasPrefix(err.Error(), "PORT_IN_USE:") {
+			status = http.StatusConflict
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	updated, err := s.controller.GetProject(project.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	// Com after, devolve as linhas seguintes a esse ID, como o stream, para
+	// quem consulta em intervalos.
+	var logs []domain.LogEntry
+	if after := r.URL.Query().Get("after"); after != "" {
+		afterID, _ := strconv.ParseInt(after, 10, 64)
+		logs, err = s.controller.GetProjectLogsSince(project.ID, afterID, queryInt(r, "limit", streamBatchSize))
+	} else {
+		logs, err = s.controller.GetProjectLogs(project.ID, queryInt(r, "tail", defaultTail))
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, logs)
+}
+
+// handleLogHistory pagina o histórico persistido: before (cursor), limit,
+// run, level e q (busca textual).
+func (s *Server) handleLogHistory(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	q := r.URL.Query()
+	before, _ := strconv.ParseInt(q.Get("before"), 10, 64)
+	page, err := s.controller.QueryProjectLogs(project.ID, domain.LogQuery{
+		RunID:  q.Get("run"),
+		Search: q.Get("q"),
+		Level:  q.Get("level"),
+		Before: before,
+		Limit:  queryInt(r, "limit", defaultTail),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) handleProjectRuns(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	runs, err := s.controller.GetProjectRuns(project.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+// handleLogStream envia os logs como server-sent events. O cliente pode
+// retomar de onde parou com o cabeçalho Last-Event-ID ou o parâmetro after;
+// sem eles, o stream começa pelas últimas `tail` linhas.
+func (s *Server) handleLogStream(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming não suportado"))
+		return
+	}
+
+	lastID := int64(-1)
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		lastID, _ = strconv.ParseInt(v, 10, 64)
+	} else if v := r.URL.Query().Get("after"); v != "" {
+		lastID, _ = strconv.ParseInt(v, 10, 64)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if lastID < 0 {
+		lastID = 0
+		if backlog, err := s.controller.GetProjectLogsSince(project.ID, 0, maxBacklog); err == nil {
+			tail := queryInt(r, "tail", defaultTail)
+			if len(backlog) > 0 {
+				lastID = backlog[len(backlog)-1].ID
+			}
+			if len(backlog) > tail {
+				backlog = backlog[len(backlog)-tail:]
+			}
+			for _, entry := range backlog {
+				writeEvent(w, entry)
+			}
+		}
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+
+	for {
+		logs, err := s.controller.GetProjectLogsSince(project.ID, lastID, streamBatchSize)
+		if err != nil {
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", strings.ReplaceAll(err.Error(), "\n", " "))
+			flusher.Flush()
+			return
+		}
+		for _, entry := range logs {
+			writeEvent(w, entry)
+			lastID = entry.ID
+		}
+		if len(logs) > 0 {
+			flusher.Flush()
+		}
+		if len(logs) == streamBatchSize {
+			continue
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, entry domain.LogEntry) {
+	data, _ := json.Marshal(entry)
+	fmt.Fprintf(w, "id: %d\nevent: log\ndata: %s\n\n", entry.ID, data)
+}
+
+func (s *Server) handleProjectServices(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	services, err := s.controller.GetProjectServices(project.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, services)
+}
+
+func (s *Server) handleProjectMetrics(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	metrics, err := s.controller.GetProjectMetrics(project.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if metrics == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("projeto '%s' sem métricas (não está rodando nesta instância)", project.Name))
+		return
+	}
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+func (s *Server) handleProjectEnv(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	vars, err := s.controller.GetProjectEnv(project.ID, r.URL.Query().Get("all") == "true")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, vars)
+}
+
+func (s *Server) handleProjectChanges(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	changes, err := s.controller.GetProjectChanges(project.ID, queryInt(r, "limit", 0))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, changes)
+}
+
+func (s *Server) handleProjectHistory(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	runs, err := s.controller.GetProjectHistory(project.ID, queryInt(r, "limit", 0))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+// handleProjectRequests lista as requisições atendidas pelo proxy: status
+// (código, 2xx..5xx ou errors), q (trecho do caminho), before (cursor) e limit.
+func (s *Server) handleProjectRequests(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	q := r.URL.Query()
+	before, _ := strconv.ParseInt(q.Get("before"), 10, 64)
+	requests, err := s.controller.GetProjectRequests(project.ID, domain.RequestQuery{
+		Status: q.Get("status"),
+		Search: q.Get("q"),
+		Before: before,
+		Limit:  queryInt(r, "limit", defaultTail),
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, requests)
+}
+
+func (s *Server) handleReplayRequest(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	requestID, err := strconv.ParseInt(r.PathValue("request"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("ID de requisição inválido: %s", r.PathValue("request")))
+		return
+	}
+	result, err := s.controller.ReplayRequest(project.ID, requestID)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleProjectStack(w http.ResponseWriter, r *http.Request) {
+	project, err := s.findProject(r.PathValue("id"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	stack, err := s.controller.GetProjectStack(project.ID)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stack)
+}
+
+func (s *Server) handleListServices(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.controller.GetManagedServices())
+}
+
+func (s *Server) handleServiceAction(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	var err error
+	if strings.HasSuffix(r.URL.Path, "/start") {
+		err = s.controller.StartManagedService(name)
+	} else {
+		err = s.controller.StopManagedService(name)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"name": name, "ok": true})
+}
+
+func queryInt(r *http.Request, key string, def int) int {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeProjectError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
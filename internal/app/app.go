@@ -10,13 +10,16 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Maycon-Santos/relief/internal/api"
 	"github.com/Maycon-Santos/relief/internal/config"
 	"github.com/Maycon-Santos/relief/internal/dependency"
 	"github.com/Maycon-Santos/relief/internal/domain"
 	"github.com/Maycon-Santos/relief/internal/git"
 	"github.com/Maycon-Santos/relief/internal/proxy"
 	"github.com/Maycon-Santos/relief/internal/runner"
+	"github.com/Maycon-Santos/relief/internal/secrets"
 	"github.com/Maycon-Santos/relief/internal/storage"
+	"github.com/Maycon-Santos/relief/pkg/fileutil"
 	"github.com/Maycon-Santos/relief/pkg/logger"
 	"github.com/Maycon-Santos/relief/pkg/pathutil"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -31,38 +34,115 @@ type App struct {
 	db             *storage.DB
 	projectRepo    *storage.ProjectRepository
 	logRepo        *storage.LogRepository
+	portRepo       *storage.PortRepository
+	runRepo        *storage.RunRepository
+	requestRepo    *storage.RequestRepository
+	requestQueue   chan domain.ProxyRequest
+	logQueue       chan *domain.LogEntry
+	logsStopped    chan struct{}
+	stopLogs       func()
+	stopRequests   func()
 	runnerFactory  *runner.Factory
 	runners        map[string]runner.ProjectRunner
+	runnersMu      sync.RWMutex
+	readiness      map[string]context.CancelFunc
+	restarts       map[string]*restartState
+	stopping       map[string]bool
+	runIDs         map[string]string
+	logSubs        map[string]*logSubscription
+	logSubsMu      sync.Mutex
 	dependencyMgr  *dependency.Manager
 	enhancedDepMgr *dependency.EnhancedManager
 	gitManager     *git.Manager
-	traefikMgr     *proxy.TraefikManager
+	proxyMgr       proxy.Provider
+	statusPages    *proxy.StatusPages
+	certMgr        *proxy.CertManager
 	hostsMgr       *proxy.HostsManager
 	gitHeadCache   map[string]string
 	gitHeadMu      sync.RWMutex
 	cancelWatcher  context.CancelFunc
+	stopRetention  context.CancelFunc
+	headless       bool
+	instance       instanceLock
+	apiServer      *api.Server
+	secretReg      *secrets.Registry
+	secretMask     *secrets.Masker
 }
 
 func NewApp() *App {
 	return &App{
 		runners:      make(map[string]runner.ProjectRunner),
+		readiness:    make(map[string]context.CancelFunc),
+		restarts:     make(map[string]*restartState),
+		stopping:     make(map[string]bool),
+		runIDs:       make(map[string]string),
+		logSubs:      make(map[string]*logSubscription),
 		gitHeadCache: make(map[string]string),
+		secretReg:    secrets.NewRegistry(),
+		secretMask:   secrets.NewMasker(),
+	}
+}
+
+// Options controla quais partes do núcleo são ativadas na inicialização. A
+// janela Wails usa DefaultOptions; a CLI liga apenas o que cada comando precisa.
+// InstanceLock toma o lock de ~/.relief/relief.lock; se outra instância viva o
+// tem, a limpeza de órfãos não roda. A CLI toma o lock por conta própria.
+type Options struct {
+	Headless       bool
+	Logger         *logger.Logger
+	InstanceLock   bool
+	StartProxy     bool
+	CleanupOrphans bool
+	SyncConfig     bool
+	WatchGit       bool
+	ServeAPI       bool
+	LogRetention   bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		InstanceLock:   true,
+		StartProxy:     true,
+		CleanupOrphans: true,
+		SyncConfig:     true,
+		WatchGit:       true,
+		ServeAPI:       true,
+		LogRetention:   true,
 	}
 }
 
 func (a *App) Startup(ctx context.Context) {
+	if err := a.Init(ctx, DefaultOptions()); err != nil {
+		a.logger.Fatal("Failed to initialize application", err, nil)
+	}
+}
+
+func (a *App) Init(ctx context.Context, opts Options) error {
 	a.ctx = ctx
+	a.headless = opts.Headless
 
-	a.logger = logger.Default()
+	a.logger = opts.Logger
+	if a.logger == nil {
+		a.logger = logger.Default()
+	}
 	a.logger.Info("Initializing Relief Orchestrator", nil)
 
 	db, err := storage.NewDB(a.logger)
 	if err != nil {
-		a.logger.Fatal("Failed to initialize database", err, nil)
+		return fmt.Errorf("erro ao inicializar banco de dados: %w", err)
 	}
 	a.db = db
 	a.projectRepo = storage.NewProjectRepository(db)
 	a.logRepo = storage.NewLogRepository(db)
+	a.portRepo = storage.NewPortRepository(db)
+	a.runRepo = storage.NewRunRepository(db)
+	a.requestRepo = storage.NewRequestRepository(db)
+	a.startLogWriter()
+
+	ownsProcesses := true
+	if opts.InstanceLock {
+		ownsProcesses = a.acquireInstanceLock()
+	}
 
 	a.configLoader = config.NewLoader()
 
@@ -78,6 +158,10 @@ func (a *App) Startup(ctx context.Context) {
 				HTTPPort:  80,
 				HTTPSPort: 443,
 			},
+			Ports: config.PortsConfig{
+				RangeStart: config.DefaultPortRangeStart,
+				RangeEnd:   config.DefaultPortRangeEnd,
+			},
 		}
 	}
 
@@ -98,33 +182,69 @@ func (a *App) Startup(ctx context.Context) {
 
 	a.enhancedDepMgr = dependency.NewEnhancedManager(a.logger, cfg)
 
-	traefikMgr, err := proxy.NewTraefikManager(
+	if !cfg.Proxy.DisableHTTPS {
+		certMgr, err := proxy.NewCertManager(a.logger)
+		if err != nil {
+			a.logger.Warn("Erro ao inicializar certificados, HTTPS desabilitado", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		a.certMgr = certMgr
+	}
+
+	proxyMgr, err := proxy.NewProvider(
+		a.config.Proxy.Provider,
 		a.config.Proxy.HTTPPort,
 		a.config.Proxy.HTTPSPort,
+		a.certMgr,
 		a.logger,
 	)
 	if err != nil {
-		a.logger.Warn("Erro ao inicializar Traefik manager", map[string]interface{}{
+		a.logger.Warn("Erro ao inicializar o proxy", map[string]interface{}{
 			"error": err.Error(),
 		})
-	} else {
-		if err := traefikMgr.Start(a.ctx); err != nil {
-			a.logger.Warn("Erro ao iniciar Traefik", map[string]interface{}{
-				"error": err.Error(),
+	} else if opts.StartProxy {
+		a.startStatusPages()
+		a.startRequestRecorder(proxyMgr)
+		if err := proxyMgr.Start(a.ctx); err != nil {
+			a.logger.Warn("Erro ao iniciar o proxy", map[string]interface{}{
+				"provider": proxyMgr.Name(),
+				"error":    err.Error(),
 			})
 		}
 	}
-	a.traefikMgr = traefikMgr
+	a.proxyMgr = proxyMgr
 
 	a.hostsMgr = proxy.NewHostsManager(a.logger)
 
-	a.cleanupOrphanProcesses()
+	if opts.CleanupOrphans && ownsProcesses {
+		a.cleanupOrphanProcesses()
+	}
 
-	a.syncConfigProjects()
+	if opts.SyncConfig {
+		a.syncConfigProjects()
+		a.checkProjectGraph()
+		a.checkPortCollisions()
+	}
 
-	a.startGitHeadWatcher()
+	if a.statusPages != nil {
+		a.publishProjects()
+	}
+
+	if opts.WatchGit {
+		a.startGitHeadWatcher()
+	}
+
+	if opts.ServeAPI && !cfg.API.Disabled {
+		a.startAPIServer()
+	}
+
+	if opts.LogRetention {
+		a.startLogRetention()
+	}
 
 	a.logger.Info("Relief Orchestrator started successfully", nil)
+	return nil
 }
 
 func (a *App) BeforeClose(ctx context.Context) bool {
@@ -138,9 +258,20 @@ func (a *App) Shutdown(ctx context.Context) {
 		a.cancelWatcher()
 	}
 
+	if a.stopRetention != nil {
+		a.stopRetention()
+	}
+
+	if a.apiServer != nil {
+		_ = a.apiServer.Close()
+	}
+
 	projects, _ := a.projectRepo.List()
 	for _, project := range projects {
-		if project.IsRunning() || project.PID > 0 {
+		if _, owned := a.getRunner(project.ID); a.headless && !owned {
+			continue
+		}
+		if project.IsActive() || project.PID > 0 {
 			a.logger.Info("Parando projeto no shutdown", map[string]interface{}{
 				"project": project.Name,
 				"pid":     project.PID,
@@ -149,17 +280,50 @@ func (a *App) Shutdown(ctx context.Context) {
 		}
 	}
 
-	if a.traefikMgr != nil {
-		if err := a.traefikMgr.Stop(); err != nil {
-			a.logger.Warn("Erro ao parar Traefik", map[string]interface{}{
+	if a.proxyMgr != nil {
+		if err := a.proxyMgr.Stop(); err != nil {
+			a.logger.Warn("Erro ao parar o proxy", map[string]interface{}{
 				"error": err.Error(),
 			})
 		}
 	}
 
+	if a.statusPages != nil {
+		_ = a.statusPages.Stop()
+	}
+
+	if a.stopRequests != nil {
+		a.stopRequests()
+	}
+
+	if a.stopLogs != nil {
+		a.stopLogs()
+	}
+
 	if a.db != nil {
 		a.db.Close()
 	}
+
+	a.releaseInstanceLock()
+}
+
+func (a *App) getRunner(id string) (runner.ProjectRunner, bool) {
+	a.runnersMu.RLock()
+	defer a.runnersMu.RUnlock()
+	r, ok := a.runners[id]
+	return r, ok
+}
+
+func (a *App) setRunner(id string, r runner.ProjectRunner) {
+	a.runnersMu.Lock()
+	a.runners[id] = r
+	a.runnersMu.Unlock()
+}
+
+func (a *App) deleteRunner(id string) {
+	a.runnersMu.Lock()
+	delete(a.runners, id)
+	a.runnersMu.Unlock()
 }
 
 func (a *App) GetProjects() ([]*domain.Project, error) {
@@ -167,11 +331,14 @@ func (a *App) GetProjects() ([]*domain.Project, error) {
 	if err != nil {
 		return nil, fmt.Errorf("erro ao listar projetos: %w", err)
 	}
+	for _, project := range projects {
+		a.refreshDefinition(project)
+	}
 	return projects, nil
 }
 
 func (a *App) GetProject(id string) (*domain.Project, error) {
-	project, err := a.projectRepo.GetByID(id)
+	project, err := a.loadProject(id)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar projeto: %w", err)
 	}
@@ -179,10 +346,32 @@ func (a *App) GetProject(id string) (*domain.Project, error) {
 }
 
 func (a *App) StartProject(id string) error {
+	if a.logger == nil || a.projectRepo == nil {
+		return fmt.Errorf("application not fully initialized")
+	}
+
+	a.clearRestart(id)
+
+	if err := a.startUpstreamProjects(id); err != nil {
+		a.logger.Warn("Erro ao iniciar projetos dependentes", map[string]interface{}{
+			"id":    id,
+			"error": err.Error(),
+		})
+		a.appendProjectLog(id, "error", err.Error())
+		return err
+	}
+
+	return a.launchProject(id)
+}
+
+// launchProject inicia um único projeto. Cada chamada abre uma nova execução
+// (run ID) nos logs; o histórico das anteriores é mantido. Um panic vira o
+// erro retornado, para o chamador não tomar o início como bem-sucedido.
+func (a *App) launchProject(id string) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			panicErr := fmt.Errorf("panic: %v", r)
-			a.logger.Error("Panic in StartProject", panicErr, map[string]interface{}{
+			err = fmt.Errorf("panic ao iniciar projeto: %v", r)
+			a.logger.Error("Panic in StartProject", err, map[string]interface{}{
 				"id": id,
 			})
 		}
@@ -194,67 +383,36 @@ func (a *App) StartProject(id string) error {
 
 	a.logger.Info("Iniciando projeto", map[string]interface{}{"id": id})
 
+	runID := a.beginRun(id)
+
 	logStartError := func(err error) error {
-		if a.logRepo != nil {
-			_ = a.logRepo.Create(&domain.LogEntry{
-				ProjectID: id,
-				Level:     "error",
-				Message:   err.Error(),
-				Timestamp: time.Now().Format(time.RFC3339),
-			})
-		}
+		a.appendProjectLog(id, "error", err.Error())
+		a.finishRun(id, runID, domain.StatusError, domain.RunEndStartFailed, err.Error(), nil)
 		return err
 	}
 
-	project, err := a.projectRepo.GetByID(id)
+	project, err := a.loadProject(id)
 	if err != nil {
 		return logStartError(fmt.Errorf("projeto não encontrado: %w", err))
 	}
-
-	if project == nil {
-		return logStartError(fmt.Errorf("project is nil"))
+	a.openRun(project, runID)
+	if !project.IsRunning() {
+		a.parkProject(project)
 	}
 
-	if project.Manifest == nil {
-		manifest, err := domain.ParseManifest(pathutil.FromRelativeHome(project.Path))
-		if err != nil {
-			a.logger.Warn("relief.yaml não encontrado, tentando reidratar da config global", map[string]interface{}{
-				"project": project.Name,
-			})
-			for _, pc := range a.config.Projects {
-				if pc.Name == project.Name {
-					a.updateProjectFromConfig(project, pc)
-					break
-				}
-			}
-			if project.Scripts["dev"] == "" {
-				return logStartError(fmt.Errorf("script 'dev' não encontrado para o projeto '%s' (sem relief.yaml e sem config global)", project.Name))
-			}
-		} else {
-			project.Manifest = manifest
-		}
+	if project.Manifest == nil && project.Scripts["dev"] == "" {
+		return logStartError(fmt.Errorf("script 'dev' não encontrado para o projeto '%s' (sem relief.yaml e sem config global)", project.Name))
 	}
 
 	project.ClearError()
 	_ = a.projectRepo.Update(project)
 
-	if a.logRepo != nil {
-		_ = a.logRepo.DeleteByProjectID(id)
-	}
-
 	if err := a.dependencyMgr.CheckDependencies(a.ctx, project); err != nil {
 		return logStartError(fmt.Errorf("erro ao verificar dependências: %w", err))
 	}
 
 	depLogFn := func(level, message string) {
-		if a.logRepo != nil {
-			_ = a.logRepo.Create(&domain.LogEntry{
-				ProjectID: id,
-				Level:     level,
-				Message:   message,
-				Timestamp: time.Now().Format(time.RFC3339),
-			})
-		}
+		a.appendProjectLog(id, level, message)
 	}
 	if err := a.enhancedDepMgr.StartManagedDependencies(a.ctx, project, depLogFn); err != nil {
 		return logStartError(fmt.Errorf("erro ao iniciar dependências gerenciadas: %w", err))
@@ -269,6 +427,10 @@ func (a *App) StartProject(id string) error {
 		return logStartError(fmt.Errorf("dependências não satisfeitas: %v", unsatisfied))
 	}
 
+	if err := a.assignPorts(project); err != nil {
+		return logStartError(err)
+	}
+
 	if project.Port > 0 {
 		conflict, err := a.CheckPortInUse(project.Port)
 		if err != nil {
@@ -277,42 +439,71 @@ func (a *App) StartProject(id string) error {
 				"error": err.Error(),
 			})
 		} else if conflict != nil {
-
+			a.finishRun(id, runID, domain.StatusError, domain.RunEndStartFailed,
+				fmt.Sprintf("porta %d em uso pelo PID %d (%s)", conflict.Port, conflict.PID, conflict.Command), nil)
 			return fmt.Errorf("PORT_IN_USE:%d:%d:%s", conflict.Port, conflict.PID, conflict.Command)
 		}
 	}
 
+	env, err := a.resolveProjectEnv(project, true)
+	if err != nil {
+		return logStartError(fmt.Errorf("erro ao montar variáveis de ambiente: %w", err))
+	}
+	project.Env = env.Defined()
+	a.secretMask.Set(id, env.Secrets())
+
+	readiness, err := runner.NewReadiness(project)
+	if err != nil {
+		return logStartError(fmt.Errorf("readiness probe inválido: %w", err))
+	}
+
 	projectRunner, err := a.runnerFactory.CreateRunner(project)
 	if err != nil {
 		return logStartError(fmt.Errorf("erro ao criar runner: %w", err))
 	}
 
-	if nativeRunner, ok := projectRunner.(*runner.NativeRunner); ok {
-		if a.logRepo != nil {
-			nativeRunner.SetLogCallback(id, func(level, message string) {
-				_ = a.logRepo.Create(&domain.LogEntry{
-					ProjectID: id,
-					Level:     level,
-					Message:   message,
-					Timestamp: time.Now().Format(time.RFC3339),
-				})
-			})
-		}
+	if cbRunner, ok := projectRunner.(runner.CallbackRunner); ok {
+		cbRunner.SetLogCallback(id, func(entry domain.LogEntry) {
+			if readiness != nil {
+				readiness.ObserveLog(entry.Message)
+			}
+			entry.ProjectID = id
+			entry.RunID = runID
+			a.storeLog(&entry)
+		})
 
-		nativeRunner.SetStatusCallback(id, func(projectID string, status domain.Status, lastError string) {
+		startedAt := time.Now()
+		cbRunner.SetStatusCallback(id, func(projectID string, status domain.Status, lastError string, exit *runner.ExitInfo) {
+			a.cancelReadiness(projectID)
+			reason := domain.RunEndExited
+			if a.isStopping(projectID) {
+				reason = domain.RunEndStopped
+			}
+			a.finishRun(projectID, runID, status, reason, lastError, exit)
 			p, err := a.projectRepo.GetByID(projectID)
 			if err != nil {
 				a.logger.Warn("StatusCallback: projeto não encontrado", map[string]interface{}{"id": projectID})
 				return
 			}
+			if status != domain.StatusRunning {
+				a.parkProject(p)
+			}
+			if a.scheduleRestart(p, status, lastError, startedAt) {
+				a.deleteRunner(projectID)
+				return
+			}
 			if lastError != "" {
 				p.SetError(fmt.Errorf("%s", lastError))
 			} else {
 				p.UpdateStatus(status)
 			}
+			if status != domain.StatusRunning {
+				p.PID = 0
+				p.PGID = 0
+			}
 			_ = a.projectRepo.Update(p)
 
-			delete(a.runners, projectID)
+			a.deleteRunner(projectID)
 		})
 	}
 
@@ -330,25 +521,47 @@ func (a *App) StartProject(id string) error {
 		"project": project.Name,
 	})
 
-	a.runners[project.ID] = projectRunner
+	a.setRunner(project.ID, projectRunner)
 
-	if a.traefikMgr != nil && project.Domain != "" {
-		a.traefikMgr.AddProject(project)
+	if readiness != nil {
+		project.UpdateStatus(domain.StatusStarting)
+		if err := a.projectRepo.Update(project); err != nil {
+			return fmt.Errorf("erro ao atualizar status: %w", err)
+		}
+		a.appendProjectLog(id, "info", fmt.Sprintf("Aguardando readiness probe (%s)", readiness.Kind()))
+		go a.awaitReadiness(project, readiness)
+		return nil
 	}
 
-	if a.hostsMgr != nil && project.Domain != "" {
-		a.hostsMgr.AddEntry(project.Domain)
-	}
+	return a.markProjectReady(project)
+}
+
+// markProjectReady publica as rotas do projeto e o marca como rodando.
+func (a *App) markProjectReady(project *domain.Project) error {
+	a.publishProject(project)
 
 	project.UpdateStatus(domain.StatusRunning)
 	if err := a.projectRepo.Update(project); err != nil {
 		return fmt.Errorf("erro ao atualizar status: %w", err)
 	}
 
+	a.markRunReady(project.ID, a.currentRun(project.ID))
 	return nil
 }
 
 func (a *App) StopProject(id string) error {
+	a.runnersMu.Lock()
+	a.stopping[id] = true
+	a.runnersMu.Unlock()
+	defer func() {
+		a.runnersMu.Lock()
+		delete(a.stopping, id)
+		a.runnersMu.Unlock()
+	}()
+
+	a.clearRestart(id)
+	a.cancelReadiness(id)
+
 	a.logger.Info("Parando projeto", map[string]interface{}{"id": id})
 
 	project, err := a.projectRepo.GetByID(id)
@@ -356,14 +569,29 @@ func (a *App) StopProject(id string) error {
 		return fmt.Errorf("projeto não encontrado: %w", err)
 	}
 
-	projectRunner, exists := a.runners[id]
+	runID := a.openRunID(id)
+	if runID != "" {
+		a.recordEvent(id, runID, domain.EventStop, "Parada solicitada")
+	}
+
+	projectRunner, exists := a.getRunner(id)
 	if exists {
 		if err := projectRunner.Stop(a.ctx, id); err != nil {
 			a.logger.Warn("Erro ao parar via runner", map[string]interface{}{
 				"error": err.Error(),
 			})
 		}
-		delete(a.runners, id)
+		a.deleteRunner(id)
+	} else if project.PGID > 0 {
+		a.logger.Info("Runner não encontrado, encerrando grupo de processos pelo PGID", map[string]interface{}{
+			"pgid": project.PGID,
+		})
+		if _, err := runner.TerminateProcessGroup(project.PGID, project.Manifest.StopGracePeriodDuration()); err != nil {
+			a.logger.Warn("Erro ao encerrar grupo de processos", map[string]interface{}{
+				"pgid":  project.PGID,
+				"error": err.Error(),
+			})
+		}
 	} else if project.PID > 0 {
 		a.logger.Info("Runner não encontrado, matando processo pelo PID", map[string]interface{}{
 			"pid": project.PID,
@@ -376,9 +604,7 @@ func (a *App) StopProject(id string) error {
 		}
 	}
 
-	if a.traefikMgr != nil {
-		a.traefikMgr.RemoveProject(id)
-	}
+	a.unpublishProject(project)
 
 	depsInUse := a.getDepsInUseByOtherProjects(id)
 	if err := a.enhancedDepMgr.StopManagedDependencies(a.ctx, project, depsInUse); err != nil {
@@ -390,13 +616,22 @@ func (a *App) StopProject(id string) error {
 
 	project.UpdateStatus(domain.StatusStopped)
 	project.PID = 0
+	project.PGID = 0
 	if err := a.projectRepo.Update(project); err != nil {
 		return fmt.Errorf("erro ao atualizar status: %w", err)
 	}
 
+	a.finishRun(id, runID, domain.StatusStopped, domain.RunEndStopped, "", nil)
 	return nil
 }
 
+// isStopping indica se StopProject está em andamento para o projeto.
+func (a *App) isStopping(id string) bool {
+	a.runnersMu.RLock()
+	defer a.runnersMu.RUnlock()
+	return a.stopping[id]
+}
+
 func (a *App) RestartProject(id string) error {
 	if err := a.StopProject(id); err != nil {
 		a.logger.Debug("Projeto já estava parado", map[string]interface{}{"id": id})
@@ -404,13 +639,35 @@ func (a *App) RestartProject(id string) error {
 	return a.StartProject(id)
 }
 
-func (a *App) GetProjectLogs(id string, tail int) ([]domain.LogEntry, error) {
-	projectRunner, exists := a.runners[id]
-	if exists {
-		return projectRunner.GetLogs(id, tail)
+func (a *App) GetProjectServices(id string) ([]runner.ServiceStatus, error) {
+	projectRunner, exists := a.getRunner(id)
+	if !exists {
+		return []runner.ServiceStatus{}, nil
 	}
 
-	return a.logRepo.GetByProjectID(id, tail)
+	status, err := projectRunner.Status(id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter status dos serviços: %w", err)
+	}
+	if status.Services == nil {
+		return []runner.ServiceStatus{}, nil
+	}
+	return status.Services, nil
+}
+
+// GetProjectMetrics retorna o consumo da árvore de processos do projeto e o
+// histórico recente, ou nil se o runner ainda não tem amostras.
+func (a *App) GetProjectMetrics(id string) (*runner.ProcessMetrics, error) {
+	projectRunner, exists := a.getRunner(id)
+	if !exists {
+		return nil, nil
+	}
+
+	status, err := projectRunner.Status(id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter métricas do projeto: %w", err)
+	}
+	return status.Metrics, nil
 }
 
 func (a *App) AddLocalProject(path string) error {
@@ -420,12 +677,16 @@ func (a *App) AddLocalProject(path string) error {
 		return fmt.Errorf("no directory selected")
 	}
 
-	manifest, err := domain.ParseManifest(path)
+	manifest, hash, err := readManifest(path)
+	if err == nil && manifest == nil {
+		err = fmt.Errorf("relief.yaml not found")
+	}
 	if err != nil {
 		return fmt.Errorf("failed to read relief.yaml in selected directory: %w", err)
 	}
 
 	project := manifest.ToProject(path)
+	a.applyDefinition(project, nil, manifest, hash)
 
 	existing, _ := a.projectRepo.GetByName(project.Name)
 	if existing != nil {
@@ -456,21 +717,26 @@ func (a *App) AddLocalProject(path string) error {
 		"name": project.Name,
 		"path": path,
 	})
+	a.parkProject(project)
 
 	return nil
 }
 
 func (a *App) RemoveProject(id string) error {
-	if _, exists := a.runners[id]; exists {
+	if _, exists := a.getRunner(id); exists {
 		if err := a.StopProject(id); err != nil {
 			return err
 		}
 	}
 
+	if a.proxyMgr != nil {
+		a.proxyMgr.RemoveProject(id)
+	}
+
 	project, err := a.projectRepo.GetByID(id)
-	if err == nil && project.Domain != "" {
-		if a.hostsMgr != nil {
-			a.hostsMgr.RemoveEntry(project.Domain)
+	if err == nil && a.hostsMgr != nil {
+		for _, host := range hostsEntries(project) {
+			a.hostsMgr.RemoveEntry(host)
 		}
 	}
 
@@ -488,6 +754,7 @@ func (a *App) RefreshConfig() error {
 	}
 
 	a.config = cfg
+	a.checkPortCollisions()
 	return nil
 }
 
@@ -495,48 +762,66 @@ func (a *App) GetStatus() (map[string]interface{}, error) {
 	projects, _ := a.projectRepo.ListLight()
 
 	running := 0
+	starting := 0
 	stopped := 0
 	errors := 0
 
+	var memoryUsed int64
+	var cpuUsed float64
+	threads := 0
+
 	for _, p := range projects {
 		switch p.Status {
 		case domain.StatusRunning:
 			running++
+		case domain.StatusStarting:
+			starting++
 		case domain.StatusStopped:
 			stopped++
 		case domain.StatusError:
 			errors++
 		}
+
+		if metrics, _ := a.GetProjectMetrics(p.ID); metrics != nil {
+			memoryUsed += metrics.MemoryUsed
+			cpuUsed += metrics.CPUUsed
+			threads += metrics.Threads
+		}
 	}
 
 	return map[string]interface{}{
-		"total_projects":  len(projects),
-		"running":         running,
-		"stopped":         stopped,
-		"errors":          errors,
-		"traefik_running": a.traefikMgr != nil && a.traefikMgr.IsRunning(),
+		"total_projects": len(projects),
+		"running":        running,
+		"starting":       starting,
+		"stopped":        stopped,
+		"errors":         errors,
+		"proxy_running":  a.proxyMgr != nil && a.proxyMgr.IsRunning(),
+		"proxy_provider": a.config.Proxy.Provider,
+		"memory_used":    memoryUsed,
+		"cpu_used":       cpuUsed,
+		"threads":        threads,
 	}, nil
 }
 
-func (a *App) RestartTraefik() error {
-	if a.traefikMgr == nil {
-		return fmt.Errorf("traefik não inicializado")
+func (a *App) RestartProxy() error {
+	if a.proxyMgr == nil {
+		return fmt.Errorf("proxy não inicializado")
 	}
-	a.logger.Info("Reiniciando Traefik via UI", nil)
-	if err := a.traefikMgr.Restart(a.ctx); err != nil {
-		return fmt.Errorf("erro ao reiniciar Traefik: %w", err)
+	a.logger.Info("Reiniciando o proxy via UI", map[string]interface{}{
+		"provider": a.proxyMgr.Name(),
+	})
+	if err := a.proxyMgr.Restart(a.ctx); err != nil {
+		return fmt.Errorf("erro ao reiniciar o proxy: %w", err)
 	}
 
-	projects, _ := a.projectRepo.List()
-	for _, p := range projects {
-		if p.IsRunning() && p.Domain != "" {
-			a.traefikMgr.AddProject(p)
-		}
-	}
+	a.publishProjects()
 	return nil
 }
 
 func (a *App) SelectProjectDirectory() (string, error) {
+	if a.headless {
+		return "", fmt.Errorf("seleção de diretório indisponível no modo headless")
+	}
 	path, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
 		Title: "Select Project Directory",
 	})
@@ -558,9 +843,32 @@ func (a *App) cleanupOrphanProcesses() {
 	}
 
 	for _, project := range projects {
+		a.interruptRuns(project)
 		needsReset := false
 
-		if project.PID > 0 {
+		if project.PGID > 0 {
+			a.logger.Info("Encontrado grupo de processos órfão com PGID registrado", map[string]interface{}{
+				"project": project.Name,
+				"pgid":    project.PGID,
+				"status":  project.Status,
+			})
+
+			forced, err := runner.TerminateProcessGroup(project.PGID, project.Manifest.StopGracePeriodDuration())
+			if err != nil {
+				a.logger.Warn("Erro ao encerrar grupo de processos órfão", map[string]interface{}{
+					"project": project.Name,
+					"pgid":    project.PGID,
+					"error":   err.Error(),
+				})
+			} else {
+				a.logger.Info("Grupo de processos órfão encerrado", map[string]interface{}{
+					"project": project.Name,
+					"pgid":    project.PGID,
+					"forced":  forced,
+				})
+			}
+			needsReset = true
+		} else if project.PID > 0 {
 			a.logger.Info("Encontrado processo órfão com PID registrado", map[string]interface{}{
 				"project": project.Name,
 				"pid":     project.PID,
@@ -584,32 +892,38 @@ func (a *App) cleanupOrphanProcesses() {
 		if project.Port > 0 {
 			conflict, err := a.CheckPortInUse(project.Port)
 			if err == nil && conflict != nil {
-				a.logger.Info("Encontrado processo órfão usando porta do projeto", map[string]interface{}{
-					"project": project.Name,
-					"port":    project.Port,
-					"pid":     conflict.PID,
-					"command": conflict.Command,
-				})
+				for _, owner := range conflict.Owners {
+					if owner.PID <= 0 {
+						continue
+					}
 
-				if err := a.KillProcessByPID(conflict.PID); err != nil {
-					a.logger.Warn("Erro ao matar processo órfão pela porta", map[string]interface{}{
-						"project": project.Name,
-						"port":    project.Port,
-						"pid":     conflict.PID,
-						"error":   err.Error(),
-					})
-				} else {
-					a.logger.Info("Processo órfão pela porta encerrado", map[string]interface{}{
+					a.logger.Info("Encontrado processo órfão usando porta do projeto", map[string]interface{}{
 						"project": project.Name,
 						"port":    project.Port,
-						"pid":     conflict.PID,
+						"pid":     owner.PID,
+						"command": owner.Command,
 					})
-					needsReset = true
+
+					if err := a.KillProcessByPID(owner.PID); err != nil {
+						a.logger.Warn("Erro ao matar processo órfão pela porta", map[string]interface{}{
+							"project": project.Name,
+							"port":    project.Port,
+							"pid":     owner.PID,
+							"error":   err.Error(),
+						})
+					} else {
+						a.logger.Info("Processo órfão pela porta encerrado", map[string]interface{}{
+							"project": project.Name,
+							"port":    project.Port,
+							"pid":     owner.PID,
+						})
+						needsReset = true
+					}
 				}
 			}
 		}
 
-		if !needsReset && (project.Status == domain.StatusError || project.Status == domain.StatusStarting || project.LastError != "") {
+		if !needsReset && (project.Status == domain.StatusError || project.Status == domain.StatusStarting || project.Status == domain.StatusCrashLoop || project.LastError != "") {
 			a.logger.Info("Resetando status transiente entre sessões", map[string]interface{}{
 				"project": project.Name,
 				"status":  project.Status,
@@ -619,6 +933,7 @@ func (a *App) cleanupOrphanProcesses() {
 
 		if needsReset {
 			project.PID = 0
+			project.PGID = 0
 			project.ClearError()
 			project.UpdateStatus(domain.StatusStopped)
 			if err := a.projectRepo.Update(project); err != nil {
@@ -627,10 +942,6 @@ func (a *App) cleanupOrphanProcesses() {
 					"error":   err.Error(),
 				})
 			}
-
-			if a.logRepo != nil {
-				_ = a.logRepo.DeleteByProjectID(project.ID)
-			}
 		}
 	}
 
@@ -701,7 +1012,11 @@ func (a *App) syncConfigProjects() {
 				})
 			}
 
-			if err := a.projectRepo.Update(existingProject); err != nil {
+			err := a.projectRepo.Update(existingProject)
+			if err == nil {
+				err = a.projectRepo.SaveDefinition(existingProject)
+			}
+			if err != nil {
 				a.logger.Warn("Erro ao atualizar projeto", map[string]interface{}{
 					"project": projectConfig.Name,
 					"error":   err.Error(),
@@ -786,82 +1101,31 @@ func (a *App) ensureRepositoryCloned(projectConfig config.ProjectConfig) error {
 }
 
 func (a *App) createProjectFromConfig(projectConfig config.ProjectConfig) *domain.Project {
-	resolvedPath := a.resolveProjectPath(projectConfig.Path)
 	project := domain.NewProject(
 		projectConfig.Name,
-		resolvedPath,
+		a.resolveProjectPath(projectConfig.Path),
 		projectConfig.Domain,
 		domain.ProjectType(projectConfig.Type),
 	)
-
-	project.Port = projectConfig.Port
-
-	project.Scripts = make(map[string]string)
-	for k, v := range projectConfig.Scripts {
-		project.Scripts[k] = v
-	}
-
-	project.Env = make(map[string]string)
-	for k, v := range projectConfig.Env {
-		project.Env[k] = v
-	}
-
-	project.Dependencies = make([]domain.Dependency, 0, len(projectConfig.Dependencies))
-	for _, dep := range projectConfig.Dependencies {
-		project.Dependencies = append(project.Dependencies, domain.Dependency{
-			Name:            dep.Name,
-			Version:         "",
-			RequiredVersion: dep.Version,
-			Managed:         dep.Managed,
-			Satisfied:       false,
-		})
-	}
-
-	if a.gitManager != nil {
-		if gitInfo, err := a.gitManager.GetGitInfo(a.ctx, resolvedPath); err != nil {
-			a.logger.Debug("Erro ao obter informações Git para projeto da config", map[string]interface{}{
-				"project": projectConfig.Name,
-				"path":    resolvedPath,
-				"error":   err.Error(),
-			})
-		} else {
-			project.UpdateGitInfo(gitInfo)
-		}
-	}
-
+	a.updateProjectFromConfig(project, projectConfig)
 	return project
 }
 
 func (a *App) updateProjectFromConfig(project *domain.Project, projectConfig config.ProjectConfig) {
-	project.Path = a.resolveProjectPath(projectConfig.Path)
-	project.Domain = projectConfig.Domain
-	project.Type = domain.ProjectType(projectConfig.Type)
-	project.Port = projectConfig.Port
-
-	project.Scripts = make(map[string]string)
-	for k, v := range projectConfig.Scripts {
-		project.Scripts[k] = v
-	}
-
-	project.Env = make(map[string]string)
-	for k, v := range projectConfig.Env {
-		project.Env[k] = v
-	}
-
-	project.Dependencies = make([]domain.Dependency, 0, len(projectConfig.Dependencies))
-	for _, dep := range projectConfig.Dependencies {
-		project.Dependencies = append(project.Dependencies, domain.Dependency{
-			Name:            dep.Name,
-			Version:         "",
-			RequiredVersion: dep.Version,
-			Managed:         dep.Managed,
-			Satisfied:       false,
+	path := a.resolveProjectPath(projectConfig.Path)
+	manifest, hash, err := readManifest(path)
+	if err != nil {
+		a.logger.Warn("relief.yaml inválido, usando a última versão lida", map[string]interface{}{
+			"project": projectConfig.Name,
+			"error":   err.Error(),
 		})
+		manifest, hash = project.Manifest, project.ManifestHash
 	}
+	a.applyDefinition(project, &projectConfig, manifest, hash)
 
 	if a.gitManager != nil {
 		if gitInfo, err := a.gitManager.GetGitInfo(a.ctx, project.Path); err != nil {
-			a.logger.Debug("Erro ao obter informações Git para projeto atualizado", map[string]interface{}{
+			a.logger.Debug("Erro ao obter informações Git para projeto da config", map[string]interface{}{
 				"project": projectConfig.Name,
 				"path":    project.Path,
 				"error":   err.Error(),
@@ -879,14 +1143,7 @@ func (a *App) StartProjectDependencies(id string) error {
 	}
 
 	depLogFn := func(level, message string) {
-		if a.logRepo != nil {
-			_ = a.logRepo.Create(&domain.LogEntry{
-				ProjectID: id,
-				Level:     level,
-				Message:   message,
-				Timestamp: time.Now().Format(time.RFC3339),
-			})
-		}
+		a.appendProjectLog(id, level, message)
 	}
 	return a.enhancedDepMgr.StartManagedDependencies(a.ctx, project, depLogFn)
 }
@@ -911,7 +1168,7 @@ func (a *App) getDepsInUseByOtherProjects(stoppingID string) map[string]bool {
 		return inUse
 	}
 	for _, p := range projects {
-		if p.ID == stoppingID || p.Status != domain.StatusRunning {
+		if p.ID == stoppingID || !p.IsActive() {
 			continue
 		}
 		for _, dep := range p.Dependencies {
@@ -1037,27 +1294,11 @@ func (a *App) RefreshProjectGitInfo(id string) error {
 }
 
 func (a *App) RunProjectScript(id string, scriptName string) error {
-	project, err := a.projectRepo.GetByID(id)
+	project, err := a.loadProject(id)
 	if err != nil {
 		return fmt.Errorf("projeto não encontrado: %w", err)
 	}
 
-	if len(project.Scripts) == 0 {
-
-		manifest, err := domain.ParseManifest(pathutil.FromRelativeHome(project.Path))
-		if err != nil {
-			for _, pc := range a.config.Projects {
-				if pc.Name == project.Name {
-					a.updateProjectFromConfig(project, pc)
-					break
-				}
-			}
-		} else {
-			project.Manifest = manifest
-			project.Scripts = manifest.Scripts
-		}
-	}
-
 	script, exists := project.Scripts[scriptName]
 	if !exists {
 		return fmt.Errorf("script '%s' não encontrado no projeto '%s'", scriptName, project.Name)
@@ -1071,77 +1312,30 @@ func (a *App) RunProjectScript(id string, scriptName string) error {
 
 	projectPath := pathutil.FromRelativeHome(project.Path)
 
-	wrappedScript := fmt.Sprintf(`set -a; [ -f .env ] && . .env; set +a; %s`, script)
-	cmd := exec.CommandContext(a.ctx, "sh", "-c", wrappedScript)
-	cmd.Dir = projectPath
-
-	cmd.Env = os.Environ()
-	for k, v := range project.Env {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	env, err := a.resolveProjectEnv(project, true)
+	if err != nil {
+		return fmt.Errorf("erro ao montar variáveis de ambiente: %w", err)
 	}
 
-	output, err := cmd.CombinedOutput()
+	cmd := exec.CommandContext(a.ctx, "sh", "-c", script)
+	cmd.Dir = projectPath
+	cmd.Env = env.Environ()
+
+	rawOutput, err := cmd.CombinedOutput()
+	output := secrets.MaskValues(string(rawOutput), env.Secrets())
 	if err != nil {
-		return fmt.Errorf("erro ao executar script '%s': %w\nOutput: %s", scriptName, err, string(output))
+		return fmt.Errorf("erro ao executar script '%s': %w\nOutput: %s", scriptName, err, output)
 	}
 
 	a.logger.Info("Script executado com sucesso", map[string]interface{}{
 		"project": project.Name,
 		"script":  scriptName,
-		"output":  string(output),
+		"output":  output,
 	})
 
 	return nil
 }
 
-func (a *App) SetupProjectEnv(id string) error {
-	project, err := a.projectRepo.GetByID(id)
-	if err != nil {
-		return fmt.Errorf("projeto não encontrado: %w", err)
-	}
-
-	return a.writeEnvFile(project.Path, project.Name, project.Env)
-}
-
-func (a *App) writeEnvFile(projectPath, projectName string, configEnv map[string]string) error {
-	resolvedPath := pathutil.FromRelativeHome(projectPath)
-	envPath := resolvedPath + "/.env"
-
-	existing := make(map[string]string)
-	if data, readErr := os.ReadFile(envPath); readErr == nil {
-		for _, line := range strings.Split(string(data), "\n") {
-			line = strings.TrimSpace(line)
-			if line == "" || strings.HasPrefix(line, "#") {
-				continue
-			}
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				existing[parts[0]] = parts[1]
-			}
-		}
-	}
-
-	for k, v := range configEnv {
-		existing[k] = v
-	}
-
-	var sb strings.Builder
-	sb.WriteString("# Gerado automaticamente pelo Relief — não edite manualmente as chaves abaixo\n")
-	for k, v := range existing {
-		sb.WriteString(fmt.Sprintf("%s=%s\n", k, v))
-	}
-
-	if err := os.WriteFile(envPath, []byte(sb.String()), 0644); err != nil {
-		return fmt.Errorf("erro ao gravar .env: %w", err)
-	}
-
-	a.logger.Info("Arquivo .env atualizado", map[string]interface{}{
-		"project": projectName,
-		"path":    envPath,
-	})
-
-	return nil
-}
 func (a *App) GetManagedServices() []interface{} {
 	if a.enhancedDepMgr == nil {
 		return []interface{}{}
@@ -1225,6 +1419,9 @@ func (a *App) SaveGlobalConfig(configYAML string) error {
 
 	a.config = &newConfig
 	a.syncConfigProjects()
+	if a.statusPages != nil {
+		a.publishProjects()
+	}
 
 	return nil
 }
@@ -1242,6 +1439,9 @@ func (a *App) ReloadConfig() error {
 
 	a.config = cfg
 	a.syncConfigProjects()
+	if a.statusPages != nil {
+		a.publishProjects()
+	}
 
 	a.logger.Info("Configuração recarregada", nil)
 	return nil
@@ -1269,6 +1469,9 @@ func (a *App) OpenConfigInEditor() error {
 		return fmt.Errorf("erro ao obter caminho da config: %w", err)
 	}
 
+	if a.headless {
+		return fmt.Errorf("abrir no editor indisponível no modo headless: %s", configPath)
+	}
 	runtime.BrowserOpenURL(a.ctx, "file://"+configPath)
 	return nil
 }
@@ -1281,13 +1484,19 @@ func (a *App) GetGlobalScripts() map[string]string {
 }
 
 func (a *App) ExecuteGlobalScript(scriptName string) error {
+	_, err := a.RunGlobalScript(scriptName)
+	return err
+}
+
+// RunGlobalScript executa um script global e retorna a saída combinada.
+func (a *App) RunGlobalScript(scriptName string) (string, error) {
 	if a.config == nil {
-		return fmt.Errorf("configuração não carregada")
+		return "", fmt.Errorf("configuração não carregada")
 	}
 
 	script, exists := a.config.Development.GlobalScripts[scriptName]
 	if !exists {
-		return fmt.Errorf("script '%s' não encontrado", scriptName)
+		return "", fmt.Errorf("script '%s' não encontrado", scriptName)
 	}
 
 	a.logger.Info("Executando script global", map[string]interface{}{
@@ -1302,20 +1511,27 @@ func (a *App) ExecuteGlobalScript(scriptName string) error {
 
 	workspaceDir = pathutil.FromRelativeHome(workspaceDir)
 
+	env, err := a.resolveGlobalEnv()
+	if err != nil {
+		return "", fmt.Errorf("erro ao montar variáveis de ambiente: %w", err)
+	}
+
 	cmd := exec.CommandContext(a.ctx, "sh", "-c", script)
 	cmd.Dir = workspaceDir
+	cmd.Env = env.Environ()
 
-	output, err := cmd.CombinedOutput()
+	rawOutput, err := cmd.CombinedOutput()
+	output := secrets.MaskValues(string(rawOutput), env.Secrets())
 	if err != nil {
-		return fmt.Errorf("erro ao executar script: %w\nOutput: %s", err, string(output))
+		return output, fmt.Errorf("erro ao executar script: %w\nOutput: %s", err, output)
 	}
 
 	a.logger.Info("Script global executado com sucesso", map[string]interface{}{
 		"script": scriptName,
-		"output": string(output),
+		"output": output,
 	})
 
-	return nil
+	return output, nil
 }
 
 // OpenProjectFolder abre a pasta do projeto no Finder (macOS) ou file manager do sistema.
@@ -1490,7 +1706,7 @@ func (a *App) checkGitHeadChanges() {
 
 			if exists {
 				// Só emite evento se não é a primeira leitura
-				runtime.EventsEmit(a.ctx, "git:branch-changed", map[string]interface{}{
+				a.emitEvent("git:branch-changed", map[string]interface{}{
 					"projectId": p.ID,
 					"branch":    branch,
 				})
@@ -1498,3 +1714,57 @@ func (a *App) checkGitHeadChanges() {
 		}
 	}
 }
+
+func (a *App) startAPIServer() {
+	server := api.NewServer(a, a.logger)
+
+	reliefDir, err := fileutil.GetReliefDir()
+	if err != nil {
+		a.logger.Warn("Erro ao localizar diretório do Relief para a API", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	socketPath, err := ControlSocketPath(a.config)
+	if err != nil {
+		a.logger.Warn("Erro ao localizar o socket da API", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	started := false
+	if err := server.ListenUnix(socketPath); err != nil {
+		a.logger.Warn("Erro ao iniciar API de controle no socket", map[string]interface{}{
+			"socket": socketPath,
+			"error":  err.Error(),
+		})
+	} else {
+		started = true
+	}
+
+	if a.config.API.TCPAddress != "" {
+		if err := server.ListenTCP(a.config.API.TCPAddress, filepath.Join(reliefDir, api.TokenName)); err != nil {
+			a.logger.Warn("Erro ao iniciar API de controle em TCP", map[string]interface{}{
+				"address": a.config.API.TCPAddress,
+				"error":   err.Error(),
+			})
+		} else {
+			started = true
+		}
+	}
+
+	if started {
+		a.apiServer = server
+	}
+}
+
+// emitEvent envia um evento ao frontend; no modo headless não há janela e o
+// evento é descartado.
+func (a *App) emitEvent(name string, data ...interface{}) {
+	if a.headless || a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, name, data...)
+}
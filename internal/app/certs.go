@@ -0,0 +1,80 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/internal/proxy"
+)
+
+// httpsEnabled indica se o proxy publica os domínios também em HTTPS.
+func (a *App) httpsEnabled() bool {
+	return a.certMgr != nil && a.config != nil && !a.config.Proxy.DisableHTTPS
+}
+
+// httpsRedirect indica se o domínio do projeto redireciona de HTTP para
+// HTTPS: basta pedir no relief.yaml ou na configuração global.
+func (a *App) httpsRedirect(project *domain.Project) bool {
+	if !a.httpsEnabled() {
+		return false
+	}
+	if project.Manifest != nil && project.Manifest.HTTPSRedirect {
+		return true
+	}
+	if pc := a.config.GetProjectByName(project.Name); pc != nil && pc.HTTPSRedirect {
+		return true
+	}
+	return false
+}
+
+// projectURL é o endereço do domínio do projeto no proxy, em HTTPS quando o
+// projeto redireciona para lá.
+func (a *App) projectURL(project *domain.Project) string {
+	if a.httpsRedirect(project) {
+		url := "https://" + project.Domain
+		if port := a.config.Proxy.HTTPSPort; port > 0 && port != 443 {
+			url += ":" + strconv.Itoa(port)
+		}
+		return url
+	}
+	url := "http://" + project.Domain
+	if a.config != nil && a.config.Proxy.HTTPPort > 0 && a.config.Proxy.HTTPPort != 80 {
+		url += ":" + strconv.Itoa(a.config.Proxy.HTTPPort)
+	}
+	return url
+}
+
+// GetCertificateAuthority descreve a CA local usada no HTTPS dos domínios,
+// criando-a na primeira chamada.
+func (a *App) GetCertificateAuthority() (*proxy.CAInfo, error) {
+	if a.certMgr == nil {
+		return nil, fmt.Errorf("HTTPS desabilitado")
+	}
+	return a.certMgr.Info()
+}
+
+// TrustCertificateAuthority instala a CA local no repositório de confiança do
+// sistema, para que os navegadores aceitem os certificados dos domínios sem
+// alerta. Pede privilégios administrativos.
+func (a *App) TrustCertificateAuthority() (*proxy.CAInfo, error) {
+	if a.certMgr == nil {
+		return nil, fmt.Errorf("HTTPS desabilitado")
+	}
+	if err := a.certMgr.InstallTrust(); err != nil {
+		return nil, err
+	}
+	return a.certMgr.Info()
+}
+
+// UntrustCertificateAuthority remove a CA local do repositório de confiança do
+// sistema.
+func (a *App) UntrustCertificateAuthority() (*proxy.CAInfo, error) {
+	if a.certMgr == nil {
+		return nil, fmt.Errorf("HTTPS desabilitado")
+	}
+	if err := a.certMgr.UninstallTrust(); err != nil {
+		return nil, err
+	}
+	return a.certMgr.Info()
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Maycon-Santos/relief/internal/config"
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/pkg/pathutil"
+)
+
+// applyDefinition monta a definição do projeto a partir da entrada em
+// config.yaml (pc) e do relief.yaml (manifest), qualquer um deles podendo
+// faltar, e guarda a origem de cada campo em project.Sources.
+//
+// Domínio, tipo, porta e dependências da configuração têm prioridade sobre o
+// relief.yaml. Em scripts e env vale o contrário, como no runner e em envvars:
+// o relief.yaml do projeto sobrescreve a configuração.
+func (a *App) applyDefinition(project *domain.Project, pc *config.ProjectConfig, manifest *domain.Manifest, manifestHash string) {
+	sources := map[string]string{}
+	scripts := map[string]string{}
+	env := map[string]string{}
+	var deps []domain.Dependency
+
+	project.Manifest = manifest
+	project.ManifestHash = manifestHash
+	project.Domain = ""
+	project.Port = 0
+
+	if manifest != nil {
+		sources["manifest"] = domain.SourceManifest
+		if manifest.Domain != "" {
+			project.Domain = manifest.Domain
+			sources["domain"] = domain.SourceManifest
+		}
+		project.Type = domain.ProjectType(manifest.Type)
+		sources["type"] = domain.SourceManifest
+		if port := manifest.DeclaredPorts()["main"]; port > 0 {
+			project.Port = port
+			sources["port"] = domain.SourceManifest
+		}
+		for name, command := range manifest.Scripts {
+			scripts[name] = command
+			sources["scripts."+name] = domain.SourceManifest
+		}
+		for key, value := range manifest.Env {
+			env[key] = value
+			sources["env."+key] = domain.SourceManifest
+		}
+		for _, dep := range manifest.Dependencies {
+			deps = append(deps, domain.Dependency{
+				Name:            dep.Name,
+				RequiredVersion: dep.Version,
+				Managed:         dep.Managed,
+			})
+		}
+	}
+
+	if pc != nil {
+		project.Path = a.resolveProjectPath(pc.Path)
+		sources["path"] = domain.SourceConfig
+		if pc.Domain != "" {
+			project.Domain = pc.Domain
+			sources["domain"] = domain.SourceConfig
+		}
+		if pc.Type != "" {
+			project.Type = domain.ProjectType(pc.Type)
+			sources["type"] = domain.SourceConfig
+		}
+		if pc.Port > 0 {
+			project.Port = pc.Port
+			sources["port"] = domain.SourceConfig
+		}
+		for name, command := range pc.Scripts {
+			if _, ok := scripts[name]; !ok {
+				scripts[name] = command
+				sources["scripts."+name] = domain.SourceConfig
+			}
+		}
+		for key, value := range pc.Env {
+			if _, ok := env[key]; !ok {
+				env[key] = value
+				sources["env."+key] = domain.SourceConfig
+			}
+		}
+		if len(pc.Dependencies) > 0 {
+			deps = make([]domain.Dependency, 0, len(pc.Dependencies))
+			for _, dep := range pc.Dependencies {
+				deps = append(deps, domain.Dependency{
+					Name:            dep.Name,
+					RequiredVersion: dep.Version,
+					Managed:         dep.Managed,
+				})
+			}
+		}
+	}
+
+	if deps == nil {
+		deps = []domain.Dependency{}
+	}
+	project.Scripts = scripts
+	project.Env = env
+	project.Dependencies = deps
+	project.Sources = sources
+}
+
+// readManifest lê o relief.yaml do projeto e o hash do conteúdo. Sem o
+// arquivo, retorna nil sem erro.
+func readManifest(projectPath string) (*domain.Manifest, string, error) {
+	data, err := os.ReadFile(filepath.Join(pathutil.FromRelativeHome(projectPath), "relief.yaml"))
+	if os.IsNotExist(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao ler relief.yaml: %w", err)
+	}
+
+	manifest, err := domain.ParseManifestData(data)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(data)
+	return manifest, hex.EncodeToString(sum[:]), nil
+}
+
+// loadProject busca o projeto com a definição atualizada.
+func (a *App) loadProject(id string) (*domain.Project, error) {
+	project, err := a.projectRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	a.refreshDefinition(project)
+	return project, nil
+}
+
+// refreshDefinition remonta e grava a definição quando o relief.yaml mudou
+// desde a última leitura. Mudanças no config.yaml chegam por
+// syncConfigProjects. Um relief.yaml removido ou inválido mantém o último
+// snapshot guardado.
+func (a *App) refreshDefinition(project *domain.Project) {
+	var pc *config.ProjectConfig
+	if a.config != nil {
+		pc = a.config.GetProjectByName(project.Name)
+	}
+	path := project.Path
+	if pc != nil {
+		path = a.resolveProjectPath(pc.Path)
+	}
+
+	manifest, hash, err := readManifest(path)
+	if err != nil {
+		a.logger.Warn("relief.yaml inválido, usando a última versão lida", map[string]interface{}{
+			"project": project.Name,
+			"error":   err.Error(),
+		})
+	}
+	if manifest == nil {
+		manifest, hash = project.Manifest, project.ManifestHash
+	}
+	if hash == project.ManifestHash && len(project.Sources) > 0 {
+		return
+	}
+
+	a.applyDefinition(project, pc, manifest, hash)
+	if err := a.projectRepo.SaveDefinition(project); err != nil {
+		a.logger.Warn("Erro ao salvar definição do projeto", map[string]interface{}{
+			"project": project.Name,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// GetProjectChanges lista as alterações na definição do projeto, das mais
+// recentes para as mais antigas.
+func (a *App) GetProjectChanges(id string, limit int) ([]domain.ProjectChange, error) {
+	if _, err := a.projectRepo.GetByID(id); err != nil {
+		return nil, fmt.Errorf("projeto não encontrado: %w", err)
+	}
+	return a.projectRepo.Changes(id, limit)
+}
@@ -0,0 +1,266 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/internal/envvars"
+	"github.com/Maycon-Santos/relief/pkg/dotenv"
+	"github.com/Maycon-Santos/relief/pkg/fileutil"
+	"github.com/Maycon-Santos/relief/pkg/pathutil"
+	"github.com/Maycon-Santos/relief/pkg/shellenv"
+)
+
+// resolveProjectEnv monta o ambiente do projeto. As camadas, da menos para a
+// mais prioritária: sistema, portas do Relief (PORT, PORT_<NOME>), env global
+// da configuração, env do projeto na configuração, env do relief.yaml, .env e
+// .env.local. Referências secret:// só são lidas com withSecrets, logo antes
+// de iniciar um processo.
+func (a *App) resolveProjectEnv(project *domain.Project, withSecrets bool) (*envvars.Env, error) {
+	ports := project.Ports
+	if ports == nil {
+		ports = a.knownPorts(project)
+	}
+	relief := map[string]string{}
+	if project.Port > 0 {
+		relief["PORT"] = strconv.Itoa(project.Port)
+	}
+	for name, port := range ports {
+		if name == "main" || port <= 0 {
+			continue
+		}
+		relief["PORT_"+strings.ToUpper(strings.ReplaceAll(name, "-", "_"))] = strconv.Itoa(port)
+	}
+
+	layers := []envvars.Layer{
+		envvars.OSLayer(shellenv.EnrichedEnv()),
+		{Source: envvars.SourceRelief, Values: relief},
+	}
+	if a.config != nil {
+		layers = append(layers, envvars.Layer{Source: envvars.SourceGlobalConfig, Values: a.config.Env})
+		if pc := a.config.GetProjectByName(project.Name); pc != nil {
+			layers = append(layers, envvars.Layer{Source: envvars.SourceProjectConfig, Values: pc.Env})
+		}
+	}
+	if project.Manifest != nil {
+		layers = append(layers, envvars.Layer{Source: envvars.SourceManifest, Values: project.Manifest.Env})
+	}
+
+	projectPath := pathutil.FromRelativeHome(project.Path)
+	for _, file := range []envvars.Source{envvars.SourceDotEnv, envvars.SourceDotEnvLocal} {
+		layer, err := envvars.FileLayer(file, filepath.Join(projectPath, string(file)))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	return envvars.Resolve(layers, a.envOptions(withSecrets))
+}
+
+// resolveGlobalEnv é o ambiente dos scripts globais: sistema e env global.
+func (a *App) resolveGlobalEnv() (*envvars.Env, error) {
+	layers := []envvars.Layer{envvars.OSLayer(shellenv.EnrichedEnv())}
+	if a.config != nil {
+		layers = append(layers, envvars.Layer{Source: envvars.SourceGlobalConfig, Values: a.config.Env})
+	}
+	return envvars.Resolve(layers, a.envOptions(true))
+}
+
+func (a *App) envOptions(withSecrets bool) envvars.Options {
+	opts := envvars.Options{Projects: a.lookupProjectInfo}
+	if withSecrets && a.secretReg != nil {
+		ctx := a.ctx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		opts.Secrets = func(ref string) (string, error) {
+			return a.secretReg.Resolve(ctx, ref)
+		}
+	}
+	return opts
+}
+
+// knownPorts são as portas fixas do projeto somadas às já alocadas, sem
+// alocar nada novo.
+func (a *App) knownPorts(project *domain.Project) map[string]int {
+	ports := a.declaredPorts(project)
+	if a.portRepo != nil {
+		if allocated, err := a.portRepo.GetByProjectID(project.ID); err == nil {
+			for name, port := range allocated {
+				if ports[name] <= 0 {
+					ports[name] = port
+				}
+			}
+		}
+	}
+	return ports
+}
+
+// lookupProjectInfo atende as referências ${project:<nome>.<campo>}.
+func (a *App) lookupProjectInfo(name string) (*envvars.ProjectInfo, error) {
+	project, err := a.projectRepo.GetByName(name)
+	if err != nil || project == nil {
+		return nil, fmt.Errorf("projeto '%s' não encontrado", name)
+	}
+
+	info := &envvars.ProjectInfo{
+		Name:   project.Name,
+		Domain: project.Domain,
+		Ports:  a.knownPorts(project),
+	}
+	if port := info.Ports["main"]; port <= 0 && project.Port > 0 {
+		info.Ports["main"] = project.Port
+	}
+
+	switch {
+	case info.Domain != "":
+		info.URL = a.projectURL(project)
+	case info.Ports["main"] > 0:
+		info.URL = "http://localhost:" + strconv.Itoa(info.Ports["main"])
+	}
+	return info, nil
+}
+
+// GetProjectEnv lista o ambiente resolvido do projeto com a origem de cada
+// variável. Variáveis do sistema só aparecem com includeOS. Segredos não são
+// lidos: aparecem como a referência secret:// e marcados com Secret.
+func (a *App) GetProjectEnv(id string, includeOS bool) ([]envvars.Variable, error) {
+	project, err := a.loadProject(id)
+	if err != nil {
+		return nil, fmt.Errorf("projeto não encontrado: %w", err)
+	}
+
+	env, err := a.resolveProjectEnv(project, false)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := env.Variables()
+	if includeOS {
+		return vars, nil
+	}
+	defined := make([]envvars.Variable, 0, len(vars))
+	for _, v := range vars {
+		if v.Source != envvars.SourceOS {
+			defined = append(defined, v)
+		}
+	}
+	return defined, nil
+}
+
+// envFileHeader marca, no .env, onde começam as chaves acrescentadas pelo Relief.
+const envFileHeader = "Chaves gerenciadas pelo Relief (env do projeto no config.yaml)"
+
+// EnvFilePreview é o que SetupProjectEnv mudaria no .env do projeto: o diff
+// no formato unificado e as chaves acrescentadas ou alteradas.
+type EnvFilePreview struct {
+	Path    string   `json:"path"`
+	Exists  bool     `json:"exists"`
+	Diff    string   `json:"diff"`
+	Changed []string `json:"changed"`
+}
+
+// planEnvFile aplica values sobre o .env atual sem gravar nada. Só as chaves
+// de values são tocadas; o resto do arquivo (comentários, ordem, aspas,
+// export) fica como está. Chaves novas vão para o fim, abaixo de envFileHeader.
+func planEnvFile(projectPath string, values map[string]string) (*EnvFilePreview, string, error) {
+	envPath := filepath.Join(pathutil.FromRelativeHome(projectPath), ".env")
+	preview := &EnvFilePreview{Path: envPath, Changed: []string{}}
+
+	before := ""
+	if data, err := os.ReadFile(envPath); err == nil {
+		before = string(data)
+		preview.Exists = true
+	} else if !os.IsNotExist(err) {
+		return nil, "", fmt.Errorf("erro ao ler .env: %w", err)
+	}
+
+	doc, err := dotenv.ParseDocument(before)
+	if err != nil {
+		return nil, "", fmt.Errorf("erro ao ler .env: %w", err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, exists := doc.Get(key); !exists && !doc.HasComment(envFileHeader) {
+			doc.AddComment(envFileHeader)
+		}
+		if doc.Set(key, values[key]) {
+			preview.Changed = append(preview.Changed, key)
+		}
+	}
+
+	after := doc.String()
+	preview.Diff = fileutil.Diff(".env", before, after)
+	return preview, after, nil
+}
+
+// PreviewProjectEnv mostra o que SetupProjectEnv faria no .env do projeto.
+func (a *App) PreviewProjectEnv(id string) (*EnvFilePreview, error) {
+	project, err := a.loadProject(id)
+	if err != nil {
+		return nil, fmt.Errorf("projeto não encontrado: %w", err)
+	}
+
+	preview, _, err := planEnvFile(project.Path, a.configEnv(project))
+	return preview, err
+}
+
+// SetupProjectEnv grava no .env do projeto o env definido para ele no config.yaml.
+func (a *App) SetupProjectEnv(id string) error {
+	project, err := a.projectRepo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("projeto não encontrado: %w", err)
+	}
+
+	return a.writeEnvFile(project.Path, project.Name, a.configEnv(project))
+}
+
+func (a *App) configEnv(project *domain.Project) map[string]string {
+	if a.config == nil {
+		return nil
+	}
+	if pc := a.config.GetProjectByName(project.Name); pc != nil {
+		return pc.Env
+	}
+	return nil
+}
+
+func (a *App) writeEnvFile(projectPath, projectName string, configEnv map[string]string) error {
+	preview, content, err := planEnvFile(projectPath, configEnv)
+	if err != nil {
+		return err
+	}
+	if len(preview.Changed) == 0 {
+		return nil
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(preview.Path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(preview.Path, []byte(content), mode); err != nil {
+		return fmt.Errorf("erro ao gravar .env: %w", err)
+	}
+
+	a.logger.Info("Arquivo .env atualizado", map[string]interface{}{
+		"project": projectName,
+		"path":    preview.Path,
+		"keys":    strings.Join(preview.Changed, ", "),
+		"diff":    preview.Diff,
+	})
+
+	return nil
+}
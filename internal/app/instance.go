@@ -0,0 +1,126 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/api"
+	"github.com/Maycon-Santos/relief/internal/config"
+	"github.com/Maycon-Santos/relief/pkg/fileutil"
+	"github.com/Maycon-Santos/relief/pkg/instancelock"
+	"github.com/Maycon-Santos/relief/pkg/pathutil"
+)
+
+// InstanceLockName é o lock, em ~/.relief, de quem gerencia os processos dos
+// projetos: a interface ou um "relief up" sem a interface aberta.
+const InstanceLockName = "relief.lock"
+
+// instanceLockRetry é o intervalo com que a interface tenta tomar o lock
+// deixado por uma CLI ainda em execução.
+const instanceLockRetry = 5 * time.Second
+
+// instanceLock guarda o lock tomado pela interface.
+type instanceLock struct {
+	mu   sync.Mutex
+	lock *instancelock.Lock
+}
+
+// InstanceLockPath é o caminho do lock da instância.
+func InstanceLockPath() (string, error) {
+	reliefDir, err := fileutil.GetReliefDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(reliefDir, InstanceLockName), nil
+}
+
+// ControlSocketPath é o socket da API de controle segundo a configuração, ou
+// vazio com a API desligada.
+func ControlSocketPath(cfg *config.Config) (string, error) {
+	if cfg != nil && cfg.API.Disabled {
+		return "", nil
+	}
+	if cfg != nil && cfg.API.Socket != "" {
+		return pathutil.FromRelativeHome(cfg.API.Socket), nil
+	}
+	reliefDir, err := fileutil.GetReliefDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(reliefDir, api.SocketName), nil
+}
+
+// acquireInstanceLock toma o lock da instância. Retorna false quando outra
+// instância viva o tem: os PGIDs guardados no banco podem ser dela, então a
+// limpeza de órfãos não roda, e o lock é tentado de novo em segundo plano até
+// a outra instância terminar.
+func (a *App) acquireInstanceLock() bool {
+	path, err := InstanceLockPath()
+	if err != nil {
+		a.logger.Warn("Erro ao localizar o lock da instância", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return true
+	}
+
+	lock, err := instancelock.Acquire(path)
+	if err == nil {
+		a.instance.mu.Lock()
+		a.instance.lock = lock
+		a.instance.mu.Unlock()
+		return true
+	}
+
+	var held *instancelock.HeldError
+	if !errors.As(err, &held) {
+		a.logger.Warn("Erro ao tomar o lock da instância", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return true
+	}
+
+	a.logger.Warn("Outra instância do Relief gerencia projetos; processos órfãos não serão limpos", map[string]interface{}{
+		"pid": held.PID,
+	})
+	go a.retryInstanceLock(path)
+	return false
+}
+
+func (a *App) retryInstanceLock(path string) {
+	ticker := time.NewTicker(instanceLockRetry)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		lock, err := instancelock.Acquire(path)
+		if err != nil {
+			continue
+		}
+		a.instance.mu.Lock()
+		if a.instance.lock != nil {
+			a.instance.mu.Unlock()
+			_ = lock.Release()
+			return
+		}
+		a.instance.lock = lock
+		a.instance.mu.Unlock()
+		a.logger.Info("Lock da instância tomado", nil)
+		return
+	}
+}
+
+func (a *App) releaseInstanceLock() {
+	a.instance.mu.Lock()
+	defer a.instance.mu.Unlock()
+	if a.instance.lock != nil {
+		_ = a.instance.lock.Release()
+		a.instance.lock = nil
+	}
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+)
+
+const (
+	logRetentionInterval = 10 * time.Minute
+
+	// logQueueSize limita as linhas à espera de gravação. Com a fila cheia,
+	// quem loga espera: linhas de log não são descartadas.
+	logQueueSize     = 4096
+	logBatchSize     = 500
+	logFlushInterval = 100 * time.Millisecond
+)
+
+// beginRun abre uma nova execução do projeto: tudo que for logado até o
+// próximo start fica agrupado sob o mesmo run ID.
+func (a *App) beginRun(id string) string {
+	runID := time.Now().Format("20060102-150405.000")
+
+	a.runnersMu.Lock()
+	a.runIDs[id] = runID
+	a.runnersMu.Unlock()
+
+	return runID
+}
+
+func (a *App) currentRun(id string) string {
+	a.runnersMu.RLock()
+	defer a.runnersMu.RUnlock()
+	return a.runIDs[id]
+}
+
+func (a *App) appendProjectLog(id, level, message string) {
+	a.storeLog(&domain.LogEntry{
+		ProjectID: id,
+		RunID:     a.currentRun(id),
+		Level:     level,
+		Message:   message,
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+}
+
+// storeLog mascara os segredos do projeto e entrega a entrada ao gravador de
+// logs, que grava em lotes e acorda a assinatura do projeto. Sem o gravador,
+// ou depois de ele parar, grava direto.
+func (a *App) storeLog(entry *domain.LogEntry) {
+	if a.logRepo == nil {
+		return
+	}
+	if a.secretMask != nil {
+		entry.Message = a.secretMask.Mask(entry.ProjectID, entry.Message)
+		for key, value := range entry.Fields {
+			entry.Fields[key] = a.secretMask.Mask(entry.ProjectID, value)
+		}
+	}
+
+	if a.logQueue != nil {
+		select {
+		case <-a.logsStopped:
+		default:
+			select {
+			case a.logQueue <- entry:
+				return
+			case <-a.logsStopped:
+			}
+		}
+	}
+	a.flushLogs([]*domain.LogEntry{entry})
+}
+
+// startLogWriter grava os logs capturados em lotes, fora das goroutines que
+// leem a saída dos processos. stopLogs grava o que ainda está na fila.
+func (a *App) startLogWriter() {
+	queue := make(chan *domain.LogEntry, logQueueSize)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	a.logQueue = queue
+	a.logsStopped = done
+	a.stopLogs = func() {
+		close(stop)
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(logFlushInterval)
+		defer ticker.Stop()
+
+		batch := make([]*domain.LogEntry, 0, logBatchSize)
+		for {
+			select {
+			case entry := <-queue:
+				batch = append(batch, entry)
+				if len(batch) < logBatchSize {
+					continue
+				}
+			case <-ticker.C:
+			case <-stop:
+				for len(queue) > 0 {
+					batch = append(batch, <-queue)
+				}
+				a.flushLogs(batch)
+				return
+			}
+			a.flushLogs(batch)
+			batch = batch[:0]
+		}
+	}()
+}
+
+func (a *App) flushLogs(batch []*domain.LogEntry) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := a.logRepo.CreateBatch(batch); err != nil {
+		a.logger.Warn("Erro ao gravar logs", map[string]interface{}{
+			"count": len(batch),
+			"error": err.Error(),
+		})
+		return
+	}
+
+	notified := map[string]bool{}
+	for _, entry := range batch {
+		if !notified[entry.ProjectID] {
+			notified[entry.ProjectID] = true
+			a.notifyLogSubscriber(entry.ProjectID)
+		}
+	}
+}
+
+func (a *App) GetProjectLogs(id string, tail int) ([]domain.LogEntry, error) {
+	page, err := a.logRepo.GetByProjectID(id, domain.LogQuery{Limit: tail})
+	if err != nil {
+		return nil, err
+	}
+	return page.Entries, nil
+}
+
+// QueryProjectLogs pagina o histórico de logs do projeto, com filtros por
+// execução, nível e busca textual.
+func (a *App) QueryProjectLogs(id string, query domain.LogQuery) (*domain.LogPage, error) {
+	return a.logRepo.GetByProjectID(id, query)
+}
+
+func (a *App) GetProjectRuns(id string) ([]domain.LogRun, error) {
+	return a.logRepo.ListRuns(id, 50)
+}
+
+// GetProjectLogsSince retorna os logs persistidos com ID maior que afterID, em
+// ordem cronológica.
+func (a *App) GetProjectLogsSince(id string, afterID int64, limit int) ([]domain.LogEntry, error) {
+	return a.logRepo.GetSince(id, afterID, limit)
+}
+
+// startLogRetention aplica logging.max_age e logging.max_size agora e depois
+// periodicamente, até o App ser encerrado. stopRetention espera a passada em
+// andamento terminar, para o banco não ser fechado no meio dela.
+func (a *App) startLogRetention() {
+	retentionCtx, cancel := context.WithCancel(a.ctx)
+	done := make(chan struct{})
+	a.stopRetention = func() {
+		cancel()
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(logRetentionInterval)
+		defer ticker.Stop()
+
+		for {
+			a.enforceLogRetention()
+
+			select {
+			case <-retentionCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (a *App) enforceLogRetention() {
+	maxAge := a.config.Logging.MaxAgeDuration()
+	maxSize := a.config.Logging.MaxSizeBytes()
+
+	var expired, trimmed int64
+	var err error
+
+	if maxAge > 0 {
+		if expired, err = a.logRepo.DeleteOldLogs(time.Now().Add(-maxAge)); err != nil {
+			a.logger.Warn("Erro ao remover logs antigos", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	if maxSize > 0 {
+		if trimmed, err = a.logRepo.TrimToSize(maxSize); err != nil {
+			a.logger.Warn("Erro ao limitar tamanho dos logs", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	if expired > 0 || trimmed > 0 {
+		a.logger.Info("Retenção de logs aplicada", map[string]interface{}{
+			"expired": expired,
+			"trimmed": trimmed,
+			"max_age": maxAge.String(),
+		})
+	}
+}
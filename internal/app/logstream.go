@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+)
+
+const (
+	// logStreamInterval é o intervalo mínimo entre dois eventos de um mesmo
+	// projeto: o que chegar nesse meio tempo vai junto no próximo lote.
+	logStreamInterval = 100 * time.Millisecond
+	logStreamBatch    = 500
+
+	// logStreamMaxLag é quantas linhas o frontend pode ficar para trás antes
+	// de a assinatura pular direto para as mais recentes.
+	logStreamMaxLag = 5000
+)
+
+// logSubscription acompanha um projeto com os logs abertos no frontend. As
+// linhas são lidas do banco a partir do último ID enviado, então chegam em
+// ordem e sem duplicatas, e um frontend lento nunca segura quem grava os logs.
+type logSubscription struct {
+	notify chan struct{}
+	cancel context.CancelFunc
+}
+
+func logStreamEvent(id string) string {
+	return "logs:" + id
+}
+
+// SubscribeProjectLogs passa a enviar as linhas do projeto com ID maior que
+// afterID pelo evento "logs:<id>". Uma assinatura anterior do mesmo projeto é
+// substituída.
+func (a *App) SubscribeProjectLogs(id string, afterID int64) error {
+	if _, err := a.projectRepo.GetByID(id); err != nil {
+		return fmt.Errorf("projeto não encontrado: %w", err)
+	}
+
+	subCtx, cancel := context.WithCancel(a.ctx)
+	sub := &logSubscription{
+		notify: make(chan struct{}, 1),
+		cancel: cancel,
+	}
+	// Entrega de imediato o que já foi gravado depois de afterID.
+	sub.notify <- struct{}{}
+
+	a.logSubsMu.Lock()
+	if previous := a.logSubs[id]; previous != nil {
+		previous.cancel()
+	}
+	a.logSubs[id] = sub
+	a.logSubsMu.Unlock()
+
+	go a.streamLogs(subCtx, id, afterID, sub)
+	return nil
+}
+
+func (a *App) UnsubscribeProjectLogs(id string) {
+	a.logSubsMu.Lock()
+	defer a.logSubsMu.Unlock()
+
+	if sub := a.logSubs[id]; sub != nil {
+		sub.cancel()
+		delete(a.logSubs, id)
+	}
+}
+
+// notifyLogSubscriber avisa a assinatura do projeto, se houver, que há linhas
+// novas no banco. Nunca bloqueia.
+func (a *App) notifyLogSubscriber(id string) {
+	a.logSubsMu.Lock()
+	sub := a.logSubs[id]
+	a.logSubsMu.Unlock()
+
+	if sub == nil {
+		return
+	}
+	select {
+	case sub.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (a *App) streamLogs(ctx context.Context, id string, cursor int64, sub *logSubscription) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.notify:
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(logStreamInterval):
+			}
+
+			batch, err := a.nextLogBatch(id, cursor)
+			if err != nil {
+				a.logger.Warn("Erro ao enviar logs ao frontend", map[string]interface{}{
+					"project": id,
+					"error":   err.Error(),
+				})
+				break
+			}
+			if len(batch.Entries) == 0 {
+				break
+			}
+
+			cursor = batch.Entries[len(batch.Entries)-1].ID
+			a.emitEvent(logStreamEvent(id), batch)
+
+			if len(batch.Entries) < logStreamBatch {
+				break
+			}
+		}
+	}
+}
+
+// nextLogBatch lê o próximo lote depois de cursor. Se o atraso passou de
+// logStreamMaxLag, devolve as linhas mais recentes e marca o lote como Skipped.
+func (a *App) nextLogBatch(id string, cursor int64) (*domain.LogBatch, error) {
+	entries, err := a.logRepo.GetSince(id, cursor, logStreamBatch)
+	if err != nil {
+		return nil, err
+	}
+	batch := &domain.LogBatch{ProjectID: id, Entries: entries}
+
+	if len(entries) < logStreamBatch {
+		return batch, nil
+	}
+
+	lag, err := a.logRepo.CountSince(id, cursor, logStreamMaxLag+1)
+	if err != nil || lag <= logStreamMaxLag {
+		return batch, nil
+	}
+
+	page, err := a.logRepo.GetByProjectID(id, domain.LogQuery{Limit: logStreamBatch})
+	if err != nil {
+		return nil, err
+	}
+	batch.Entries = page.Entries
+	batch.Skipped = true
+	return batch, nil
+}
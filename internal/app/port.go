@@ -5,53 +5,62 @@ import (
 	"os/exec"
 	"runtime"
 	"strconv"
-	"strings"
+
+	"github.com/Maycon-Santos/relief/pkg/portowner"
 )
 
+// PortConflict descreve uma porta ocupada. PID e Command são do primeiro dono;
+// Owners traz todos eles (vários processos podem escutar na mesma porta com
+// SO_REUSEPORT, ou um em IPv4 e outro em IPv6) com a árvore de cada um.
 type PortConflict struct {
-	Port    int    `json:"port"`
-	PID     int    `json:"pid"`
-	Command string `json:"command"`
+	Port    int               `json:"port"`
+	PID     int               `json:"pid"`
+	Command string            `json:"command"`
+	Owners  []portowner.Owner `json:"owners"`
 }
 
 func (a *App) CheckPortInUse(port int) (*PortConflict, error) {
-	var cmd *exec.Cmd
-
-	switch runtime.GOOS {
-	case "darwin", "linux":
-		cmd = exec.Command("lsof", "-ti:"+strconv.Itoa(port))
-	case "windows":
-		cmd = exec.Command("netstat", "-ano")
-	default:
-		return nil, fmt.Errorf("sistema operacional não suportado")
-	}
-
-	output, err := cmd.CombinedOutput()
+	owners, err := portowner.Lookup(port)
 	if err != nil {
-		return nil, nil
+		return nil, fmt.Errorf("erro ao verificar porta %d: %w", port, err)
 	}
-
-	pidStr := strings.TrimSpace(string(output))
-	if pidStr == "" {
+	if len(owners) == 0 {
 		return nil, nil
 	}
 
-	pid, err := strconv.Atoi(pidStr)
+	return &PortConflict{
+		Port:    port,
+		PID:     owners[0].PID,
+		Command: owners[0].Command,
+		Owners:  owners,
+	}, nil
+}
+
+// KillPortOwners encerra todos os processos escutando na porta. Donos sem PID
+// conhecido (de outro usuário, por exemplo) não podem ser encerrados daqui.
+func (a *App) KillPortOwners(port int) error {
+	conflict, err := a.CheckPortInUse(port)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao parsear PID: %w", err)
+		return err
 	}
-
-	command := ""
-	psCmd := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "command=")
-	if psOutput, err := psCmd.CombinedOutput(); err == nil {
-		command = strings.TrimSpace(string(psOutput))
+	if conflict == nil {
+		return nil
 	}
 
-	return &PortConflict{
-		Port:    port,
-		PID:     pid,
-		Command: command,
-	}, nil
+	unknown := false
+	for _, owner := range conflict.Owners {
+		if owner.PID <= 0 {
+			unknown = true
+			continue
+		}
+		if err := a.KillProcessByPID(owner.PID); err != nil {
+			return err
+		}
+	}
+	if unknown {
+		return fmt.Errorf("a porta %d está em uso por um processo que não pode ser identificado", port)
+	}
+	return nil
 }
 
 func (a *App) KillProcessByPID(pid int) error {
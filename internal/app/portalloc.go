@@ -0,0 +1,283 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Maycon-Santos/relief/internal/config"
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/internal/envvars"
+	"github.com/Maycon-Santos/relief/pkg/pathutil"
+)
+
+// declaredPorts junta as portas fixas do projeto: a port da configuração
+// global tem prioridade sobre o relief.yaml. Portas com valor 0 (e a "main",
+// quando ausente) ficam para o alocador.
+func (a *App) declaredPorts(project *domain.Project) map[string]int {
+	ports := map[string]int{}
+	if project.Manifest != nil {
+		ports = project.Manifest.DeclaredPorts()
+	}
+	if a.config != nil {
+		if pc := a.config.GetProjectByName(project.Name); pc != nil && pc.Port > 0 {
+			ports["main"] = pc.Port
+		}
+	}
+	return ports
+}
+
+// portReference acha PORT usada como variável: $PORT, ${PORT} ou %PORT%.
+var portReference = regexp.MustCompile(`\$\{?PORT\b|%PORT%`)
+
+// consumesPort diz se o projeto usa uma porta principal que não declarou:
+// scripts ou env que leem PORT, ou um readiness http com caminho relativo,
+// que bate na porta do projeto.
+func (a *App) consumesPort(project *domain.Project) bool {
+	values := []string{}
+	for _, script := range project.Scripts {
+		values = append(values, script)
+	}
+	if project.Manifest != nil {
+		for _, script := range project.Manifest.Scripts {
+			values = append(values, script)
+		}
+		for _, value := range project.Manifest.Env {
+			values = append(values, value)
+		}
+		if probe := project.Manifest.Readiness; probe != nil && strings.HasPrefix(probe.HTTP, "/") {
+			return true
+		}
+	}
+	if a.config != nil {
+		for _, value := range a.config.Env {
+			values = append(values, value)
+		}
+		if pc := a.config.GetProjectByName(project.Name); pc != nil {
+			for _, value := range pc.Env {
+				values = append(values, value)
+			}
+		}
+	}
+	projectPath := pathutil.FromRelativeHome(project.Path)
+	for _, file := range []envvars.Source{envvars.SourceDotEnv, envvars.SourceDotEnvLocal} {
+		if layer, err := envvars.FileLayer(file, filepath.Join(projectPath, string(file))); err == nil {
+			for _, value := range layer.Values {
+				values = append(values, value)
+			}
+		}
+	}
+
+	for _, value := range values {
+		if portReference.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignPorts resolve todas as portas do projeto antes de iniciá-lo. Portas
+// sem valor fixo recebem uma porta livre da faixa ports.range_start..range_end,
+// guardada no banco para que o projeto receba a mesma porta da próxima vez.
+// A "main" só é alocada sem ser declarada quando o projeto lê PORT. Uma
+// porta guardada que está ocupada continua do projeto: a colisão vai para os
+// logs, e a checagem de PORT_IN_USE do início barra a "main" ocupada.
+// Projetos docker publicam as portas do próprio manifesto e ficam de fora.
+func (a *App) assignPorts(project *domain.Project) error {
+	if project.Type == domain.ProjectTypeDocker || a.portRepo == nil {
+		return nil
+	}
+
+	ports := a.declaredPorts(project)
+	if _, ok := ports["main"]; !ok && a.consumesPort(project) {
+		ports["main"] = 0
+	}
+
+	allocated, err := a.portRepo.GetByProjectID(project.ID)
+	if err != nil {
+		return err
+	}
+
+	taken, err := a.takenPorts(project.ID)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(ports))
+	for name := range ports {
+		names = append(names, name)
+	}
+	// "main" primeiro, para ficar com a menor porta livre.
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == "main") != (names[j] == "main") {
+			return names[i] == "main"
+		}
+		return names[i] < names[j]
+	})
+
+	// Uma porta nova também não pode repetir outra porta do próprio projeto.
+	reserved := make(map[int]bool, len(taken)+len(allocated))
+	for port := range taken {
+		reserved[port] = true
+	}
+	for _, port := range allocated {
+		reserved[port] = true
+	}
+
+	for _, name := range names {
+		if ports[name] > 0 {
+			if _, ok := allocated[name]; ok {
+				_ = a.portRepo.Release(project.ID, name)
+			}
+			continue
+		}
+
+		if previous, ok := allocated[name]; ok {
+			switch {
+			case taken[previous]:
+				a.reportPortCollision(project, name, previous, "também é de outro projeto")
+			case !portAvailable(previous):
+				a.reportPortCollision(project, name, previous, "está em uso")
+			}
+			ports[name] = previous
+			taken[previous] = true
+			continue
+		}
+
+		port, err := a.findFreePort(reserved)
+		if err != nil {
+			return fmt.Errorf("erro ao alocar porta '%s': %w", name, err)
+		}
+		if err := a.portRepo.Save(project.ID, name, port); err != nil {
+			return err
+		}
+		taken[port] = true
+		reserved[port] = true
+		ports[name] = port
+
+		a.logger.Info("Porta alocada para o projeto", map[string]interface{}{
+			"project": project.Name,
+			"name":    name,
+			"port":    port,
+		})
+	}
+
+	project.Ports = ports
+	project.Port = ports["main"]
+	return nil
+}
+
+// reportPortCollision avisa que a porta guardada para o projeto está ocupada.
+// A reserva fica como está para a porta não mudar a cada colisão passageira.
+func (a *App) reportPortCollision(project *domain.Project, name string, port int, reason string) {
+	a.logger.Warn("Porta reservada do projeto ocupada", map[string]interface{}{
+		"project": project.Name,
+		"name":    name,
+		"port":    port,
+		"reason":  reason,
+	})
+	a.appendProjectLog(project.ID, "warn", fmt.Sprintf("Porta %d ('%s') reservada para o projeto %s", port, name, reason))
+}
+
+// takenPorts são as portas que pertencem a outros projetos, fixas ou alocadas.
+func (a *App) takenPorts(exceptID string) (map[int]bool, error) {
+	taken := map[int]bool{}
+
+	owners, err := a.portRepo.ListOwners()
+	if err != nil {
+		return nil, err
+	}
+	for port, owner := range owners {
+		if owner != exceptID {
+			taken[port] = true
+		}
+	}
+
+	projects, err := a.projectRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		if p.ID == exceptID {
+			continue
+		}
+		for _, port := range a.declaredPorts(p) {
+			if port > 0 {
+				taken[port] = true
+			}
+		}
+	}
+	return taken, nil
+}
+
+func (a *App) findFreePort(taken map[int]bool) (int, error) {
+	start, end := config.DefaultPortRangeStart, config.DefaultPortRangeEnd
+	if a.config != nil && a.config.Ports.RangeStart > 0 && a.config.Ports.RangeEnd >= a.config.Ports.RangeStart {
+		start, end = a.config.Ports.RangeStart, a.config.Ports.RangeEnd
+	}
+
+	for port := start; port <= end; port++ {
+		if !taken[port] && portAvailable(port) {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("nenhuma porta livre entre %d e %d", start, end)
+}
+
+// portAvailable testa o bind no loopback e em todas as interfaces: em alguns
+// sistemas um processo escutando só em 127.0.0.1 não impede o bind em ":porta".
+func portAvailable(port int) bool {
+	for _, host := range []string{"127.0.0.1", ""} {
+		listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return false
+		}
+		listener.Close()
+	}
+	return true
+}
+
+// checkPortCollisions avisa, logo ao carregar a configuração, sobre projetos
+// que declaram a mesma porta fixa. O aviso também vai para os logs de cada
+// projeto envolvido.
+func (a *App) checkPortCollisions() {
+	projects, err := a.projectRepo.List()
+	if err != nil {
+		return
+	}
+
+	claims := map[int][]*domain.Project{}
+	for _, p := range projects {
+		seen := map[int]bool{}
+		for _, port := range a.declaredPorts(p) {
+			if port > 0 && !seen[port] {
+				seen[port] = true
+				claims[port] = append(claims[port], p)
+			}
+		}
+	}
+
+	for port, owners := range claims {
+		if len(owners) < 2 {
+			continue
+		}
+
+		names := make([]string, len(owners))
+		for i, p := range owners {
+			names[i] = p.Name
+		}
+		sort.Strings(names)
+
+		a.logger.Warn("Projetos declaram a mesma porta", map[string]interface{}{
+			"port":     port,
+			"projects": strings.Join(names, ", "),
+		})
+		for _, p := range owners {
+			a.appendProjectLog(p.ID, "warn", fmt.Sprintf("Porta %d também declarada por: %s", port, strings.Join(names, ", ")))
+		}
+	}
+}
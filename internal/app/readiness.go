@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/internal/runner"
+)
+
+// awaitReadiness mantém o projeto em "starting" até o probe passar. As rotas
+// do proxy só são publicadas quando ele fica pronto; se o probe expirar, o
+// processo é encerrado e o projeto vai para "error", pronto para um novo
+// início.
+func (a *App) awaitReadiness(project *domain.Project, readiness *runner.Readiness) {
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.runnersMu.Lock()
+	a.readiness[project.ID] = cancel
+	a.runnersMu.Unlock()
+	defer a.cancelReadiness(project.ID)
+
+	err := readiness.Wait(ctx)
+	if ctx.Err() == context.Canceled {
+		return
+	}
+
+	current, getErr := a.projectRepo.GetByID(project.ID)
+	if getErr != nil || current.Status != domain.StatusStarting {
+		return
+	}
+	current.PID = project.PID
+	current.PGID = project.PGID
+	if project.Port > 0 {
+		current.Port = project.Port
+	}
+
+	if err != nil {
+		a.logger.Warn("Readiness probe falhou", map[string]interface{}{
+			"project": project.Name,
+			"error":   err.Error(),
+		})
+		a.appendProjectLog(project.ID, "error", err.Error())
+		runID := a.currentRun(project.ID)
+		a.failRun(project.ID, runID, domain.EventReadinessFailed, err.Error())
+		a.finishRun(project.ID, runID, domain.StatusError, domain.RunEndStartFailed, err.Error(), nil)
+		a.stopUnreadyRunner(project.ID)
+		current.SetError(err)
+		current.PID = 0
+		current.PGID = 0
+		_ = a.projectRepo.Update(current)
+		return
+	}
+
+	a.logger.Info("Projeto pronto", map[string]interface{}{
+		"project": project.Name,
+		"probe":   readiness.Kind(),
+	})
+	a.appendProjectLog(project.ID, "info", "Readiness probe passou, projeto pronto")
+	if err := a.markProjectReady(current); err != nil {
+		a.logger.Warn("Erro ao marcar projeto como pronto", map[string]interface{}{
+			"project": project.Name,
+			"error":   err.Error(),
+		})
+	}
+}
+
+func (a *App) cancelReadiness(id string) {
+	a.runnersMu.Lock()
+	cancel, ok := a.readiness[id]
+	delete(a.readiness, id)
+	a.runnersMu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+// stopUnreadyRunner encerra o processo (ou container) de um projeto que não
+// ficou pronto e tira o runner do registro, para StartProject poder subi-lo de
+// novo. O status callback é desligado antes, porque o erro do probe é quem
+// define o status e não deve haver restart.
+func (a *App) stopUnreadyRunner(id string) {
+	projectRunner, ok := a.getRunner(id)
+	if !ok {
+		return
+	}
+	if cbRunner, ok := projectRunner.(runner.CallbackRunner); ok {
+		cbRunner.SetStatusCallback(id, nil)
+	}
+	if err := projectRunner.Stop(a.ctx, id); err != nil {
+		a.logger.Warn("Erro ao encerrar projeto que não ficou pronto", map[string]interface{}{
+			"id":    id,
+			"error": err.Error(),
+		})
+	}
+	a.deleteRunner(id)
+}
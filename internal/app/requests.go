@@ -0,0 +1,245 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/internal/proxy"
+)
+
+const (
+	// requestQueueSize limita as requisições à espera de gravação; com a fila
+	// cheia, as novas são descartadas em vez de atrasar o proxy.
+	requestQueueSize     = 2048
+	requestBatchSize     = 200
+	requestFlushInterval = time.Second
+
+	replayTimeout = 30 * time.Second
+	// replayMaxBody é quanto da resposta de um replay é devolvido.
+	replayMaxBody = 64 * 1024
+)
+
+// replaySkipHeaders não são reenviados no replay: são da conexão original ou
+// acrescentados pelo proxy. Accept-Encoding fica de fora para a resposta
+// chegar descompactada.
+var replaySkipHeaders = map[string]bool{
+	"Connection":         true,
+	"Content-Length":     true,
+	"Host":               true,
+	"Keep-Alive":         true,
+	"Te":                 true,
+	"Trailer":            true,
+	"Transfer-Encoding":  true,
+	"Upgrade":            true,
+	"Accept-Encoding":    true,
+	"X-Forwarded-For":    true,
+	"X-Forwarded-Host":   true,
+	"X-Forwarded-Port":   true,
+	"X-Forwarded-Proto":  true,
+	"X-Forwarded-Server": true,
+	"X-Real-Ip":          true,
+}
+
+// startRequestRecorder liga o registro das requisições atendidas pelo proxy:
+// o provider entrega cada uma em recordRequest e elas são gravadas em lotes.
+// stopRequests grava o que ainda está na fila. A fila nunca é fechada, porque
+// conexões de WebSocket podem terminar depois do proxy parado.
+func (a *App) startRequestRecorder(provider proxy.Provider) {
+	queue := make(chan domain.ProxyRequest, requestQueueSize)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	a.requestQueue = queue
+	a.stopRequests = func() {
+		close(stop)
+		<-done
+	}
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(requestFlushInterval)
+		defer ticker.Stop()
+
+		batch := make([]domain.ProxyRequest, 0, requestBatchSize)
+		for {
+			select {
+			case req := <-queue:
+				batch = append(batch, req)
+				if len(batch) < requestBatchSize {
+					continue
+				}
+			case <-ticker.C:
+			case <-stop:
+				for len(queue) > 0 {
+					batch = append(batch, <-queue)
+				}
+				a.flushRequests(batch)
+				return
+			}
+			a.flushRequests(batch)
+			batch = batch[:0]
+		}
+	}()
+
+	provider.OnRequest(a.recordRequest)
+}
+
+func (a *App) recordRequest(record proxy.AccessRecord) {
+	req := domain.ProxyRequest{
+		ProjectID:    record.ProjectID,
+		Route:        record.Route,
+		Method:       record.Method,
+		Scheme:       record.Scheme,
+		Host:         record.Host,
+		Path:         record.Path,
+		Status:       record.Status,
+		DurationMs:   float64(record.Duration.Microseconds()) / 1000,
+		Upstream:     record.Upstream,
+		RequestSize:  record.RequestSize,
+		ResponseSize: record.ResponseSize,
+		Headers:      record.Headers,
+		CreatedAt:    record.Time.Format(time.RFC3339Nano),
+	}
+	select {
+	case a.requestQueue <- req:
+	default:
+	}
+}
+
+func (a *App) flushRequests(batch []domain.ProxyRequest) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := a.requestRepo.CreateBatch(batch); err != nil {
+		a.logger.Warn("Erro ao registrar requisições do proxy", map[string]interface{}{
+			"count": len(batch),
+			"error": err.Error(),
+		})
+		return
+	}
+
+	notified := map[string]bool{}
+	for _, req := range batch {
+		if !notified[req.ProjectID] {
+			notified[req.ProjectID] = true
+			a.emitEvent("requests:" + req.ProjectID)
+		}
+	}
+}
+
+// GetProjectRequests lista as requisições do projeto atendidas pelo proxy, da
+// mais recente para a mais antiga.
+func (a *App) GetProjectRequests(id string, query domain.RequestQuery) ([]domain.ProxyRequest, error) {
+	if _, err := a.projectRepo.GetByID(id); err != nil {
+		return nil, fmt.Errorf("projeto não encontrado: %w", err)
+	}
+	return a.requestRepo.List(id, query)
+}
+
+// ReplayRequest reenvia uma requisição registrada pelo proxy, com o mesmo
+// método, host, caminho e cabeçalhos, e devolve a resposta. O replay passa
+// pelo proxy e aparece como uma requisição nova. O corpo original não é
+// capturado, então não é reenviado, e os cabeçalhos de credenciais não são
+// guardados (ficam proxy.RedactedValue): o replay sai sem eles.
+func (a *App) ReplayRequest(id string, requestID int64) (*domain.ReplayResult, error) {
+	original, err := a.requestRepo.Get(id, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	port := a.config.Proxy.HTTPPort
+	if original.Scheme == "https" {
+		port = a.config.Proxy.HTTPSPort
+	}
+	target := original.Scheme + "://" + original.Host
+	if (original.Scheme == "https" && port != 443) || (original.Scheme != "https" && port != 80) {
+		target += ":" + strconv.Itoa(port)
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, replayTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, original.Method, target+original.Path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao montar a requisição: %w", err)
+	}
+	for name, value := range original.Headers {
+		if value == proxy.RedactedValue {
+			continue
+		}
+		if !replaySkipHeaders[http.CanonicalHeaderKey(name)] {
+			req.Header.Set(name, value)
+		}
+	}
+	req.Header.Set("X-Relief-Replay", strconv.FormatInt(original.ID, 10))
+
+	client := &http.Client{
+		Transport: a.replayTransport(original.Host, port),
+		// O replay mostra a resposta da própria requisição, inclusive
+		// redirecionamentos.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	started := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao reenviar a requisição: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, replayMaxBody+1))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler a resposta: %w", err)
+	}
+	result := &domain.ReplayResult{
+		Status:      resp.StatusCode,
+		DurationMs:  float64(time.Since(started).Microseconds()) / 1000,
+		Headers:     map[string]string{},
+		BodyOmitted: original.RequestSize > 0,
+	}
+	if len(body) > replayMaxBody {
+		body = body[:replayMaxBody]
+		result.Truncated = true
+	}
+	result.Body = string(body)
+	for name, values := range resp.Header {
+		result.Headers[name] = strings.Join(values, ", ")
+	}
+	return result, nil
+}
+
+// replayTransport conecta direto no proxy local, sem depender de o host
+// resolver para 127.0.0.1, e confia na CA local no HTTPS.
+func (a *App) replayTransport(host string, port int) *http.Transport {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		},
+		TLSClientConfig: &tls.Config{ServerName: host},
+	}
+	if a.certMgr != nil {
+		if data, err := os.ReadFile(a.certMgr.CAPath()); err == nil {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			pool.AppendCertsFromPEM(data)
+			transport.TLSClientConfig.RootCAs = pool
+		}
+	}
+	return transport
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+)
+
+// Um processo que ficou de pé por mais que isso zera a contagem de tentativas.
+const restartStableAfter = time.Minute
+
+type restartState struct {
+	attempts int
+	timer    *time.Timer
+}
+
+// scheduleRestart aplica a política de restart do projeto a um processo que
+// terminou. Retorna true quando tratou a saída (restart agendado ou crash
+// loop), e false quando o chamador deve seguir com o status normal.
+func (a *App) scheduleRestart(project *domain.Project, exit domain.Status, reason string, startedAt time.Time) bool {
+	if project.Manifest == nil || !project.Manifest.Restart.ShouldRestart(exit) {
+		a.clearRestart(project.ID)
+		return false
+	}
+	policy := project.Manifest.Restart
+
+	a.runnersMu.Lock()
+	if a.stopping[project.ID] {
+		a.runnersMu.Unlock()
+		return false
+	}
+	state, ok := a.restarts[project.ID]
+	if !ok {
+		state = &restartState{}
+		a.restarts[project.ID] = state
+	}
+	if time.Since(startedAt) >= restartStableAfter {
+		state.attempts = 0
+	}
+	if state.attempts >= policy.Retries() {
+		delete(a.restarts, project.ID)
+		a.runnersMu.Unlock()
+		a.markCrashLoop(project, state.attempts, reason)
+		return true
+	}
+	state.attempts++
+	attempt := state.attempts
+	delay := policy.Delay(attempt)
+	a.runnersMu.Unlock()
+
+	if reason == "" {
+		reason = "processo encerrado"
+	}
+	message := fmt.Sprintf("Reiniciando em %s (tentativa %d/%d): %s", delay, attempt, policy.Retries(), reason)
+	a.logger.Warn("Reiniciando projeto", map[string]interface{}{
+		"project": project.Name,
+		"attempt": attempt,
+		"delay":   delay.String(),
+		"reason":  reason,
+	})
+	a.appendProjectLog(project.ID, "warn", message)
+	a.recordEvent(project.ID, a.currentRun(project.ID), domain.EventRestart, message)
+
+	project.PID = 0
+	project.PGID = 0
+	project.LastError = reason
+	project.UpdateStatus(domain.StatusStarting)
+	_ = a.projectRepo.Update(project)
+
+	timer := time.AfterFunc(delay, func() {
+		a.runnersMu.RLock()
+		current, pending := a.restarts[project.ID]
+		a.runnersMu.RUnlock()
+		if !pending || current != state {
+			return
+		}
+
+		startedAt := time.Now()
+		if err := a.launchProject(project.ID); err != nil {
+			a.appendProjectLog(project.ID, "error", fmt.Sprintf("Falha ao reiniciar: %s", err.Error()))
+			p, getErr := a.projectRepo.GetByID(project.ID)
+			if getErr != nil {
+				return
+			}
+			if !a.scheduleRestart(p, domain.StatusError, err.Error(), startedAt) {
+				p.SetError(err)
+				_ = a.projectRepo.Update(p)
+			}
+		}
+	})
+
+	a.runnersMu.Lock()
+	state.timer = timer
+	a.runnersMu.Unlock()
+
+	return true
+}
+
+func (a *App) markCrashLoop(project *domain.Project, attempts int, reason string) {
+	message := fmt.Sprintf("Crash loop: processo falhou após %d reinícios seguidos", attempts)
+	if reason != "" {
+		message += "; último erro: " + reason
+	}
+
+	a.logger.Error("Projeto em crash loop", nil, map[string]interface{}{
+		"project":  project.Name,
+		"attempts": attempts,
+		"reason":   reason,
+	})
+	a.appendProjectLog(project.ID, "error", message)
+	a.recordEvent(project.ID, a.currentRun(project.ID), domain.EventCrashLoop, message)
+
+	project.PID = 0
+	project.PGID = 0
+	project.LastError = message
+	project.UpdateStatus(domain.StatusCrashLoop)
+	_ = a.projectRepo.Update(project)
+}
+
+// clearRestart cancela um restart pendente e zera as tentativas.
+func (a *App) clearRestart(id string) {
+	a.runnersMu.Lock()
+	state, ok := a.restarts[id]
+	delete(a.restarts, id)
+	a.runnersMu.Unlock()
+
+	if ok && state.timer != nil {
+		state.timer.Stop()
+	}
+}
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/internal/proxy"
+)
+
+// publishProject registra as rotas do projeto no proxy e os hosts exatos no
+// /etc/hosts. Curingas não cabem no /etc/hosts e dependem de DNS próprio.
+func (a *App) publishProject(project *domain.Project) {
+	a.registerRoutes(project, a.projectRoutes(project))
+}
+
+// parkProject aponta as rotas do projeto para a página de status, que mostra
+// o status e o último erro enquanto ele não está pronto. Sem a página (proxy
+// não iniciado por este processo), não faz nada.
+func (a *App) parkProject(project *domain.Project) {
+	if a.statusPages == nil {
+		return
+	}
+	a.registerRoutes(project, a.parkedRoutes(project))
+}
+
+// unpublishProject tira o projeto parado do ar: estaciona as rotas na página
+// de status ou, sem ela, remove-as do proxy.
+func (a *App) unpublishProject(project *domain.Project) {
+	if a.statusPages != nil {
+		a.parkProject(project)
+	} else if a.proxyMgr != nil {
+		a.proxyMgr.RemoveProject(project.ID)
+	}
+}
+
+// publishProjects registra as rotas de todos os projetos conhecidos: os que
+// estão rodando no próprio upstream, os demais na página de status.
+func (a *App) publishProjects() {
+	projects, err := a.projectRepo.List()
+	if err != nil {
+		return
+	}
+	for _, project := range projects {
+		if project.IsRunning() {
+			a.publishProject(project)
+		} else {
+			a.parkProject(project)
+		}
+	}
+}
+
+func (a *App) registerRoutes(project *domain.Project, routes []proxy.Route) {
+	if len(routes) == 0 {
+		return
+	}
+
+	if a.proxyMgr != nil {
+		if err := a.proxyMgr.AddProject(project, routes); err != nil {
+			a.logger.Warn("Erro ao publicar rotas do projeto", map[string]interface{}{
+				"project": project.Name,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	if a.hostsMgr != nil {
+		hosts := []string{}
+		for _, route := range routes {
+			hosts = append(hosts, route.Hosts...)
+		}
+		for _, host := range exactHosts(hosts) {
+			a.hostsMgr.AddEntry(host)
+		}
+	}
+}
+
+// projectHosts são o domínio e os aliases do projeto, em minúsculas.
+func projectHosts(project *domain.Project) []string {
+	hosts := []string{}
+	if project.Domain != "" {
+		hosts = append(hosts, strings.ToLower(project.Domain))
+	}
+	if project.Manifest != nil {
+		for _, alias := range project.Manifest.Aliases {
+			hosts = append(hosts, strings.ToLower(alias))
+		}
+	}
+	return hosts
+}
+
+// hostsEntries são todos os hosts exatos que o projeto pode ter posto no
+// /etc/hosts, publicado ou não.
+func hostsEntries(project *domain.Project) []string {
+	hosts := projectHosts(project)
+	if project.Manifest != nil {
+		for _, route := range project.Manifest.Routes {
+			for _, host := range route.Hosts {
+				hosts = append(hosts, strings.ToLower(host))
+			}
+		}
+	}
+	return exactHosts(hosts)
+}
+
+// projectRoutes monta as rotas do projeto: o domínio e os aliases na porta
+// principal, seguidos das routes do relief.yaml. Uma rota que não pode ser
+// resolvida é ignorada com um aviso, sem impedir as demais.
+func (a *App) projectRoutes(project *domain.Project) []proxy.Route {
+	return a.buildRoutes(project, project.Port, func(spec domain.ManifestRoute) (int, error) {
+		return a.routePort(project, spec)
+	})
+}
+
+// parkedRoutes são as mesmas rotas de projectRoutes levando à página de
+// status, com o ID do projeto no cabeçalho. O caminho segue inteiro para a
+// página poder consultar o status na própria URL.
+func (a *App) parkedRoutes(project *domain.Project) []proxy.Route {
+	port := a.statusPages.Port()
+	routes := a.buildRoutes(project, port, func(domain.ManifestRoute) (int, error) {
+		return port, nil
+	})
+	for i := range routes {
+		routes[i].StripPrefix = false
+		routes[i].RequestHeaders = map[string]string{proxy.StatusProjectHeader: project.ID}
+		routes[i].ResponseHeaders = nil
+	}
+	return routes
+}
+
+func (a *App) buildRoutes(project *domain.Project, mainPort int, routePort func(domain.ManifestRoute) (int, error)) []proxy.Route {
+	redirect := a.httpsRedirect(project)
+	hosts := projectHosts(project)
+
+	routes := []proxy.Route{}
+	if len(hosts) > 0 && mainPort > 0 {
+		routes = append(routes, proxy.Route{
+			Name:          project.Name,
+			Hosts:         hosts,
+			Port:          mainPort,
+			HTTPSRedirect: redirect,
+		})
+	}
+	if project.Manifest == nil {
+		return routes
+	}
+
+	for i, spec := range project.Manifest.Routes {
+		route := proxy.Route{
+			Name:            fmt.Sprintf("%s-route-%d", project.Name, i+1),
+			Hosts:           hosts,
+			PathPrefix:      spec.PathPrefix,
+			StripPrefix:     spec.StripPrefix,
+			Priority:        spec.Priority,
+			RequestHeaders:  spec.RequestHeaders,
+			ResponseHeaders: spec.ResponseHeaders,
+			HTTPSRedirect:   redirect,
+		}
+		if len(spec.Hosts) > 0 {
+			route.Hosts = make([]string, len(spec.Hosts))
+			for j, host := range spec.Hosts {
+				route.Hosts[j] = strings.ToLower(host)
+			}
+		}
+
+		port, err := routePort(spec)
+		if err == nil && len(route.Hosts) == 0 {
+			err = fmt.Errorf("sem hosts e o projeto não tem domínio")
+		}
+		if err != nil {
+			a.logger.Warn("Rota ignorada", map[string]interface{}{
+				"project": project.Name,
+				"route":   i + 1,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		route.Port = port
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// routePort resolve a porta de destino da rota: a principal do projeto (ou
+// do projeto em spec.Project), um número ou o nome de uma porta em ports.
+func (a *App) routePort(project *domain.Project, spec domain.ManifestRoute) (int, error) {
+	target := project
+	if spec.Project != "" && spec.Project != project.Name {
+		other, err := a.projectRepo.GetByName(spec.Project)
+		if err != nil || other == nil {
+			return 0, fmt.Errorf("projeto '%s' não encontrado", spec.Project)
+		}
+		target = other
+	}
+
+	ports := a.knownPorts(target)
+	if ports["main"] <= 0 && target.Port > 0 {
+		ports["main"] = target.Port
+	}
+
+	name := spec.Port
+	if name == "" {
+		name = "main"
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		return n, nil
+	}
+	if port := ports[name]; port > 0 {
+		return port, nil
+	}
+	return 0, fmt.Errorf("porta '%s' de '%s' não declarada ou ainda não alocada", name, target.Name)
+}
+
+// exactHosts tira de hosts os curingas e as repetições.
+func exactHosts(hosts []string) []string {
+	seen := map[string]bool{}
+	exact := []string{}
+	for _, host := range hosts {
+		if strings.HasPrefix(host, "*.") || seen[host] {
+			continue
+		}
+		seen[host] = true
+		exact = append(exact, host)
+	}
+	return exact
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/internal/runner"
+)
+
+// gitInfoTimeout limita a leitura de branch e commit ao abrir uma execução,
+// para um repositório lento não atrasar o start.
+const gitInfoTimeout = 2 * time.Second
+
+// openRun registra o início da execução runID: o que vai rodar e o branch e
+// commit do projeto naquele momento.
+func (a *App) openRun(project *domain.Project, runID string) {
+	if a.runRepo == nil {
+		return
+	}
+
+	run := &domain.Run{
+		ID:        runID,
+		ProjectID: project.ID,
+		Runner:    string(runner.TypeFor(project)),
+		Script:    runner.Describe(project),
+	}
+	gitInfo := project.GitInfo
+	if a.gitManager != nil {
+		ctx, cancel := context.WithTimeout(a.ctx, gitInfoTimeout)
+		if info, err := a.gitManager.GetGitInfo(ctx, project.Path); err == nil {
+			gitInfo = info
+		}
+		cancel()
+	}
+	if gitInfo != nil {
+		run.GitBranch = gitInfo.CurrentBranch
+		run.GitCommit = gitInfo.LastCommit
+	}
+
+	if err := a.runRepo.Create(run, time.Now()); err != nil {
+		a.logger.Warn("Erro ao registrar execução", map[string]interface{}{
+			"project": project.Name,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	message := "Iniciando"
+	if run.Script != "" {
+		// Scripts de várias linhas aparecem inteiros só na execução.
+		first, _, multiline := strings.Cut(run.Script, "\n")
+		message += ": " + first
+		if multiline {
+			message += " ..."
+		}
+	}
+	a.recordEvent(project.ID, runID, domain.EventStart, message)
+}
+
+// markRunReady registra quando a execução passou a responder.
+func (a *App) markRunReady(projectID, runID string) {
+	if a.runRepo == nil || runID == "" {
+		return
+	}
+	ready, err := a.runRepo.MarkReady(projectID, runID, time.Now())
+	if err != nil {
+		a.logger.Warn("Erro ao atualizar execução", map[string]interface{}{
+			"id":    projectID,
+			"error": err.Error(),
+		})
+		return
+	}
+	if ready {
+		a.recordEvent(projectID, runID, domain.EventReady, "Projeto pronto")
+	}
+}
+
+// failRun marca com erro a execução, que continua aberta.
+func (a *App) failRun(projectID, runID, eventType, message string) {
+	if a.runRepo == nil || runID == "" {
+		return
+	}
+	if err := a.runRepo.SetError(projectID, runID, message); err != nil {
+		a.logger.Warn("Erro ao atualizar execução", map[string]interface{}{
+			"id":    projectID,
+			"error": err.Error(),
+		})
+	}
+	a.recordEvent(projectID, runID, eventType, message)
+}
+
+// finishRun encerra a execução e registra no diário como ela terminou. Só a
+// primeira chamada para a execução vale: o fim informado pelo runner e o
+// StopProject podem chegar os dois.
+func (a *App) finishRun(projectID, runID string, status domain.Status, reason, message string, exit *runner.ExitInfo) {
+	if a.runRepo == nil || runID == "" {
+		return
+	}
+
+	run := &domain.Run{
+		ID:        runID,
+		ProjectID: projectID,
+		Status:    status,
+		EndReason: reason,
+		Error:     message,
+	}
+	if exit != nil {
+		run.Signal = exit.Signal
+		if exit.Signal == "" {
+			code := exit.Code
+			run.ExitCode = &code
+		}
+	}
+
+	finished, err := a.runRepo.Finish(run, time.Now())
+	if err != nil {
+		a.logger.Warn("Erro ao encerrar execução", map[string]interface{}{
+			"id":    projectID,
+			"error": err.Error(),
+		})
+		return
+	}
+	if !finished {
+		return
+	}
+
+	if reason == domain.RunEndStartFailed {
+		a.recordEvent(projectID, runID, domain.EventStartFailed, message)
+		return
+	}
+	a.recordEvent(projectID, runID, domain.EventExit, exitMessage(run))
+}
+
+func exitMessage(run *domain.Run) string {
+	var message string
+	switch {
+	case run.Signal != "":
+		message = "Encerrado pelo sinal " + run.Signal
+	case run.ExitCode != nil:
+		message = fmt.Sprintf("Encerrado com código %d", *run.ExitCode)
+	default:
+		message = "Encerrado"
+	}
+	if run.EndReason == domain.RunEndStopped {
+		message += " (parado pelo Relief)"
+	}
+	if run.Error != "" && run.Signal == "" && run.ExitCode == nil {
+		message += ": " + run.Error
+	}
+	return message
+}
+
+// interruptRuns fecha as execuções que ficaram abertas quando o Relief foi
+// encerrado sem parar o projeto.
+func (a *App) interruptRuns(project *domain.Project) {
+	if a.runRepo == nil {
+		return
+	}
+	ids, err := a.runRepo.Interrupt(project.ID)
+	if err != nil {
+		a.logger.Warn("Erro ao encerrar execuções abertas", map[string]interface{}{
+			"project": project.Name,
+			"error":   err.Error(),
+		})
+		return
+	}
+	for _, runID := range ids {
+		a.recordEvent(project.ID, runID, domain.EventInterrupted, "O Relief foi encerrado com o projeto em execução")
+	}
+}
+
+// openRunID é a execução ainda aberta do projeto, registrada por este ou por
+// outro processo do Relief (a janela ou a CLI).
+func (a *App) openRunID(id string) string {
+	if a.runRepo == nil {
+		return ""
+	}
+	runID, err := a.runRepo.LatestOpen(id)
+	if err != nil {
+		a.logger.Warn("Erro ao buscar execução aberta", map[string]interface{}{
+			"id":    id,
+			"error": err.Error(),
+		})
+	}
+	return runID
+}
+
+func (a *App) recordEvent(projectID, runID, eventType, message string) {
+	if a.runRepo == nil {
+		return
+	}
+	event := &domain.Event{
+		ProjectID: projectID,
+		RunID:     runID,
+		Type:      eventType,
+		Message:   a.secretMask.Mask(projectID, message),
+	}
+	if err := a.runRepo.AddEvent(event); err != nil {
+		a.logger.Warn("Erro ao registrar evento", map[string]interface{}{
+			"id":    projectID,
+			"type":  eventType,
+			"error": err.Error(),
+		})
+	}
+}
+
+// GetProjectHistory lista as execuções do projeto, da mais recente para a
+// mais antiga, com os eventos de cada uma.
+func (a *App) GetProjectHistory(id string, limit int) ([]domain.Run, error) {
+	if _, err := a.projectRepo.GetByID(id); err != nil {
+		return nil, fmt.Errorf("projeto não encontrado: %w", err)
+	}
+	return a.runRepo.History(id, limit)
+}
@@ -0,0 +1,231 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+)
+
+const (
+	stackReadyTimeout  = 2 * time.Minute
+	stackReadyInterval = 500 * time.Millisecond
+)
+
+// projectDependsOn junta os depends_on do relief.yaml e da configuração global.
+func (a *App) projectDependsOn(project *domain.Project) []string {
+	seen := map[string]bool{}
+	deps := []string{}
+	add := func(names []string) {
+		for _, name := range names {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				deps = append(deps, name)
+			}
+		}
+	}
+
+	if project.Manifest != nil {
+		add(project.Manifest.DependsOn)
+	}
+	if a.config != nil {
+		if pc := a.config.GetProjectByName(project.Name); pc != nil {
+			add(pc.DependsOn)
+		}
+	}
+	return deps
+}
+
+// stackOrder retorna o projeto e todos os projetos dos quais ele depende, em
+// ordem de inicialização (o próprio projeto por último).
+func (a *App) stackOrder(id string) ([]*domain.Project, error) {
+	target, err := a.projectRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("projeto não encontrado: %w", err)
+	}
+
+	projects, err := a.projectRepo.List()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar projetos: %w", err)
+	}
+
+	byName := make(map[string]*domain.Project, len(projects))
+	graph := make(map[string][]string, len(projects))
+	for _, p := range projects {
+		byName[p.Name] = p
+		graph[p.Name] = a.projectDependsOn(p)
+	}
+
+	var priority []string
+	if a.config != nil {
+		priority = a.config.Development.StartupOrder
+	}
+
+	names, err := domain.StartOrder(graph, []string{target.Name}, priority)
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]*domain.Project, 0, len(names))
+	for _, name := range names {
+		order = append(order, byName[name])
+	}
+	return order, nil
+}
+
+// checkProjectGraph avisa sobre ciclos e referências inválidas em depends_on
+// logo ao carregar os projetos, antes que alguém tente iniciá-los.
+func (a *App) checkProjectGraph() {
+	projects, err := a.projectRepo.List()
+	if err != nil {
+		return
+	}
+
+	graph := make(map[string][]string, len(projects))
+	roots := make([]string, 0, len(projects))
+	for _, p := range projects {
+		graph[p.Name] = a.projectDependsOn(p)
+		roots = append(roots, p.Name)
+	}
+
+	if _, err := domain.StartOrder(graph, roots, nil); err != nil {
+		a.logger.Warn("Dependências entre projetos inválidas", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// GetProjectStack retorna os nomes dos projetos que sobem junto com o projeto,
+// na ordem em que são iniciados.
+func (a *App) GetProjectStack(id string) ([]string, error) {
+	order, err := a.stackOrder(id)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(order))
+	for i, p := range order {
+		names[i] = p.Name
+	}
+	return names, nil
+}
+
+// startUpstreamProjects inicia, em ordem topológica, os projetos declarados em
+// depends_on que ainda não estão rodando, aguardando cada um ficar pronto.
+func (a *App) startUpstreamProjects(id string) error {
+	order, err := a.stackOrder(id)
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range order[:len(order)-1] {
+		if !dep.IsActive() {
+			a.logger.Info("Iniciando projeto dependente", map[string]interface{}{
+				"project":    dep.Name,
+				"depends_on": order[len(order)-1].Name,
+			})
+			if err := a.launchProject(dep.ID); err != nil {
+				return fmt.Errorf("erro ao iniciar dependência '%s': %w", dep.Name, err)
+			}
+		}
+
+		if err := a.waitProjectReady(dep.ID); err != nil {
+			return fmt.Errorf("dependência '%s' não ficou pronta: %w", dep.Name, err)
+		}
+	}
+	return nil
+}
+
+// waitProjectReady aguarda o projeto estar rodando. Sem readiness probe, um
+// projeto com porta só conta como pronto quando ela aceita conexões.
+func (a *App) waitProjectReady(id string) error {
+	deadline := time.Now().Add(stackReadyTimeout)
+
+	for {
+		project, err := a.projectRepo.GetByID(id)
+		if err != nil {
+			return err
+		}
+
+		switch project.Status {
+		case domain.StatusError:
+			return fmt.Errorf("%s", project.LastError)
+		case domain.StatusStopped:
+			return fmt.Errorf("processo encerrado antes de ficar pronto")
+		case domain.StatusRunning:
+			hasProbe := project.Manifest != nil && project.Manifest.Readiness != nil
+			if hasProbe || project.Port == 0 || portAcceptsConnections(project.Port) {
+				return nil
+			}
+		}
+
+		// Com probe, quem decide o timeout é o próprio probe.
+		probing := project.Status == domain.StatusStarting && project.Manifest != nil && project.Manifest.Readiness != nil
+		if !probing && time.Now().After(deadline) {
+			return fmt.Errorf("tempo esgotado após %s", stackReadyTimeout)
+		}
+
+		select {
+		case <-a.ctx.Done():
+			return a.ctx.Err()
+		case <-time.After(stackReadyInterval):
+		}
+	}
+}
+
+func portAcceptsConnections(port int) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// StopStack para o projeto e, em ordem inversa à de inicialização, os
+// projetos dos quais ele depende que não são usados por outro projeto rodando.
+func (a *App) StopStack(id string) error {
+	order, err := a.stackOrder(id)
+	if err != nil {
+		return err
+	}
+
+	inStack := make(map[string]bool, len(order))
+	for _, p := range order {
+		inStack[p.Name] = true
+	}
+
+	neededOutside := map[string]bool{}
+	projects, _ := a.projectRepo.List()
+	for _, p := range projects {
+		if inStack[p.Name] || !p.IsActive() {
+			continue
+		}
+		for _, dep := range a.projectDependsOn(p) {
+			neededOutside[dep] = true
+		}
+	}
+
+	var firstErr error
+	for i := len(order) - 1; i >= 0; i-- {
+		p := order[i]
+		if i < len(order)-1 && neededOutside[p.Name] {
+			a.logger.Info("Mantendo projeto usado por outro projeto em execução", map[string]interface{}{
+				"project": p.Name,
+			})
+			for _, dep := range a.projectDependsOn(p) {
+				neededOutside[dep] = true
+			}
+			continue
+		}
+		if !p.IsActive() && p.PID == 0 {
+			continue
+		}
+		if err := a.StopProject(p.ID); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("erro ao parar '%s': %w", p.Name, err)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/internal/proxy"
+)
+
+// startStatusPages sobe a página de status para onde vão as rotas dos
+// projetos que não estão prontos. Sem ela, os domínios desses projetos saem
+// do proxy como antes.
+func (a *App) startStatusPages() {
+	pages := proxy.NewStatusPages(statusController{app: a}, a.logger)
+	if err := pages.Start(); err != nil {
+		a.logger.Warn("Erro ao iniciar a página de status", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	a.statusPages = pages
+}
+
+// statusController liga a página de status ao App sem expor seus métodos nos
+// bindings do Wails.
+type statusController struct {
+	app *App
+}
+
+func (c statusController) ProjectState(id string) (proxy.ProjectState, error) {
+	project, err := c.app.projectRepo.GetByID(id)
+	if err != nil {
+		return proxy.ProjectState{}, err
+	}
+	state := proxy.ProjectState{
+		Name:   project.Name,
+		Status: string(project.Status),
+	}
+	if project.Status != domain.StatusRunning {
+		state.Error = project.LastError
+	}
+	return state, nil
+}
+
+// StartProject ignora o pedido quando o projeto já está subindo ou rodando,
+// como num segundo clique em outra aba. A página não espera o início, então
+// um erro que não chegou ao projeto (de um projeto upstream, por exemplo)
+// vira o último erro dele, para a página mostrá-lo.
+func (c statusController) StartProject(id string) error {
+	project, err := c.app.projectRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if project.IsActive() {
+		return nil
+	}
+
+	startErr := c.app.StartProject(id)
+	if startErr == nil {
+		return nil
+	}
+	if current, err := c.app.projectRepo.GetByID(id); err == nil && !current.IsActive() && current.LastError == project.LastError {
+		current.SetError(startErr)
+		_ = c.app.projectRepo.Update(current)
+	}
+	return startErr
+}
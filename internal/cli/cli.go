@@ -0,0 +1,1090 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/app"
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/internal/proxy"
+	"github.com/Maycon-Santos/relief/internal/storage"
+	"github.com/Maycon-Santos/relief/pkg/instancelock"
+	"github.com/Maycon-Santos/relief/pkg/logger"
+)
+
+const followInterval = 500 * time.Millisecond
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(c *cli, args []string) error
+}
+
+var commands = []command{
+	{"up", "up [--all] [--json] <project>...", "inicia projetos e acompanha os logs até Ctrl+C", runUp},
+	{"down", "down [--all] [--stack] [--json] <project>...", "para projetos em execução", runDown},
+	{"ps", "ps [--json]", "lista os projetos e seus status", runPs},
+	{"logs", "logs [-n N] [-f] [--run ID] [-q TEXT] [--runs] [--json] <project>", "mostra os logs de um projeto", runLogs},
+	{"history", "history [-n N] [--events] [--json] <project>", "histórico de execuções do projeto: branch, commit, tempo até ficar pronto, duração e como terminou", runHistory},
+	{"requests", "requests [-n N] [--status S] [-q TEXT] [--replay ID] [--json] <project>", "requisições atendidas pelo proxy: método, caminho, status, latência e upstream; --replay reenvia uma delas", runRequests},
+	{"status", "status [--json]", "resumo do orquestrador", runStatus},
+	{"env", "env [--all] [--json] [--sync [--write]] <project>", "mostra o ambiente resolvido do projeto e a origem de cada variável; --sync mostra o que mudaria no .env", runEnv},
+	{"run", "run <script>", "executa um script global da configuração", runScript},
+	{"certs", "certs [--trust | --untrust] [--json]", "mostra a CA local usada no HTTPS dos domínios; --trust a instala no repositório de confiança do sistema", runCerts},
+	{"migrate", "migrate [--status | --down N] [--json]", "aplica as migrations pendentes do banco; --down desfaz as últimas N (desenvolvimento)", runMigrate},
+}
+
+type cli struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	usage   string
+	verbose bool
+	json    bool
+}
+
+// IsCommand indica se o argumento corresponde a um subcomando da CLI, para que
+// o binário decida entre abrir a janela ou rodar em modo headless.
+func IsCommand(name string) bool {
+	if name == "help" || name == "-h" || name == "--help" {
+		return true
+	}
+	_, ok := findCommand(name)
+	return ok
+}
+
+// Run executa a CLI e retorna o código de saída do processo.
+func Run(args []string) int {
+	c := &cli{stdout: os.Stdout, stderr: os.Stderr}
+
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		c.printUsage()
+		return 0
+	}
+
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		fmt.Fprintf(c.stderr, "comando desconhecido: %s\n\n", args[0])
+		c.printUsage()
+		return 2
+	}
+
+	c.usage = cmd.usage
+	if err := cmd.run(c, args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return 2
+		}
+		fmt.Fprintf(c.stderr, "erro: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func (c *cli) printUsage() {
+	fmt.Fprintln(c.stderr, "Uso: relief <comando> [opções]")
+	fmt.Fprintln(c.stderr)
+	w := tabwriter.NewWriter(c.stderr, 0, 0, 3, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", cmd.usage, cmd.summary)
+	}
+	w.Flush()
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "Sem comando, o Relief abre a interface gráfica.")
+}
+
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.BoolVar(&c.json, "json", false, "saída em JSON")
+	fs.BoolVar(&c.verbose, "v", false, "mostra os logs internos do Relief")
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Uso: relief %s\n", c.usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs aceita flags antes ou depois dos argumentos posicionais.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// open inicializa o núcleo do App sem runtime Wails.
+func (c *cli) open(ctx context.Context, opts app.Options) (*app.App, error) {
+	opts.Headless = true
+	opts.Logger = c.logger()
+
+	a := app.NewApp()
+	if err := a.Init(ctx, opts); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// openBackend usa a API da interface em execução ou, sem ela, abre o núcleo
+// só para leitura. release libera o que foi aberto.
+func (c *cli) openBackend(ctx context.Context) (b backend, release func(), err error) {
+	if remote := c.connect(); remote != nil {
+		return remote, func() {}, nil
+	}
+	a, err := c.open(ctx, app.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, func() { a.Shutdown(context.Background()) }, nil
+}
+
+func (c *cli) logger() *logger.Logger {
+	level := "warn"
+	if c.verbose {
+		level = "info"
+	}
+	return logger.New(level, c.stderr)
+}
+
+func (c *cli) writeJSON(v interface{}) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func resolveProjects(a backend, names []string, all bool) ([]*domain.Project, error) {
+	projects, err := a.GetProjects()
+	if err != nil {
+		return nil, err
+	}
+	if all {
+		return projects, nil
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("informe ao menos um projeto ou use --all")
+	}
+
+	selected := make([]*domain.Project, 0, len(names))
+	for _, name := range names {
+		project := findProject(projects, name)
+		if project == nil {
+			return nil, fmt.Errorf("projeto '%s' não encontrado", name)
+		}
+		selected = append(selected, project)
+	}
+	return selected, nil
+}
+
+func findProject(projects []*domain.Project, name string) *domain.Project {
+	for _, p := range projects {
+		if p.ID == name || p.Name == name {
+			return p
+		}
+	}
+	for _, p := range projects {
+		if strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+	return nil
+}
+
+type actionResult struct {
+	Project string `json:"project"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// runUp inicia os projetos pela interface quando ela está aberta. Sem ela, a
+// CLI abre o núcleo e toma o lock da instância; se outra CLI já o tem, os
+// projetos sobem sem o proxy, que fica com ela.
+func runUp(c *cli, args []string) error {
+	fs := c.flagSet("up")
+	all := fs.Bool("all", false, "inicia todos os projetos")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var b backend
+	remote := c.connect()
+	if remote != nil {
+		b = remote
+	} else {
+		opts := app.DefaultOptions()
+		opts.InstanceLock = false
+		opts.CleanupOrphans = false
+		opts.WatchGit = false
+		opts.ServeAPI = false
+
+		lock, err := lockInstance()
+		var held *instancelock.HeldError
+		switch {
+		case errors.As(err, &held):
+			opts.StartProxy = false
+			fmt.Fprintf(c.stderr, "aviso: %v; o proxy fica com ela\n", held)
+		case err != nil:
+			return err
+		default:
+			defer lock.Release()
+		}
+
+		a, err := c.open(ctx, opts)
+		if err != nil {
+			return err
+		}
+		defer a.Shutdown(context.Background())
+		b = a
+	}
+
+	projects, err := resolveProjects(b, names, *all)
+	if err != nil {
+		return err
+	}
+
+	results := []actionResult{}
+	started := []*domain.Project{}
+	for _, project := range projects {
+		if project.IsActive() {
+			results = append(results, actionResult{Project: project.Name, Status: "already running"})
+			continue
+		}
+		if err := b.StartProject(project.ID); err != nil {
+			results = append(results, actionResult{Project: project.Name, Status: "error", Error: err.Error()})
+			continue
+		}
+		results = append(results, actionResult{Project: project.Name, Status: "started"})
+		started = append(started, project)
+	}
+
+	for _, r := range results {
+		switch {
+		case c.json:
+			data, _ := json.Marshal(r)
+			fmt.Fprintln(c.stdout, string(data))
+		case r.Error != "":
+			fmt.Fprintf(c.stderr, "✗ %s: %s\n", r.Project, r.Error)
+		default:
+			fmt.Fprintf(c.stderr, "✓ %s %s\n", r.Project, r.Status)
+		}
+	}
+
+	if len(started) == 0 {
+		return fmt.Errorf("nenhum projeto iniciado")
+	}
+
+	if !c.json {
+		fmt.Fprintln(c.stderr, "Acompanhando logs (Ctrl+C para parar)...")
+	}
+	c.follow(ctx, b, started, true)
+
+	if !c.json {
+		fmt.Fprintln(c.stderr, "Parando projetos...")
+	}
+	// Aberto pela CLI, o núcleo para os próprios projetos no Shutdown; pela
+	// interface, o Ctrl+C pede a ela que pare os que este up iniciou.
+	if remote != nil && ctx.Err() != nil {
+		for _, project := range started {
+			if err := remote.StopProject(project.ID); err != nil {
+				fmt.Fprintf(c.stderr, "✗ %s: %v\n", project.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// runDown para os projetos pela interface quando ela está aberta, para que
+// ela não tome a parada por uma queda e reinicie o projeto. Sem ela, só para
+// com o lock da instância: se outra CLI o tem, os processos são dela.
+func runDown(c *cli, args []string) error {
+	fs := c.flagSet("down")
+	all := fs.Bool("all", false, "para todos os projetos em execução")
+	stack := fs.Bool("stack", false, "para também os projetos de depends_on, em ordem inversa")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var b backend
+	if remote := c.connect(); remote != nil {
+		b = remote
+	} else {
+		lock, err := lockInstance()
+		var held *instancelock.HeldError
+		if errors.As(err, &held) {
+			return fmt.Errorf("%v; pare os projetos por ela (na interface ou com Ctrl+C no relief up)", held)
+		} else if err != nil {
+			return err
+		}
+		defer lock.Release()
+
+		a, err := c.open(context.Background(), app.Options{})
+		if err != nil {
+			return err
+		}
+		defer a.Shutdown(context.Background())
+		b = a
+	}
+
+	projects, err := resolveProjects(b, names, *all)
+	if err != nil {
+		return err
+	}
+
+	results := []actionResult{}
+	failed := false
+	for _, project := range projects {
+		if *stack {
+			if err := b.StopStack(project.ID); err != nil {
+				failed = true
+				results = append(results, actionResult{Project: project.Name, Status: "error", Error: err.Error()})
+				continue
+			}
+			results = append(results, actionResult{Project: project.Name, Status: "stack stopped"})
+			continue
+		}
+		if !project.IsActive() && project.PID == 0 {
+			if !*all {
+				results = append(results, actionResult{Project: project.Name, Status: "not running"})
+			}
+			continue
+		}
+		if err := b.StopProject(project.ID); err != nil {
+			failed = true
+			results = append(results, actionResult{Project: project.Name, Status: "error", Error: err.Error()})
+			continue
+		}
+		results = append(results, actionResult{Project: project.Name, Status: "stopped"})
+	}
+
+	if c.json {
+		return c.writeJSON(results)
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(c.stdout, "✗ %s: %s\n", r.Project, r.Error)
+		} else {
+			fmt.Fprintf(c.stdout, "✓ %s %s\n", r.Project, r.Status)
+		}
+	}
+	if failed {
+		return fmt.Errorf("falha ao parar um ou mais projetos")
+	}
+	return nil
+}
+
+type projectRow struct {
+	ID     string        `json:"id"`
+	Name   string        `json:"name"`
+	Type   string        `json:"type"`
+	Status domain.Status `json:"status"`
+	Port   int           `json:"port,omitempty"`
+	PID    int           `json:"pid,omitempty"`
+	Domain string        `json:"domain,omitempty"`
+	Branch string        `json:"branch,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+func runPs(c *cli, args []string) error {
+	fs := c.flagSet("ps")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	b, closeBackend, err := c.openBackend(context.Background())
+	if err != nil {
+		return err
+	}
+	defer closeBackend()
+
+	projects, err := b.GetProjects()
+	if err != nil {
+		return err
+	}
+
+	rows := make([]projectRow, 0, len(projects))
+	for _, p := range projects {
+		row := projectRow{
+			ID:     p.ID,
+			Name:   p.Name,
+			Type:   string(p.Type),
+			Status: p.Status,
+			Port:   p.Port,
+			PID:    p.PID,
+			Domain: p.Domain,
+			Error:  p.LastError,
+		}
+		if p.GitInfo != nil {
+			row.Branch = p.GitInfo.CurrentBranch
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+
+	if c.json {
+		return c.writeJSON(rows)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tSTATUS\tPORT\tPID\tDOMAIN\tBRANCH")
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Name, r.Type, r.Status, orDash(r.Port), orDash(r.PID), dashIfEmpty(r.Domain), dashIfEmpty(r.Branch))
+	}
+	return w.Flush()
+}
+
+func runLogs(c *cli, args []string) error {
+	fs := c.flagSet("logs")
+	tail := fs.Int("n", 100, "quantidade de linhas")
+	follow := fs.Bool("f", false, "continua acompanhando novas linhas")
+	runID := fs.String("run", "", "mostra apenas a execução informada (veja --runs)")
+	search := fs.String("q", "", "busca textual no histórico")
+	listRuns := fs.Bool("runs", false, "lista as execuções registradas")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	b, closeBackend, err := c.openBackend(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeBackend()
+
+	projects, err := resolveProjects(b, names, false)
+	if err != nil {
+		return err
+	}
+	project := projects[0]
+
+	if *listRuns {
+		return c.printRuns(b, project)
+	}
+
+	page, err := b.QueryProjectLogs(project.ID, domain.LogQuery{
+		RunID:  *runID,
+		Search: *search,
+		Limit:  *tail,
+	})
+	if err != nil {
+		return err
+	}
+	logs := page.Entries
+
+	for _, entry := range logs {
+		c.printLog(entry, "")
+	}
+
+	if *follow {
+		var lastID int64
+		if len(logs) > 0 {
+			lastID = logs[len(logs)-1].ID
+		}
+		c.followFrom(ctx, b, []*domain.Project{project}, map[string]int64{project.ID: lastID}, false)
+	}
+	return nil
+}
+
+func (c *cli) printRuns(a backend, project *domain.Project) error {
+	runs, err := a.GetProjectRuns(project.ID)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.writeJSON(runs)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN\tSTARTED\tENDED\tLINES\tERRORS")
+	for _, run := range runs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", run.RunID, run.StartedAt, run.EndedAt, run.Lines, run.Errors)
+	}
+	return w.Flush()
+}
+
+func runHistory(c *cli, args []string) error {
+	fs := c.flagSet("history")
+	limit := fs.Int("n", 20, "quantidade de execuções")
+	events := fs.Bool("events", false, "mostra os eventos de cada execução")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	a, err := c.open(context.Background(), app.Options{})
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown(context.Background())
+
+	projects, err := resolveProjects(a, names, false)
+	if err != nil {
+		return err
+	}
+	runs, err := a.GetProjectHistory(projects[0].ID, *limit)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.writeJSON(runs)
+	}
+
+	// Com --events, cada linha da tabela vem seguida dos eventos da execução,
+	// fora do alinhamento das colunas.
+	var table bytes.Buffer
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN\tSTARTED\tBRANCH\tCOMMIT\tREADY\tDURATION\tRESULT")
+	for _, run := range runs {
+		commit, _, _ := strings.Cut(run.GitCommit, " ")
+		ready := "-"
+		if run.ReadyAt != "" {
+			ready = formatMillis(run.ReadyMs)
+		}
+		duration := "-"
+		if run.EndedAt != "" {
+			duration = formatMillis(run.DurationMs)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			run.ID, formatTime(run.StartedAt), dashIfEmpty(run.GitBranch), dashIfEmpty(commit), ready, duration, runResult(run))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	fmt.Fprintln(c.stdout, lines[0])
+	for i, run := range runs {
+		fmt.Fprintln(c.stdout, lines[i+1])
+		if !*events {
+			continue
+		}
+		for _, e := range run.Events {
+			fmt.Fprintf(c.stdout, "    %s  %-16s %s\n", formatTime(e.CreatedAt), e.Type, e.Message)
+		}
+	}
+	return nil
+}
+
+// runResult resume como a execução terminou: código de saída, sinal ou o
+// motivo, ou o status enquanto ela está aberta.
+func runResult(run domain.Run) string {
+	var result string
+	switch {
+	case run.EndedAt == "":
+		return string(run.Status)
+	case run.EndReason == domain.RunEndStartFailed || run.EndReason == domain.RunEndInterrupted:
+		return run.EndReason
+	case run.Signal != "":
+		result = run.Signal
+	case run.ExitCode != nil:
+		result = fmt.Sprintf("exit %d", *run.ExitCode)
+	default:
+		result = string(run.Status)
+	}
+	if run.EndReason == domain.RunEndStopped {
+		result += " (stop)"
+	}
+	return result
+}
+
+func runRequests(c *cli, args []string) error {
+	fs := c.flagSet("requests")
+	limit := fs.Int("n", 50, "quantidade de requisições")
+	status := fs.String("status", "", "filtra por status: código, 2xx..5xx ou errors")
+	search := fs.String("q", "", "filtra pelo caminho")
+	replay := fs.Int64("replay", 0, "reenvia a requisição com este ID e mostra a resposta")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	a, err := c.open(context.Background(), app.Options{})
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown(context.Background())
+
+	projects, err := resolveProjects(a, names, false)
+	if err != nil {
+		return err
+	}
+
+	if *replay > 0 {
+		result, err := a.ReplayRequest(projects[0].ID, *replay)
+		if err != nil {
+			return err
+		}
+		if c.json {
+			return c.writeJSON(result)
+		}
+		fmt.Fprintf(c.stdout, "%d %s (%s)\n", result.Status, http.StatusText(result.Status), formatLatency(result.DurationMs))
+		if result.BodyOmitted {
+			fmt.Fprintln(c.stderr, "aviso: a requisição original tinha corpo, que não é capturado e não foi reenviado")
+		}
+		headers := make([]string, 0, len(result.Headers))
+		for name := range result.Headers {
+			headers = append(headers, name)
+		}
+		sort.Strings(headers)
+		for _, name := range headers {
+			fmt.Fprintf(c.stdout, "%s: %s\n", name, result.Headers[name])
+		}
+		fmt.Fprintln(c.stdout)
+		fmt.Fprint(c.stdout, result.Body)
+		if result.Truncated {
+			fmt.Fprintln(c.stderr, "\n(resposta truncada)")
+		}
+		return nil
+	}
+
+	requests, err := a.GetProjectRequests(projects[0].ID, domain.RequestQuery{
+		Status: *status,
+		Search: *search,
+		Limit:  *limit,
+	})
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.writeJSON(requests)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tMETHOD\tHOST\tPATH\tSTATUS\tLATENCY\tUPSTREAM")
+	for _, req := range requests {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			req.ID, formatTime(req.CreatedAt), req.Method, req.Host, req.Path, req.Status,
+			formatLatency(req.DurationMs), dashIfEmpty(req.Upstream))
+	}
+	return w.Flush()
+}
+
+func formatLatency(ms float64) string {
+	d := time.Duration(ms * float64(time.Millisecond))
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(100 * time.Microsecond).String()
+}
+
+func formatMillis(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	if d < 10*time.Second {
+		return d.Round(10 * time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+func formatTime(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func runStatus(c *cli, args []string) error {
+	fs := c.flagSet("status")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	a, err := c.open(context.Background(), app.Options{})
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown(context.Background())
+
+	status, err := a.GetStatus()
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return c.writeJSON(status)
+	}
+
+	keys := make([]string, 0, len(status))
+	for k := range status {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s\t%v\n", k, status[k])
+	}
+	return w.Flush()
+}
+
+func runEnv(c *cli, args []string) error {
+	fs := c.flagSet("env")
+	all := fs.Bool("all", false, "inclui as variáveis herdadas do sistema")
+	sync := fs.Bool("sync", false, "mostra o diff do .env com o env do projeto no config.yaml")
+	write := fs.Bool("write", false, "com --sync, grava o .env")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 || (*write && !*sync) {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	a, err := c.open(context.Background(), app.Options{})
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown(context.Background())
+
+	projects, err := resolveProjects(a, names, false)
+	if err != nil {
+		return err
+	}
+
+	if *sync {
+		return syncEnvFile(c, a, projects[0].ID, *write)
+	}
+
+	vars, err := a.GetProjectEnv(projects[0].ID, *all)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return c.writeJSON(vars)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVALUE\tSOURCE\tOVERRIDES")
+	for _, v := range vars {
+		overrides := make([]string, len(v.Overrides))
+		for i, source := range v.Overrides {
+			overrides[i] = string(source)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Name, v.Value, v.Source, dashIfEmpty(strings.Join(overrides, ", ")))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, v := range vars {
+		if len(v.Missing) > 0 {
+			fmt.Fprintf(c.stderr, "aviso: %s referencia variáveis não definidas: %s\n", v.Name, strings.Join(v.Missing, ", "))
+		}
+	}
+	return nil
+}
+
+// syncEnvFile mostra o diff do .env do projeto e, com write, grava o arquivo.
+func syncEnvFile(c *cli, a *app.App, id string, write bool) error {
+	preview, err := a.PreviewProjectEnv(id)
+	if err != nil {
+		return err
+	}
+
+	if write && len(preview.Changed) > 0 {
+		if err := a.SetupProjectEnv(id); err != nil {
+			return err
+		}
+	}
+	if c.json {
+		return c.writeJSON(preview)
+	}
+
+	switch {
+	case len(preview.Changed) == 0:
+		fmt.Fprintf(c.stdout, "%s já está atualizado\n", preview.Path)
+	case write:
+		fmt.Fprint(c.stdout, preview.Diff)
+		fmt.Fprintf(c.stdout, "%s atualizado: %s\n", preview.Path, strings.Join(preview.Changed, ", "))
+	default:
+		fmt.Fprint(c.stdout, preview.Diff)
+		fmt.Fprintf(c.stderr, "use --write para gravar %s\n", preview.Path)
+	}
+	return nil
+}
+
+func runScript(c *cli, args []string) error {
+	fs := c.flagSet("run")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a, err := c.open(ctx, app.Options{})
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown(context.Background())
+
+	output, err := a.RunGlobalScript(names[0])
+	if c.json {
+		result := map[string]interface{}{"script": names[0], "output": output}
+		if err != nil {
+			result["error"] = err.Error()
+		}
+		if encErr := c.writeJSON(result); encErr != nil {
+			return encErr
+		}
+		return err
+	}
+
+	fmt.Fprint(c.stdout, output)
+	return err
+}
+
+// follow acompanha os logs persistidos a partir de agora. Com untilStopped,
+// retorna quando todos os projetos deixam de estar em execução.
+func (c *cli) follow(ctx context.Context, a backend, projects []*domain.Project, untilStopped bool) {
+	c.followFrom(ctx, a, projects, map[string]int64{}, untilStopped)
+}
+
+func (c *cli) followFrom(ctx context.Context, a backend, projects []*domain.Project, lastIDs map[string]int64, untilStopped bool) {
+	prefix := len(projects) > 1
+	width := 0
+	for _, p := range projects {
+		if len(p.Name) > width {
+			width = len(p.Name)
+		}
+	}
+
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+
+	for {
+		active := 0
+		for _, p := range projects {
+			logs, err := a.GetProjectLogsSince(p.ID, lastIDs[p.ID], 500)
+			if err == nil {
+				label := ""
+				if prefix {
+					label = fmt.Sprintf("%-*s | ", width, p.Name)
+				}
+				for _, entry := range logs {
+					c.printLog(entry, label)
+					lastIDs[p.ID] = entry.ID
+				}
+			}
+
+			if current, err := a.GetProject(p.ID); err == nil && current.IsActive() {
+				active++
+			}
+		}
+
+		if untilStopped && active == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *cli) printLog(entry domain.LogEntry, label string) {
+	if c.json {
+		data, _ := json.Marshal(entry)
+		fmt.Fprintln(c.stdout, string(data))
+		return
+	}
+
+	out := c.stdout
+	if entry.Level == "error" {
+		out = c.stderr
+	}
+	fmt.Fprintf(out, "%s%s\n", label, strings.TrimRight(entry.Message, "\n"))
+}
+
+func orDash(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// runMigrate aplica as migrations pendentes e mostra a situação de cada uma.
+// --status e --down abrem o banco sem aplicar nada, de forma que uma migration
+// alterada depois de aplicada (que impede o Relief de iniciar) possa ser
+// desfeita e aplicada de novo.
+func runMigrate(c *cli, args []string) error {
+	fs := c.flagSet("migrate")
+	statusOnly := fs.Bool("status", false, "só mostra as migrations, sem aplicar as pendentes")
+	down := fs.Int("down", 0, "desfaz as últimas N migrations aplicadas")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if *down < 0 || (*down > 0 && *statusOnly) {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	var db *storage.DB
+	var err error
+	if *statusOnly || *down > 0 {
+		db, err = storage.OpenDB(c.logger())
+	} else {
+		db, err = storage.NewDB(c.logger())
+	}
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if *down > 0 {
+		reverted, err := db.MigrateDown(*down)
+		if err != nil {
+			return err
+		}
+		for _, m := range reverted {
+			fmt.Fprintf(c.stderr, "desfeita: %s\n", m.Name)
+		}
+	}
+
+	statuses, err := db.Migrations()
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return c.writeJSON(statuses)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED")
+	for _, m := range statuses {
+		status := "pending"
+		switch {
+		case m.Unknown:
+			status = "unknown"
+		case m.Modified:
+			status = "modified"
+		case m.Applied:
+			status = "applied"
+		}
+		applied := "-"
+		if m.AppliedAt != nil {
+			applied = m.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", m.Version, m.Name, status, applied)
+	}
+	return w.Flush()
+}
+
+// runCerts mostra a CA local e, com --trust ou --untrust, a instala ou remove
+// do repositório de confiança do sistema.
+func runCerts(c *cli, args []string) error {
+	fs := c.flagSet("certs")
+	trust := fs.Bool("trust", false, "instala a CA no repositório de confiança do sistema (pede privilégios administrativos)")
+	untrust := fs.Bool("untrust", false, "remove a CA do repositório de confiança do sistema")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 || (*trust && *untrust) {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	a, err := c.open(context.Background(), app.Options{})
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown(context.Background())
+
+	var info *proxy.CAInfo
+	switch {
+	case *trust:
+		info, err = a.TrustCertificateAuthority()
+	case *untrust:
+		info, err = a.UntrustCertificateAuthority()
+	default:
+		info, err = a.GetCertificateAuthority()
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return c.writeJSON(info)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "subject\t%s\n", info.Subject)
+	fmt.Fprintf(w, "path\t%s\n", info.Path)
+	fmt.Fprintf(w, "fingerprint\t%s\n", info.Fingerprint)
+	fmt.Fprintf(w, "not_after\t%s\n", info.NotAfter)
+	fmt.Fprintf(w, "trusted\t%v\n", info.Trusted)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !info.Trusted && !*untrust {
+		fmt.Fprintln(c.stderr, "use --trust para que os navegadores aceitem os certificados dos domínios sem alerta")
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/app"
+	"github.com/Maycon-Santos/relief/internal/config"
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/pkg/instancelock"
+)
+
+// remoteProbeTimeout é quanto a CLI espera a API da interface responder antes
+// de abrir o núcleo por conta própria.
+const remoteProbeTimeout = time.Second
+
+// backend é o que up, down, ps e logs usam do Relief: o App aberto pela
+// própria CLI ou, com a interface em execução, a API de controle dela.
+type backend interface {
+	GetProjects() ([]*domain.Project, error)
+	GetProject(id string) (*domain.Project, error)
+	StartProject(id string) error
+	StopProject(id string) error
+	StopStack(id string) error
+	GetProjectLogsSince(id string, afterID int64, limit int) ([]domain.LogEntry, error)
+	QueryProjectLogs(id string, query domain.LogQuery) (*domain.LogPage, error)
+	GetProjectRuns(id string) ([]domain.LogRun, error)
+}
+
+// remoteApp atende backend pela API de controle no socket unix, para que os
+// projetos da interface sejam iniciados e parados por ela, que sabe quais
+// processos são seus e não os reinicia ao vê-los parar.
+type remoteApp struct {
+	client *http.Client
+}
+
+// connect devolve a API da interface em execução, ou nil quando o socket não
+// responde (interface fechada ou API desligada).
+func (c *cli) connect() *remoteApp {
+	cfg, err := config.LoadLocalConfig()
+	if err != nil {
+		return nil
+	}
+	socket, err := app.ControlSocketPath(cfg)
+	if err != nil || socket == "" {
+		return nil
+	}
+
+	r := &remoteApp{client: &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteProbeTimeout)
+	defer cancel()
+	if err := r.do(ctx, http.MethodGet, "/v1/status", nil, nil); err != nil {
+		return nil
+	}
+	if c.verbose {
+		fmt.Fprintf(c.stderr, "usando o Relief em execução (%s)\n", socket)
+	}
+	return r
+}
+
+// lockInstance toma o lock da instância para a CLI gerenciar processos. Com
+// outra instância viva, retorna o *instancelock.HeldError dela.
+func lockInstance() (*instancelock.Lock, error) {
+	path, err := app.InstanceLockPath()
+	if err != nil {
+		return nil, err
+	}
+	return instancelock.Acquire(path)
+}
+
+func (r *remoteApp) do(ctx context.Context, method, path string, query url.Values, out interface{}) error {
+	target := "http://localhost" + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var body struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+			return fmt.Errorf("API do Relief respondeu %s", resp.Status)
+		}
+		return errors.New(body.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (r *remoteApp) projectPath(id, suffix string) string {
+	return "/v1/projects/" + url.PathEscape(id) + suffix
+}
+
+func (r *remoteApp) GetProjects() ([]*domain.Project, error) {
+	var projects []*domain.Project
+	err := r.do(context.Background(), http.MethodGet, "/v1/projects", nil, &projects)
+	return projects, err
+}
+
+func (r *remoteApp) GetProject(id string) (*domain.Project, error) {
+	var project domain.Project
+	if err := r.do(context.Background(), http.MethodGet, r.projectPath(id, ""), nil, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *remoteApp) StartProject(id string) error {
+	return r.do(context.Background(), http.MethodPost, r.projectPath(id, "/start"), nil, nil)
+}
+
+func (r *remoteApp) StopProject(id string) error {
+	return r.do(context.Background(), http.MethodPost, r.projectPath(id, "/stop"), nil, nil)
+}
+
+func (r *remoteApp) StopStack(id string) error {
+	return r.do(context.Background(), http.MethodPost, r.projectPath(id, "/stop"), url.Values{"stack": {"true"}}, nil)
+}
+
+func (r *remoteApp) GetProjectLogsSince(id string, afterID int64, limit int) ([]domain.LogEntry, error) {
+	var logs []domain.LogEntry
+	query := url.Values{
+		"after": {strconv.FormatInt(afterID, 10)},
+		"limit": {strconv.Itoa(limit)},
+	}
+	err := r.do(context.Background(), http.MethodGet, r.projectPath(id, "/logs"), query, &logs)
+	return logs, err
+}
+
+func (r *remoteApp) QueryProjectLogs(id string, q domain.LogQuery) (*domain.LogPage, error) {
+	query := url.Values{}
+	if q.RunID != "" {
+		query.Set("run", q.RunID)
+	}
+	if q.Search != "" {
+		query.Set("q", q.Search)
+	}
+	if q.Level != "" {
+		query.Set("level", q.Level)
+	}
+	if q.Before > 0 {
+		query.Set("before", strconv.FormatInt(q.Before, 10))
+	}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+
+	var page domain.LogPage
+	if err := r.do(context.Background(), http.MethodGet, r.projectPath(id, "/logs/history"), query, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func (r *remoteApp) GetProjectRuns(id string) ([]domain.LogRun, error) {
+	var runs []domain.LogRun
+	err := r.do(context.Background(), http.MethodGet, r.projectPath(id, "/runs"), nil, &runs)
+	return runs, err
+}
@@ -31,7 +31,7 @@ func (l *Loader) LoadConfig(remoteURL, configPath string) (*Config, error) {
 			return nil, fmt.Errorf("erro ao carregar config: %w", err)
 		}
 		finalConfig.MergeWith(localConfig)
-		fmt.Printf("Configuração local carregada de: %s\n", configPath)
+		fmt.Fprintf(os.Stderr, "Configuração local carregada de: %s\n", configPath)
 	}
 
 	effectiveRemoteURL := remoteURL
@@ -42,18 +42,18 @@ func (l *Loader) LoadConfig(remoteURL, configPath string) (*Config, error) {
 	if effectiveRemoteURL != "" {
 		remoteConfig, err := l.loadRemoteConfig(effectiveRemoteURL)
 		if err != nil {
-			fmt.Printf("Aviso: não foi possível carregar config remota: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Aviso: não foi possível carregar config remota: %v\n", err)
 		} else {
 			finalConfig.MergeWith(remoteConfig)
-			fmt.Printf("Configuração remota carregada de: %s\n", effectiveRemoteURL)
+			fmt.Fprintf(os.Stderr, "Configuração remota carregada de: %s\n", effectiveRemoteURL)
 		}
 	}
 
 	if finalConfig.Environment.ExternalWorkspaceConfig != "" {
 		if err := l.loadExternalWorkspaceProjects(finalConfig); err != nil {
-			fmt.Printf("Aviso: não foi possível carregar projetos do workspace externo: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Aviso: não foi possível carregar projetos do workspace externo: %v\n", err)
 		} else {
-			fmt.Printf("Projetos carregados do workspace externo: %s\n", finalConfig.Environment.ExternalWorkspaceConfig)
+			fmt.Fprintf(os.Stderr, "Projetos carregados do workspace externo: %s\n", finalConfig.Environment.ExternalWorkspaceConfig)
 		}
 	}
 
@@ -81,6 +81,26 @@ func (l *Loader) loadRemoteConfig(url string) (*Config, error) {
 	return &config, nil
 }
 
+// LoadLocalConfig lê só o config.yaml local, sem mensagens e sem a
+// configuração remota ou de workspace. A CLI o usa para achar a API de
+// controle antes de abrir o núcleo.
+func LoadLocalConfig() (*Config, error) {
+	path, err := GetConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := defaultConfig()
+	if fileutil.Exists(path) {
+		local, err := (&Loader{}).loadLocalConfig(path)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao carregar config: %w", err)
+		}
+		cfg.MergeWith(local)
+	}
+	return cfg, nil
+}
+
 func (l *Loader) loadLocalConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -135,7 +155,7 @@ func (l *Loader) loadExternalWorkspaceProjects(cfg *Config) error {
 	}
 
 	if len(externalConfig.Projects) > 0 {
-		fmt.Printf("Carregando %d projetos do workspace externo: %s\n", len(externalConfig.Projects), externalPath)
+		fmt.Fprintf(os.Stderr, "Carregando %d projetos do workspace externo: %s\n", len(externalConfig.Projects), externalPath)
 		cfg.Projects = append(cfg.Projects, externalConfig.Projects...)
 	}
 
@@ -176,6 +196,10 @@ func defaultConfig() *Config {
 			Dashboard:  true,
 			AutoManage: true,
 		},
+		Ports: PortsConfig{
+			RangeStart: DefaultPortRangeStart,
+			RangeEnd:   DefaultPortRangeEnd,
+		},
 	}
 }
 
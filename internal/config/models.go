@@ -1,17 +1,33 @@
 package config
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Config struct {
 	Remote              RemoteConfig                 `yaml:"remote"`
 	Projects            []ProjectConfig              `yaml:"projects"`
 	Tools               map[string]ToolVersion       `yaml:"tools"`
 	Proxy               ProxyConfig                  `yaml:"proxy"`
+	Ports               PortsConfig                  `yaml:"ports"`
 	ManagedDependencies map[string]ManagedDependency `yaml:"managed_dependencies"`
 	Development         DevelopmentConfig            `yaml:"development"`
 	Logging             LoggingConfig                `yaml:"logging"`
 	HealthChecks        map[string]HealthCheckConfig `yaml:"health_checks"`
 	Environment         EnvironmentConfig            `yaml:"environment"`
+	Env                 map[string]string            `yaml:"env,omitempty"`
+	API                 APIConfig                    `yaml:"api"`
+}
+
+// APIConfig configura a API de controle local. Por padrão ela escuta em
+// ~/.relief/relief.sock; TCPAddress só aceita endereços de loopback e exige o
+// token de ~/.relief/api-token.
+type APIConfig struct {
+	Disabled   bool   `yaml:"disabled"`
+	Socket     string `yaml:"socket,omitempty"`
+	TCPAddress string `yaml:"tcp_address,omitempty"`
 }
 
 type EnvironmentConfig struct {
@@ -27,19 +43,21 @@ type RemoteConfig struct {
 }
 
 type ProjectConfig struct {
-	Name         string            `yaml:"name"`
-	Path         string            `yaml:"path"`
-	Repository   *RepositoryConfig `yaml:"repository,omitempty"`
-	Domain       string            `yaml:"domain"`
-	Type         string            `yaml:"type"`
-	Dependencies []DependencySpec  `yaml:"dependencies"`
-	Scripts      map[string]string `yaml:"scripts"`
-	Env          map[string]string `yaml:"env"`
-	Port         int               `yaml:"port,omitempty"`
-	AutoStart    bool              `yaml:"auto_start"`
-	AutoInstall  bool              `yaml:"auto_install"`
-	AutoMigrate  bool              `yaml:"auto_migrate"`
-	SetupEnv     bool              `yaml:"setup_env"`
+	Name          string            `yaml:"name"`
+	Path          string            `yaml:"path"`
+	Repository    *RepositoryConfig `yaml:"repository,omitempty"`
+	Domain        string            `yaml:"domain"`
+	Type          string            `yaml:"type"`
+	Dependencies  []DependencySpec  `yaml:"dependencies"`
+	DependsOn     []string          `yaml:"depends_on,omitempty"`
+	Scripts       map[string]string `yaml:"scripts"`
+	Env           map[string]string `yaml:"env"`
+	Port          int               `yaml:"port,omitempty"`
+	AutoStart     bool              `yaml:"auto_start"`
+	AutoInstall   bool              `yaml:"auto_install"`
+	AutoMigrate   bool              `yaml:"auto_migrate"`
+	SetupEnv      bool              `yaml:"setup_env"`
+	HTTPSRedirect bool              `yaml:"https_redirect,omitempty"`
 }
 
 type RepositoryConfig struct {
@@ -79,10 +97,63 @@ type DevelopmentConfig struct {
 	Editor        string            `yaml:"editor,omitempty"`
 }
 
+// LoggingConfig também define a retenção dos logs dos projetos guardados no
+// banco: MaxAge aceita durações Go ou dias ("7d"), MaxSize aceita "KB", "MB"
+// e "GB". "0" desliga o respectivo limite.
 type LoggingConfig struct {
-	Level  string `yaml:"level"`
-	Format string `yaml:"format"`
-	Output string `yaml:"output"`
+	Level   string `yaml:"level"`
+	Format  string `yaml:"format"`
+	Output  string `yaml:"output"`
+	MaxAge  string `yaml:"max_age,omitempty"`
+	MaxSize string `yaml:"max_size,omitempty"`
+}
+
+const (
+	DefaultLogMaxAge  = 7 * 24 * time.Hour
+	DefaultLogMaxSize = 100 << 20
+)
+
+func (l LoggingConfig) MaxAgeDuration() time.Duration {
+	value := strings.TrimSpace(l.MaxAge)
+	if value == "" {
+		return DefaultLogMaxAge
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour
+		}
+		return DefaultLogMaxAge
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return DefaultLogMaxAge
+	}
+	return d
+}
+
+func (l LoggingConfig) MaxSizeBytes() int64 {
+	value := strings.ToUpper(strings.TrimSpace(l.MaxSize))
+	if value == "" {
+		return DefaultLogMaxSize
+	}
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return DefaultLogMaxSize
+	}
+	return n * multiplier
 }
 
 type HealthCheckConfig struct {
@@ -97,13 +168,34 @@ type ToolVersion struct {
 	DownloadURL string `yaml:"download_url,omitempty"`
 }
 
+// ProxyConfig configura o proxy que publica os domínios. Provider escolhe
+// entre o Traefik ("traefik", padrão) e o proxy reverso embutido ("builtin"),
+// que roda no próprio processo e não precisa baixar nada. Por padrão os
+// domínios também respondem em HTTPS na HTTPSPort, com certificados da CA
+// local em ~/.relief/certs; DisableHTTPS deixa só o HTTP.
 type ProxyConfig struct {
-	HTTPPort   int  `yaml:"http_port"`
-	HTTPSPort  int  `yaml:"https_port"`
-	Dashboard  bool `yaml:"dashboard"`
-	AutoManage bool `yaml:"auto_manage"`
+	Provider     string `yaml:"provider,omitempty"`
+	HTTPPort     int    `yaml:"http_port"`
+	HTTPSPort    int    `yaml:"https_port"`
+	DisableHTTPS bool   `yaml:"disable_https,omitempty"`
+	Dashboard    bool   `yaml:"dashboard"`
+	AutoManage   bool   `yaml:"auto_manage"`
 }
 
+const DefaultProxyProvider = "traefik"
+
+// PortsConfig define a faixa de onde saem as portas dos projetos que não
+// declaram uma porta fixa.
+type PortsConfig struct {
+	RangeStart int `yaml:"range_start,omitempty"`
+	RangeEnd   int `yaml:"range_end,omitempty"`
+}
+
+const (
+	DefaultPortRangeStart = 3000
+	DefaultPortRangeEnd   = 3999
+)
+
 func (c *Config) Validate() error {
 	if c.Proxy.HTTPPort <= 0 {
 		c.Proxy.HTTPPort = 80
@@ -111,6 +203,23 @@ func (c *Config) Validate() error {
 	if c.Proxy.HTTPSPort <= 0 {
 		c.Proxy.HTTPSPort = 443
 	}
+	switch c.Proxy.Provider {
+	case "":
+		c.Proxy.Provider = DefaultProxyProvider
+	case "traefik", "builtin":
+	default:
+		return &ValidationError{Field: "proxy.provider", Message: "provider deve ser \"traefik\" ou \"builtin\""}
+	}
+
+	if c.Ports.RangeStart <= 0 {
+		c.Ports.RangeStart = DefaultPortRangeStart
+	}
+	if c.Ports.RangeEnd <= 0 {
+		c.Ports.RangeEnd = DefaultPortRangeEnd
+	}
+	if c.Ports.RangeStart < 1024 || c.Ports.RangeEnd > 65535 || c.Ports.RangeStart > c.Ports.RangeEnd {
+		return &ValidationError{Field: "ports", Message: "faixa de portas deve estar entre 1024 e 65535, com range_start <= range_end"}
+	}
 
 	for i := range c.Projects {
 		if c.Projects[i].Name == "" {
@@ -184,16 +293,38 @@ func (c *Config) MergeWith(other *Config) {
 		}
 	}
 
+	if other.Env != nil {
+		if c.Env == nil {
+			c.Env = make(map[string]string)
+		}
+		for key, value := range other.Env {
+			c.Env[key] = value
+		}
+	}
+
+	if other.Proxy.Provider != "" {
+		c.Proxy.Provider = other.Proxy.Provider
+	}
 	if other.Proxy.HTTPPort != 0 {
 		c.Proxy.HTTPPort = other.Proxy.HTTPPort
 	}
 	if other.Proxy.HTTPSPort != 0 {
 		c.Proxy.HTTPSPort = other.Proxy.HTTPSPort
 	}
+	if other.Proxy.DisableHTTPS {
+		c.Proxy.DisableHTTPS = other.Proxy.DisableHTTPS
+	}
 	if other.Proxy.Dashboard {
 		c.Proxy.Dashboard = other.Proxy.Dashboard
 	}
 
+	if other.Ports.RangeStart != 0 {
+		c.Ports.RangeStart = other.Ports.RangeStart
+	}
+	if other.Ports.RangeEnd != 0 {
+		c.Ports.RangeEnd = other.Ports.RangeEnd
+	}
+
 	if other.Remote.URL != "" {
 		c.Remote = other.Remote
 	}
@@ -208,6 +339,23 @@ func (c *Config) MergeWith(other *Config) {
 		c.Environment.CompanyName = other.Environment.CompanyName
 	}
 
+	if other.API.Disabled {
+		c.API.Disabled = true
+	}
+	if other.API.Socket != "" {
+		c.API.Socket = other.API.Socket
+	}
+	if other.API.TCPAddress != "" {
+		c.API.TCPAddress = other.API.TCPAddress
+	}
+
+	if other.Logging.MaxAge != "" {
+		c.Logging.MaxAge = other.Logging.MaxAge
+	}
+	if other.Logging.MaxSize != "" {
+		c.Logging.MaxSize = other.Logging.MaxSize
+	}
+
 	if other.Development.GlobalScripts != nil {
 		if c.Development.GlobalScripts == nil {
 			c.Development.GlobalScripts = make(map[string]string)
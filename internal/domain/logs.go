@@ -0,0 +1,37 @@
+package domain
+
+// LogQuery filtra e pagina os logs persistidos de um projeto. A paginação é
+// por cursor: Before é o ID da entrada mais antiga já exibida.
+type LogQuery struct {
+	RunID  string `json:"run_id,omitempty"`
+	Search string `json:"search,omitempty"`
+	Level  string `json:"level,omitempty"`
+	Before int64  `json:"before,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// LogPage traz as entradas em ordem cronológica. NextCursor, quando diferente
+// de zero, é o Before da página anterior.
+type LogPage struct {
+	Entries    []LogEntry `json:"entries"`
+	NextCursor int64      `json:"next_cursor,omitempty"`
+}
+
+// LogRun resume uma execução do projeto: tudo que foi logado entre um start e
+// o fim do processo.
+type LogRun struct {
+	RunID     string `json:"run_id"`
+	StartedAt string `json:"started_at"`
+	EndedAt   string `json:"ended_at"`
+	Lines     int    `json:"lines"`
+	Errors    int    `json:"errors"`
+}
+
+// LogBatch é o lote de linhas novas enviado ao frontend no evento
+// "logs:<project_id>". Skipped indica que o frontend ficou para trás e as
+// linhas entre o último lote e este foram puladas.
+type LogBatch struct {
+	ProjectID string     `json:"project_id"`
+	Entries   []LogEntry `json:"entries"`
+	Skipped   bool       `json:"skipped,omitempty"`
+}
@@ -4,22 +4,33 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Manifest struct {
-	Name         string                 `yaml:"name"`
-	Domain       string                 `yaml:"domain"`
-	Type         string                 `yaml:"type"`
-	Dependencies []ManifestDependency   `yaml:"dependencies"`
-	Scripts      map[string]string      `yaml:"scripts"`
-	Env          map[string]string      `yaml:"env"`
-	Ports        map[string]int         `yaml:"ports,omitempty"`
-	Volumes      []string               `yaml:"volumes,omitempty"`
-	Networks     []string               `yaml:"networks,omitempty"`
-	Extra        map[string]interface{} `yaml:",inline"`
+	Name            string                 `yaml:"name"`
+	Domain          string                 `yaml:"domain"`
+	Type            string                 `yaml:"type"`
+	Dependencies    []ManifestDependency   `yaml:"dependencies"`
+	DependsOn       []string               `yaml:"depends_on,omitempty"`
+	Scripts         map[string]string      `yaml:"scripts"`
+	Env             map[string]string      `yaml:"env"`
+	Ports           map[string]int         `yaml:"ports,omitempty"`
+	Volumes         []string               `yaml:"volumes,omitempty"`
+	Networks        []string               `yaml:"networks,omitempty"`
+	Docker          *DockerConfig          `yaml:"docker,omitempty"`
+	Readiness       *ReadinessProbe        `yaml:"readiness,omitempty"`
+	Restart         *RestartPolicy         `yaml:"restart,omitempty"`
+	StopGracePeriod string                 `yaml:"stop_grace_period,omitempty"`
+	HTTPSRedirect   bool                   `yaml:"https_redirect,omitempty"`
+	Aliases         []string               `yaml:"aliases,omitempty"`
+	Routes          []ManifestRoute        `yaml:"routes,omitempty"`
+	Extra           map[string]interface{} `yaml:",inline"`
 }
 
 type ManifestDependency struct {
@@ -28,6 +39,241 @@ type ManifestDependency struct {
 	Managed bool   `yaml:"managed"`
 }
 
+type DockerConfig struct {
+	Image       string            `yaml:"image,omitempty"`
+	ComposeFile string            `yaml:"compose_file,omitempty"`
+	MainService string            `yaml:"main_service,omitempty"`
+	Command     []string          `yaml:"command,omitempty"`
+	WorkingDir  string            `yaml:"working_dir,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+}
+
+// ReadinessProbe define quando o projeto passa de "starting" para "running".
+// Apenas um entre HTTP, TCP, Log e Command deve ser informado.
+type ReadinessProbe struct {
+	HTTP         string `yaml:"http,omitempty"`
+	ExpectStatus int    `yaml:"expect_status,omitempty"`
+	TCP          int    `yaml:"tcp,omitempty"`
+	Log          string `yaml:"log,omitempty"`
+	Command      string `yaml:"command,omitempty"`
+	Interval     string `yaml:"interval,omitempty"`
+	Timeout      string `yaml:"timeout,omitempty"`
+	InitialDelay string `yaml:"initial_delay,omitempty"`
+}
+
+const (
+	defaultProbeInterval = time.Second
+	defaultProbeTimeout  = 2 * time.Minute
+)
+
+func (p *ReadinessProbe) Kind() string {
+	switch {
+	case p.HTTP != "":
+		return "http"
+	case p.TCP > 0:
+		return "tcp"
+	case p.Log != "":
+		return "log"
+	case p.Command != "":
+		return "command"
+	}
+	return ""
+}
+
+func (p *ReadinessProbe) IntervalDuration() time.Duration {
+	return parseProbeDuration(p.Interval, defaultProbeInterval)
+}
+
+func (p *ReadinessProbe) TimeoutDuration() time.Duration {
+	return parseProbeDuration(p.Timeout, defaultProbeTimeout)
+}
+
+func (p *ReadinessProbe) InitialDelayDuration() time.Duration {
+	return parseProbeDuration(p.InitialDelay, 0)
+}
+
+func (p *ReadinessProbe) Validate() error {
+	set := 0
+	for _, v := range []bool{p.HTTP != "", p.TCP > 0, p.Log != "", p.Command != ""} {
+		if v {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("'readiness' must define exactly one of http, tcp, log or command")
+	}
+
+	if p.Log != "" {
+		if _, err := regexp.Compile(p.Log); err != nil {
+			return fmt.Errorf("'readiness.log' is not a valid regex: %w", err)
+		}
+	}
+
+	for field, value := range map[string]string{"interval": p.Interval, "timeout": p.Timeout, "initial_delay": p.InitialDelay} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("'readiness.%s' is not a valid duration: %w", field, err)
+		}
+	}
+
+	return nil
+}
+
+func parseProbeDuration(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+type RestartMode string
+
+const (
+	RestartNever     RestartMode = "never"
+	RestartOnFailure RestartMode = "on-failure"
+	RestartAlways    RestartMode = "always"
+)
+
+// RestartPolicy define se o processo é reiniciado quando termina. Aceita a
+// forma curta (`restart: on-failure`) ou o objeto completo.
+type RestartPolicy struct {
+	Policy     RestartMode `yaml:"policy"`
+	MaxRetries int         `yaml:"max_retries,omitempty"`
+	Backoff    string      `yaml:"backoff,omitempty"`
+	MaxBackoff string      `yaml:"max_backoff,omitempty"`
+}
+
+const (
+	defaultRestartRetries    = 5
+	defaultRestartBackoff    = time.Second
+	defaultRestartMaxBackoff = 30 * time.Second
+)
+
+func (p *RestartPolicy) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		p.Policy = RestartMode(value.Value)
+		return nil
+	}
+
+	type plain RestartPolicy
+	return value.Decode((*plain)(p))
+}
+
+func (p *RestartPolicy) Validate() error {
+	switch p.Policy {
+	case RestartNever, RestartOnFailure, RestartAlways, "":
+	default:
+		return fmt.Errorf("'restart.policy' must be never, on-failure or always, got '%s'", p.Policy)
+	}
+	if p.MaxRetries < 0 {
+		return fmt.Errorf("'restart.max_retries' must not be negative")
+	}
+	for field, value := range map[string]string{"backoff": p.Backoff, "max_backoff": p.MaxBackoff} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("'restart.%s' is not a valid duration: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// ShouldRestart diz se um processo que terminou com o status informado deve
+// ser reiniciado.
+func (p *RestartPolicy) ShouldRestart(exit Status) bool {
+	if p == nil {
+		return false
+	}
+	switch p.Policy {
+	case RestartAlways:
+		return exit == StatusStopped || exit == StatusError
+	case RestartOnFailure:
+		return exit == StatusError
+	}
+	return false
+}
+
+func (p *RestartPolicy) Retries() int {
+	if p.MaxRetries == 0 {
+		return defaultRestartRetries
+	}
+	return p.MaxRetries
+}
+
+// Delay retorna o backoff exponencial da tentativa (começando em 1).
+func (p *RestartPolicy) Delay(attempt int) time.Duration {
+	base := parseProbeDuration(p.Backoff, defaultRestartBackoff)
+	limit := parseProbeDuration(p.MaxBackoff, defaultRestartMaxBackoff)
+
+	delay := base
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	return delay
+}
+
+// ManifestRoute publica o projeto, ou outro projeto, além do domínio
+// principal: em outros hosts, num prefixo de caminho ou noutra porta. Hosts
+// vazio são o domínio e os aliases do projeto; "*.dominio" vale para qualquer
+// subdomínio de um nível. Port aceita um número ou o nome de uma porta em
+// ports; com Project, a rota aponta para aquele projeto e Port se refere às
+// portas dele.
+type ManifestRoute struct {
+	Hosts           []string          `yaml:"hosts,omitempty"`
+	PathPrefix      string            `yaml:"path_prefix,omitempty"`
+	StripPrefix     bool              `yaml:"strip_prefix,omitempty"`
+	Project         string            `yaml:"project,omitempty"`
+	Port            string            `yaml:"port,omitempty"`
+	Priority        int               `yaml:"priority,omitempty"`
+	RequestHeaders  map[string]string `yaml:"request_headers,omitempty"`
+	ResponseHeaders map[string]string `yaml:"response_headers,omitempty"`
+}
+
+func (r *ManifestRoute) Validate(index int) error {
+	field := fmt.Sprintf("routes[%d]", index)
+	for _, host := range r.Hosts {
+		if err := validateRouteHost(host); err != nil {
+			return fmt.Errorf("'%s.hosts': %w", field, err)
+		}
+	}
+	if r.PathPrefix != "" && !strings.HasPrefix(r.PathPrefix, "/") {
+		return fmt.Errorf("'%s.path_prefix' must start with '/'", field)
+	}
+	if r.StripPrefix && r.PathPrefix == "" {
+		return fmt.Errorf("'%s.strip_prefix' requires 'path_prefix'", field)
+	}
+	if r.Priority < 0 {
+		return fmt.Errorf("'%s.priority' must not be negative", field)
+	}
+	if r.Port != "" {
+		if n, err := strconv.Atoi(r.Port); err == nil && (n <= 0 || n > 65535) {
+			return fmt.Errorf("'%s.port' must be between 1 and 65535", field)
+		}
+	}
+	if len(r.Hosts) == 0 && r.PathPrefix == "" && r.Project == "" && r.Port == "" {
+		return fmt.Errorf("'%s' must set at least one of hosts, path_prefix, project or port", field)
+	}
+	return nil
+}
+
+func validateRouteHost(host string) error {
+	name := strings.TrimPrefix(host, "*.")
+	if name == "" || strings.ContainsAny(name, "*/: ") {
+		return fmt.Errorf("'%s' is not a valid host (only a leading '*.' wildcard is allowed)", host)
+	}
+	return nil
+}
+
 func ParseManifest(projectPath string) (*Manifest, error) {
 	manifestPath := filepath.Join(projectPath, "relief.yaml")
 
@@ -40,6 +286,11 @@ func ParseManifest(projectPath string) (*Manifest, error) {
 		return nil, fmt.Errorf("error reading relief.yaml: %w", err)
 	}
 
+	return ParseManifestData(data)
+}
+
+// ParseManifestData interpreta o conteúdo de um relief.yaml já lido.
+func ParseManifestData(data []byte) (*Manifest, error) {
 	var manifest Manifest
 	if err := yaml.Unmarshal(data, &manifest); err != nil {
 		return nil, fmt.Errorf("invalid YAML format in relief.yaml: %w", err)
@@ -72,9 +323,53 @@ func (m *Manifest) Validate() error {
 		return fmt.Errorf("type '%s' is not valid", m.Type)
 	}
 
+	if m.Readiness != nil {
+		if err := m.Readiness.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if m.Restart != nil {
+		if err := m.Restart.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if m.StopGracePeriod != "" {
+		if d, err := time.ParseDuration(m.StopGracePeriod); err != nil || d < 0 {
+			return fmt.Errorf("stop_grace_period '%s' is not a valid duration", m.StopGracePeriod)
+		}
+	}
+
+	for _, alias := range m.Aliases {
+		if err := validateRouteHost(alias); err != nil {
+			return fmt.Errorf("'aliases': %w", err)
+		}
+	}
+	for i := range m.Routes {
+		if err := m.Routes[i].Validate(i); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// DefaultStopGracePeriod é quanto o runner espera após o SIGTERM antes de
+// mandar SIGKILL para o grupo de processos do projeto.
+const DefaultStopGracePeriod = 10 * time.Second
+
+func (m *Manifest) StopGracePeriodDuration() time.Duration {
+	if m == nil || m.StopGracePeriod == "" {
+		return DefaultStopGracePeriod
+	}
+	d, err := time.ParseDuration(m.StopGracePeriod)
+	if err != nil || d < 0 {
+		return DefaultStopGracePeriod
+	}
+	return d
+}
+
 func (m *Manifest) GetDevScript() string {
 	if script, ok := m.Scripts["dev"]; ok {
 		return script
@@ -115,15 +410,7 @@ func (m *Manifest) ToProject(path string) *Project {
 	project.Env = m.Env
 	project.Manifest = m
 
-	if portStr, ok := m.Env["PORT"]; ok {
-		if port, err := strconv.Atoi(portStr); err == nil {
-			project.Port = port
-		}
-	} else if m.Ports != nil {
-		if mainPort, ok := m.Ports["main"]; ok {
-			project.Port = mainPort
-		}
-	}
+	project.Port = m.DeclaredPorts()["main"]
 
 	for _, dep := range m.Dependencies {
 		project.Dependencies = append(project.Dependencies, Dependency{
@@ -137,6 +424,27 @@ func (m *Manifest) ToProject(path string) *Project {
 	return project
 }
 
+// DeclaredPorts retorna as portas nomeadas do relief.yaml. A porta "main" vem
+// de env.PORT, de ports.main ou do campo port; valor 0 deixa a escolha para o
+// alocador de portas.
+func (m *Manifest) DeclaredPorts() map[string]int {
+	ports := make(map[string]int, len(m.Ports)+1)
+	for name, port := range m.Ports {
+		ports[name] = port
+	}
+
+	if portStr, ok := m.Env["PORT"]; ok {
+		if port, err := strconv.Atoi(portStr); err == nil {
+			ports["main"] = port
+		}
+	} else if _, ok := ports["main"]; !ok {
+		if port, ok := m.Extra["port"].(int); ok {
+			ports["main"] = port
+		}
+	}
+	return ports
+}
+
 func (m *Manifest) SaveManifest(projectPath string) error {
 	manifestPath := filepath.Join(projectPath, "relief.yaml")
 
@@ -182,6 +490,7 @@ func CreateDefaultManifest(name, projectType string) *Manifest {
 	case "docker":
 		manifest.Scripts["dev"] = "docker-compose up"
 		manifest.Scripts["install"] = "docker-compose pull"
+		manifest.Docker = &DockerConfig{ComposeFile: "docker-compose.yml"}
 	}
 
 	return manifest
@@ -18,11 +18,12 @@ const (
 type Status string
 
 const (
-	StatusStopped  Status = "stopped"
-	StatusStarting Status = "starting"
-	StatusRunning  Status = "running"
-	StatusError    Status = "error"
-	StatusUnknown  Status = "unknown"
+	StatusStopped   Status = "stopped"
+	StatusStarting  Status = "starting"
+	StatusRunning   Status = "running"
+	StatusError     Status = "error"
+	StatusCrashLoop Status = "crash_loop"
+	StatusUnknown   Status = "unknown"
 )
 
 type Project struct {
@@ -33,7 +34,9 @@ type Project struct {
 	Type         ProjectType       `json:"type"`
 	Status       Status            `json:"status"`
 	Port         int               `json:"port"`
+	Ports        map[string]int    `json:"ports,omitempty"`
 	PID          int               `json:"pid,omitempty"`
+	PGID         int               `json:"pgid,omitempty"`
 	Dependencies []Dependency      `json:"dependencies"`
 	Scripts      map[string]string `json:"scripts"`
 	Env          map[string]string `json:"env"`
@@ -41,9 +44,30 @@ type Project struct {
 	CreatedAt    string            `json:"created_at"`
 	UpdatedAt    string            `json:"updated_at"`
 	LastError    string            `json:"last_error,omitempty"`
+	Sources      map[string]string `json:"sources,omitempty"`
+	ManifestHash string            `json:"-"`
 	GitInfo *GitInfo `json:"git_info,omitempty"`
 }
 
+// Origem de cada campo da definição do projeto, em Project.Sources. As chaves
+// são o nome do campo ("domain", "port") ou "scripts.<nome>" e "env.<NOME>".
+const (
+	SourceConfig   = "config.yaml"
+	SourceManifest = "relief.yaml"
+)
+
+// ProjectChange é uma alteração na definição do projeto: um campo que mudou de
+// valor, apareceu (OldValue vazio) ou sumiu (NewValue vazio).
+type ProjectChange struct {
+	ID        int64  `json:"id"`
+	ProjectID string `json:"project_id"`
+	Field     string `json:"field"`
+	OldValue  string `json:"old_value,omitempty"`
+	NewValue  string `json:"new_value,omitempty"`
+	Source    string `json:"source,omitempty"`
+	ChangedAt string `json:"changed_at"`
+}
+
 type GitInfo struct {
 	IsRepository      bool     `json:"is_repository"`
 	CurrentBranch     string   `json:"current_branch,omitempty"`
@@ -63,11 +87,14 @@ type Dependency struct {
 }
 
 type LogEntry struct {
-	ID        int64  `json:"id"`
-	ProjectID string `json:"project_id"`
-	Level     string `json:"level"`
-	Message   string `json:"message"`
-	Timestamp string `json:"timestamp"`
+	ID        int64             `json:"id"`
+	ProjectID string            `json:"project_id"`
+	RunID     string            `json:"run_id,omitempty"`
+	Level     string            `json:"level"`
+	Stream    string            `json:"stream,omitempty"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	Timestamp string            `json:"timestamp"`
 }
 
 func NewProject(name, path, domain string, projectType ProjectType) *Project {
@@ -91,6 +118,12 @@ func (p *Project) IsRunning() bool {
 	return p.Status == StatusRunning
 }
 
+// IsActive indica que o processo está de pé, pronto ou ainda aguardando o
+// readiness probe.
+func (p *Project) IsActive() bool {
+	return p.Status == StatusRunning || p.Status == StatusStarting
+}
+
 func (p *Project) IsStopped() bool {
 	return p.Status == StatusStopped
 }
@@ -0,0 +1,44 @@
+package domain
+
+// ProxyRequest é uma requisição atendida pelo proxy para um projeto, lida do
+// access log do Traefik ou registrada pelo proxy embutido.
+type ProxyRequest struct {
+	ID        int64  `json:"id"`
+	ProjectID string `json:"project_id"`
+	Route     string `json:"route"`
+	Method    string `json:"method"`
+	Scheme    string `json:"scheme"`
+	Host      string `json:"host"`
+	// Path inclui a query string, como chegou ao proxy.
+	Path         string  `json:"path"`
+	Status       int     `json:"status"`
+	DurationMs   float64 `json:"duration_ms"`
+	Upstream     string  `json:"upstream,omitempty"`
+	RequestSize  int64   `json:"request_size,omitempty"`
+	ResponseSize int64   `json:"response_size,omitempty"`
+	// Headers são os cabeçalhos da requisição, guardados para o replay.
+	Headers   map[string]string `json:"headers,omitempty"`
+	CreatedAt string            `json:"created_at"`
+}
+
+// RequestQuery filtra as requisições de um projeto. Status aceita um código
+// ("404"), uma classe ("2xx", "5xx") ou "errors" (400 em diante); Before é o
+// ID da requisição mais antiga já exibida.
+type RequestQuery struct {
+	Status string `json:"status,omitempty"`
+	Search string `json:"search,omitempty"`
+	Before int64  `json:"before,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// ReplayResult é a resposta de uma requisição reenviada pelo proxy. O corpo
+// da requisição original não é capturado; BodyOmitted avisa quando ela tinha
+// um.
+type ReplayResult struct {
+	Status      int               `json:"status"`
+	DurationMs  float64           `json:"duration_ms"`
+	Headers     map[string]string `json:"headers"`
+	Body        string            `json:"body"`
+	Truncated   bool              `json:"truncated,omitempty"`
+	BodyOmitted bool              `json:"body_omitted,omitempty"`
+}
@@ -0,0 +1,54 @@
+package domain
+
+// Run é uma execução do projeto, do start até o processo terminar. O ID é o
+// mesmo run ID dos logs da execução.
+type Run struct {
+	ID         string  `json:"id"`
+	ProjectID  string  `json:"project_id"`
+	Runner     string  `json:"runner"`
+	Script     string  `json:"script"`
+	GitBranch  string  `json:"git_branch,omitempty"`
+	GitCommit  string  `json:"git_commit,omitempty"`
+	Status     Status  `json:"status"`
+	EndReason  string  `json:"end_reason,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	StartedAt  string  `json:"started_at"`
+	ReadyAt    string  `json:"ready_at,omitempty"`
+	ReadyMs    int64   `json:"ready_ms,omitempty"`
+	EndedAt    string  `json:"ended_at,omitempty"`
+	ExitCode   *int    `json:"exit_code,omitempty"`
+	Signal     string  `json:"signal,omitempty"`
+	DurationMs int64   `json:"duration_ms,omitempty"`
+	Events     []Event `json:"events"`
+}
+
+// Motivos do fim de uma execução, em Run.EndReason.
+const (
+	RunEndStopped     = "stopped"
+	RunEndExited      = "exited"
+	RunEndStartFailed = "start_failed"
+	RunEndInterrupted = "interrupted"
+)
+
+// Event é uma entrada do diário do ciclo de vida do projeto.
+type Event struct {
+	ID        int64  `json:"id"`
+	ProjectID string `json:"project_id"`
+	RunID     string `json:"run_id,omitempty"`
+	Type      string `json:"type"`
+	Message   string `json:"message"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Tipos de Event.
+const (
+	EventStart           = "start"
+	EventStartFailed     = "start_failed"
+	EventReady           = "ready"
+	EventReadinessFailed = "readiness_failed"
+	EventStop            = "stop"
+	EventExit            = "exit"
+	EventRestart         = "restart"
+	EventCrashLoop       = "crash_loop"
+	EventInterrupted     = "interrupted"
+)
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CycleError indica um ciclo entre projetos declarados em depends_on.
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("ciclo de dependências entre projetos: %s", strings.Join(e.Path, " -> "))
+}
+
+// StartOrder retorna os projetos alcançáveis a partir de roots em ordem
+// topológica: cada projeto aparece depois de todos os que ele depende. Entre
+// projetos independentes, vale a posição em priority (startup_order) e depois
+// o nome.
+func StartOrder(graph map[string][]string, roots []string, priority []string) ([]string, error) {
+	rank := make(map[string]int, len(priority))
+	for i, name := range priority {
+		if _, ok := rank[name]; !ok {
+			rank[name] = i
+		}
+	}
+	less := func(x, y string) bool {
+		rx, okx := rank[x]
+		ry, oky := rank[y]
+		switch {
+		case okx && oky && rx != ry:
+			return rx < ry
+		case okx != oky:
+			return okx
+		}
+		return x < y
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	order := []string{}
+	stack := []string{}
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			start := 0
+			for i, n := range stack {
+				if n == name {
+					start = i
+					break
+				}
+			}
+			path := append(append([]string{}, stack[start:]...), name)
+			return &CycleError{Path: path}
+		}
+
+		deps, ok := graph[name]
+		if !ok {
+			if len(stack) > 0 {
+				return fmt.Errorf("projeto '%s' depende de '%s', que não existe", stack[len(stack)-1], name)
+			}
+			return fmt.Errorf("projeto '%s' não encontrado", name)
+		}
+
+		state[name] = visiting
+		stack = append(stack, name)
+
+		sorted := append([]string{}, deps...)
+		sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+		for _, dep := range sorted {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+
+	sortedRoots := append([]string{}, roots...)
+	sort.SliceStable(sortedRoots, func(i, j int) bool { return less(sortedRoots[i], sortedRoots[j]) })
+	for _, root := range sortedRoots {
+		if err := visit(root); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
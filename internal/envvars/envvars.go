@@ -0,0 +1,412 @@
+// Package envvars monta o ambiente de tudo o que o Relief executa a partir de
+// camadas (sistema, config global, config do projeto, relief.yaml, .env e
+// .env.local), resolvendo ${VAR}, ${VAR:-padrão} e ${project:<nome>.<campo>}
+// e guardando de qual camada veio cada variável.
+package envvars
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Maycon-Santos/relief/internal/secrets"
+	"github.com/Maycon-Santos/relief/pkg/dotenv"
+)
+
+// Source identifica a camada de onde uma variável veio.
+type Source string
+
+const (
+	SourceOS            Source = "os"
+	SourceRelief        Source = "relief"
+	SourceGlobalConfig  Source = "config"
+	SourceProjectConfig Source = "config.projects"
+	SourceManifest      Source = "relief.yaml"
+	SourceDotEnv        Source = ".env"
+	SourceDotEnvLocal   Source = ".env.local"
+)
+
+// Layer é um conjunto de variáveis de uma mesma origem. Camadas posteriores
+// sobrescrevem as anteriores. Valores de camadas Literal não são interpolados.
+type Layer struct {
+	Source  Source
+	Values  map[string]string
+	Literal bool
+}
+
+// OSLayer são as variáveis do processo, recebidas no formato KEY=valor.
+func OSLayer(environ []string) Layer {
+	values := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if eq := strings.IndexByte(kv, '='); eq > 0 {
+			values[kv[:eq]] = kv[eq+1:]
+		}
+	}
+	return Layer{Source: SourceOS, Values: values, Literal: true}
+}
+
+// FileLayer lê um .env. Arquivo inexistente vira uma camada vazia. Valores
+// entre aspas simples são literais, como no shell.
+func FileLayer(source Source, path string) (Layer, error) {
+	layer := Layer{Source: source, Values: map[string]string{}}
+
+	entries, err := dotenv.Read(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return layer, nil
+		}
+		return layer, err
+	}
+
+	for _, e := range entries {
+		value := e.Value
+		if e.Quote == '\'' {
+			value = strings.ReplaceAll(value, "$", "$$")
+		}
+		layer.Values[e.Key] = value
+	}
+	return layer, nil
+}
+
+// ProjectInfo é o que ${project:<nome>.<campo>} enxerga de outro projeto.
+type ProjectInfo struct {
+	Name   string
+	Domain string
+	URL    string
+	Ports  map[string]int
+}
+
+// ProjectLookup busca um projeto pelo nome para as referências ${project:...}.
+type ProjectLookup func(name string) (*ProjectInfo, error)
+
+// SecretResolver lê o segredo de uma referência secret://.
+type SecretResolver func(ref string) (string, error)
+
+// Options são os ganchos da resolução. Sem Secrets, valores secret:// ficam
+// como referência: é o modo usado para exibir o ambiente.
+type Options struct {
+	Projects ProjectLookup
+	Secrets  SecretResolver
+}
+
+// Variable é uma variável resolvida. Raw é o valor antes da interpolação,
+// quando diferente; Overrides lista as camadas que também a definiam e
+// perderam; Missing, as referências a variáveis que não existem. Secret
+// marca valores que são ou contêm um segredo.
+type Variable struct {
+	Name      string   `json:"name"`
+	Value     string   `json:"value"`
+	Raw       string   `json:"raw,omitempty"`
+	Source    Source   `json:"source"`
+	Overrides []Source `json:"overrides,omitempty"`
+	Missing   []string `json:"missing,omitempty"`
+	Secret    bool     `json:"secret,omitempty"`
+}
+
+// Env é o resultado da resolução.
+type Env struct {
+	vars    map[string]*Variable
+	secrets []string
+}
+
+func (e *Env) Get(name string) (string, bool) {
+	v, ok := e.vars[name]
+	if !ok {
+		return "", false
+	}
+	return v.Value, true
+}
+
+// Environ retorna todas as variáveis no formato de exec.Cmd.Env.
+func (e *Env) Environ() []string {
+	environ := make([]string, 0, len(e.vars))
+	for _, v := range e.Variables() {
+		environ = append(environ, v.Name+"="+v.Value)
+	}
+	return environ
+}
+
+// Defined retorna só as variáveis que não vieram do sistema: o que o Relief
+// acrescenta ao ambiente de um processo ou container.
+func (e *Env) Defined() map[string]string {
+	values := map[string]string{}
+	for name, v := range e.vars {
+		if v.Source != SourceOS {
+			values[name] = v.Value
+		}
+	}
+	return values
+}
+
+// Secrets retorna os segredos lidos durante a resolução, para mascarar logs.
+func (e *Env) Secrets() []string {
+	return e.secrets
+}
+
+// Variables lista as variáveis em ordem alfabética.
+func (e *Env) Variables() []Variable {
+	vars := make([]Variable, 0, len(e.vars))
+	for _, v := range e.vars {
+		vars = append(vars, *v)
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+// Resolve combina as camadas, da menos para a mais prioritária.
+//
+// Referências a outra variável usam o valor final dela; uma variável que
+// referencia a si mesma (PATH: "./bin:${PATH}") recebe o valor da camada
+// abaixo. "$$" escreve um "$" literal. Um valor que, depois de interpolado,
+// seja uma referência secret:// é lido com opts.Secrets.
+func Resolve(layers []Layer, opts Options) (*Env, error) {
+	r := &resolver{
+		layers:   layers,
+		defs:     map[string][]int{},
+		opts:     opts,
+		memo:     map[ref]result{},
+		visiting: map[ref]bool{},
+	}
+	for i, layer := range layers {
+		for name := range layer.Values {
+			r.defs[name] = append(r.defs[name], i)
+		}
+	}
+
+	env := &Env{vars: make(map[string]*Variable, len(r.defs))}
+	for name, defs := range r.defs {
+		res, err := r.value(name, len(layers))
+		if err != nil {
+			return nil, err
+		}
+
+		top := defs[len(defs)-1]
+		v := &Variable{
+			Name:    name,
+			Value:   res.value,
+			Source:  layers[top].Source,
+			Missing: res.missing,
+			Secret:  res.secret,
+		}
+		if raw := layers[top].Values[name]; raw != res.value {
+			v.Raw = raw
+		}
+		for _, i := range defs[:len(defs)-1] {
+			v.Overrides = append(v.Overrides, layers[i].Source)
+		}
+		env.vars[name] = v
+	}
+	env.secrets = r.secrets
+	return env, nil
+}
+
+// ref é uma variável vista a partir de uma camada: só valem definições em
+// camadas abaixo de below.
+type ref struct {
+	name  string
+	below int
+}
+
+type result struct {
+	value   string
+	missing []string
+	found   bool
+	secret  bool
+}
+
+type resolver struct {
+	layers   []Layer
+	defs     map[string][]int
+	opts     Options
+	memo     map[ref]result
+	visiting map[ref]bool
+	secrets  []string
+}
+
+func (r *resolver) value(name string, below int) (result, error) {
+	key := ref{name, below}
+	if res, ok := r.memo[key]; ok {
+		return res, nil
+	}
+	if r.visiting[key] {
+		return result{}, fmt.Errorf("referência circular em ${%s}", name)
+	}
+
+	layer := -1
+	for _, i := range r.defs[name] {
+		if i < below {
+			layer = i
+		}
+	}
+	if layer < 0 {
+		return result{}, nil
+	}
+
+	raw := r.layers[layer].Values[name]
+	if r.layers[layer].Literal {
+		res := result{value: raw, found: true}
+		r.memo[key] = res
+		return res, nil
+	}
+
+	r.visiting[key] = true
+	res, err := r.expand(raw, name, layer)
+	delete(r.visiting, key)
+	if err != nil {
+		return result{}, fmt.Errorf("%s (%s): %w", name, r.layers[layer].Source, err)
+	}
+	res.found = true
+
+	if secrets.IsRef(res.value) {
+		res.secret = true
+		if r.opts.Secrets != nil {
+			secret, err := r.opts.Secrets(res.value)
+			if err != nil {
+				return result{}, fmt.Errorf("%s (%s): %w", name, r.layers[layer].Source, err)
+			}
+			res.value = secret
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+
+	r.memo[key] = res
+	return res, nil
+}
+
+// expand interpola s, o valor de self definido na camada layer.
+func (r *resolver) expand(s, self string, layer int) (result, error) {
+	if !strings.Contains(s, "$") {
+		return result{value: s}, nil
+	}
+
+	var sb strings.Builder
+	out := result{}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+			continue
+		case '{':
+		default:
+			sb.WriteByte('$')
+			continue
+		}
+
+		end := matchingBrace(s, i+2)
+		if end < 0 {
+			return result{}, fmt.Errorf("'${' sem '}' correspondente")
+		}
+		expr := s[i+2 : end]
+		i = end
+
+		name, fallback, hasDefault := expr, "", false
+		if idx := strings.Index(expr, ":-"); idx >= 0 {
+			name, fallback, hasDefault = expr[:idx], expr[idx+2:], true
+		}
+
+		var value string
+		var found bool
+		if strings.HasPrefix(name, "project:") {
+			v, err := r.projectField(strings.TrimPrefix(name, "project:"))
+			if err != nil && !hasDefault {
+				return result{}, err
+			}
+			value, found = v, err == nil
+		} else {
+			below := len(r.layers)
+			if name == self {
+				below = layer
+			}
+			res, err := r.value(name, below)
+			if err != nil {
+				return result{}, err
+			}
+			value, found = res.value, res.found
+			out.missing = append(out.missing, res.missing...)
+			out.secret = out.secret || res.secret
+			if !found && !hasDefault {
+				out.missing = append(out.missing, name)
+			}
+		}
+
+		if hasDefault && (!found || value == "") {
+			res, err := r.expand(fallback, self, layer)
+			if err != nil {
+				return result{}, err
+			}
+			value = res.value
+			out.missing = append(out.missing, res.missing...)
+			out.secret = out.secret || res.secret
+		}
+		sb.WriteString(value)
+	}
+	out.value = sb.String()
+	return out, nil
+}
+
+// projectField resolve "<nome>.<campo>", com campo url, domain, port ou
+// ports.<nome>.
+func (r *resolver) projectField(expr string) (string, error) {
+	dot := strings.IndexByte(expr, '.')
+	if dot <= 0 {
+		return "", fmt.Errorf("referência inválida ${project:%s}: use ${project:<nome>.<campo>}", expr)
+	}
+	name, field := expr[:dot], expr[dot+1:]
+
+	if r.opts.Projects == nil {
+		return "", fmt.Errorf("projeto '%s' não encontrado", name)
+	}
+	info, err := r.opts.Projects(name)
+	if err != nil {
+		return "", err
+	}
+
+	port := func(portName string) (string, error) {
+		if p := info.Ports[portName]; p > 0 {
+			return strconv.Itoa(p), nil
+		}
+		return "", fmt.Errorf("projeto '%s' não tem porta '%s' definida ou alocada", name, portName)
+	}
+
+	switch {
+	case field == "url":
+		if info.URL == "" {
+			return "", fmt.Errorf("projeto '%s' não tem domínio nem porta definidos", name)
+		}
+		return info.URL, nil
+	case field == "domain":
+		if info.Domain == "" {
+			return "", fmt.Errorf("projeto '%s' não tem domínio definido", name)
+		}
+		return info.Domain, nil
+	case field == "port":
+		return port("main")
+	case strings.HasPrefix(field, "ports."):
+		return port(strings.TrimPrefix(field, "ports."))
+	default:
+		return "", fmt.Errorf("campo '%s' desconhecido em ${project:%s}", field, expr)
+	}
+}
+
+func matchingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
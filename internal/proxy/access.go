@@ -0,0 +1,267 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// AccessRecord é uma requisição atendida pelo proxy numa rota de projeto.
+type AccessRecord struct {
+	ProjectID string
+	Route     string
+	Method    string
+	Scheme    string
+	Host      string
+	// Path inclui a query string.
+	Path         string
+	Status       int
+	Duration     time.Duration
+	Upstream     string
+	RequestSize  int64
+	ResponseSize int64
+	// Headers são os cabeçalhos da requisição, com valores repetidos unidos
+	// por vírgula.
+	Headers map[string]string
+	Time    time.Time
+}
+
+// sensitiveHeaderParts marcam, em qualquer parte do nome, os cabeçalhos de
+// credenciais: Authorization, X-Auth-Token, X-Csrf-Token, X-Goog-Api-Key,
+// Cookie... Eles não vão para os registros: o valor fica RedactedValue, que
+// só mostra que o cabeçalho veio, e o replay não os reenvia.
+var sensitiveHeaderParts = []string{"token", "secret", "auth", "key", "session", "cookie", "password"}
+
+// traefikRedactedHeaders são os cabeçalhos sensíveis mais comuns, passados
+// pelo nome ao Traefik, que não aceita padrões, para não chegarem nem ao
+// access log em disco. Os demais são redigidos ao ler o arquivo.
+var traefikRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+	"X-Access-Token",
+	"X-Csrf-Token",
+	"X-Xsrf-Token",
+	"X-Amz-Security-Token",
+	"X-Goog-Api-Key",
+	"X-Session-Id",
+}
+
+// RedactedValue é o que o Traefik grava no lugar de um cabeçalho com redact.
+const RedactedValue = "REDACTED"
+
+// sensitiveHeader diz se o cabeçalho name carrega credenciais.
+func sensitiveHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range sensitiveHeaderParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders troca, em headers, os valores dos cabeçalhos sensíveis.
+func redactHeaders(headers map[string]string) map[string]string {
+	for name := range headers {
+		if sensitiveHeader(name) {
+			headers[name] = RedactedValue
+		}
+	}
+	return headers
+}
+
+// AccessHandler recebe cada requisição atendida. É chamado fora dos locks do
+// provider e não deve bloquear.
+type AccessHandler func(AccessRecord)
+
+const (
+	// accessLogPoll é o intervalo com que o access log do Traefik é relido.
+	accessLogPoll = 500 * time.Millisecond
+	// accessLogMaxSize é o tamanho a partir do qual o access log, já lido, é
+	// truncado enquanto o Traefik roda.
+	accessLogMaxSize = 10 << 20
+)
+
+// traefikAccessEntry são os campos usados de uma linha do access log do
+// Traefik em formato JSON. Os cabeçalhos vêm como "request_<Nome>".
+type traefikAccessEntry struct {
+	RouterName            string `json:"RouterName"`
+	ServiceAddr           string `json:"ServiceAddr"`
+	RequestMethod         string `json:"RequestMethod"`
+	RequestScheme         string `json:"RequestScheme"`
+	RequestHost           string `json:"RequestHost"`
+	RequestPath           string `json:"RequestPath"`
+	RequestContentSize    int64  `json:"RequestContentSize"`
+	DownstreamStatus      int    `json:"DownstreamStatus"`
+	DownstreamContentSize int64  `json:"DownstreamContentSize"`
+	Duration              int64  `json:"Duration"`
+	StartUTC              string `json:"StartUTC"`
+}
+
+// parseTraefikAccess converte uma linha do access log. O nome da rota é o do
+// router sem o provider ("@file") e sem o sufixo "-router" ou
+// "-secure-router" dado por generateConfig.
+func parseTraefikAccess(line []byte) (AccessRecord, bool) {
+	var entry traefikAccessEntry
+	if err := json.Unmarshal(line, &entry); err != nil || entry.RouterName == "" {
+		return AccessRecord{}, false
+	}
+
+	router, _, _ := strings.Cut(entry.RouterName, "@")
+	route, ok := strings.CutSuffix(router, "-secure-router")
+	if !ok {
+		if route, ok = strings.CutSuffix(router, "-router"); !ok {
+			return AccessRecord{}, false
+		}
+	}
+
+	var raw map[string]interface{}
+	_ = json.Unmarshal(line, &raw)
+	headers := map[string]string{}
+	for key, value := range raw {
+		if name, ok := strings.CutPrefix(key, "request_"); ok {
+			if s, ok := value.(string); ok {
+				headers[http.CanonicalHeaderKey(name)] = s
+			}
+		}
+	}
+
+	// ServiceURL é um objeto no JSON; os serviços gerados são sempre HTTP.
+	var upstream string
+	if entry.ServiceAddr != "" {
+		upstream = "http://" + entry.ServiceAddr
+	}
+
+	started, err := time.Parse(time.RFC3339Nano, entry.StartUTC)
+	if err != nil {
+		started = time.Now()
+	}
+
+	return AccessRecord{
+		Route:        route,
+		Method:       entry.RequestMethod,
+		Scheme:       entry.RequestScheme,
+		Host:         strings.ToLower(hostWithoutPort(entry.RequestHost)),
+		Path:         entry.RequestPath,
+		Status:       entry.DownstreamStatus,
+		Duration:     time.Duration(entry.Duration),
+		Upstream:     upstream,
+		RequestSize:  entry.RequestContentSize,
+		ResponseSize: entry.DownstreamContentSize,
+		Headers:      redactHeaders(headers),
+		Time:         started,
+	}, true
+}
+
+// tailFile entrega a onLine cada linha completa acrescentada a path, até ctx
+// terminar. O arquivo pode ainda não existir; se for recriado ou encolher, a
+// leitura volta ao início.
+func tailFile(ctx context.Context, path string, onLine func([]byte)) {
+	ticker := time.NewTicker(accessLogPoll)
+	defer ticker.Stop()
+
+	var file *os.File
+	var reader *bufio.Reader
+	var offset int64
+	var pending []byte
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	for {
+		if file == nil {
+			if f, err := os.Open(path); err == nil {
+				file, reader, offset, pending = f, bufio.NewReader(f), 0, nil
+			}
+		}
+
+		if file != nil {
+			if replaced(file, path, offset) {
+				file.Close()
+				file = nil
+				continue
+			}
+			for {
+				chunk, err := reader.ReadBytes('\n')
+				offset += int64(len(chunk))
+				pending = append(pending, chunk...)
+				if err != nil {
+					if err != io.EOF {
+						file.Close()
+						file = nil
+					}
+					break
+				}
+				onLine(pending)
+				pending = nil
+			}
+			if file != nil && offset >= accessLogMaxSize {
+				offset, pending = truncateAccessLog(file, reader, path, offset), nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// truncateAccessLog esvazia o access log já lido até o fim e volta a leitura
+// ao início. O Traefik abre o arquivo com O_APPEND, então as próximas linhas
+// começam do zero; uma linha escrita entre a última leitura e o truncamento
+// se perde. Devolve o novo offset.
+func truncateAccessLog(file *os.File, reader *bufio.Reader, path string, offset int64) int64 {
+	if err := os.Truncate(path, 0); err != nil {
+		return offset
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return offset
+	}
+	reader.Reset(file)
+	return 0
+}
+
+// replaced indica que path não é mais o arquivo aberto, ou que ele encolheu
+// desde a última leitura.
+func replaced(file *os.File, path string, offset int64) bool {
+	current, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	opened, err := file.Stat()
+	if err != nil {
+		return true
+	}
+	return !os.SameFile(opened, current) || current.Size() < offset
+}
+
+// flattenHeaders une os valores repetidos de cada cabeçalho por vírgula e
+// esconde os sensíveis.
+func flattenHeaders(header http.Header) map[string]string {
+	flat := make(map[string]string, len(header))
+	for name, values := range header {
+		flat[name] = strings.Join(values, ", ")
+	}
+	return redactHeaders(flat)
+}
+
+func hostWithoutPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
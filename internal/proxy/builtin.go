@@ -0,0 +1,478 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/pkg/logger"
+)
+
+// BuiltinProxy é o proxy reverso embutido no Relief. Roteia pelo Host para a
+// porta do projeto dentro do próprio processo: não baixa binário, não grava
+// arquivo de configuração e aplica mudanças de rota na hora. WebSockets
+// passam pelo httputil.ReverseProxy, que repassa o Upgrade.
+type BuiltinProxy struct {
+	httpPort  int
+	httpsPort int
+	certs     *CertManager
+	logger    *logger.Logger
+
+	mu        sync.RWMutex
+	byProject map[string][]*builtinRoute
+	// table são as rotas de todos os projetos na ordem em que são testadas.
+	table   []*builtinRoute
+	servers []*http.Server
+	running bool
+
+	tlsMu    sync.Mutex
+	tlsCache map[string]*tls.Certificate
+
+	proxy     *httputil.ReverseProxy
+	onRequest AccessHandler
+}
+
+type builtinRoute struct {
+	Route
+	projectID string
+	project   string
+	target    *url.URL
+}
+
+func (r *builtinRoute) match(host, path string) bool {
+	if !matchPath(r.PathPrefix, path) {
+		return false
+	}
+	for _, pattern := range r.Hosts {
+		if matchHost(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessRecord descreve a requisição atendida pela rota, como uma linha do
+// access log do Traefik. Redirecionamentos para HTTPS não têm upstream.
+func (r *builtinRoute) accessRecord(req *http.Request, host string, recorder *accessRecorder, started time.Time) AccessRecord {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	upstream := r.target.String()
+	if req.TLS == nil && r.HTTPSRedirect {
+		upstream = ""
+	}
+	return AccessRecord{
+		ProjectID:    r.projectID,
+		Route:        r.Name,
+		Method:       req.Method,
+		Scheme:       scheme,
+		Host:         host,
+		Path:         req.URL.RequestURI(),
+		Status:       recorder.status,
+		Duration:     time.Since(started),
+		Upstream:     upstream,
+		RequestSize:  max(req.ContentLength, 0),
+		ResponseSize: recorder.size,
+		Headers:      flattenHeaders(req.Header),
+		Time:         started,
+	}
+}
+
+// accessRecorder guarda o status e o tamanho da resposta. Unwrap deixa o
+// http.ResponseController do ReverseProxy chegar ao Flush e ao Hijack do
+// ResponseWriter original, usados em streaming e WebSockets.
+type accessRecorder struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+}
+
+func (w *accessRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		// Respostas 1xx (como 103 Early Hints) não são a resposta final; 101
+		// é, no upgrade para WebSocket.
+		w.wroteHeader = status >= 200 || status == http.StatusSwitchingProtocols
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessRecorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *accessRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type routeKey struct{}
+
+// builtinShutdownTimeout é quanto Stop espera as requisições em andamento.
+const builtinShutdownTimeout = 5 * time.Second
+
+func NewBuiltinProxy(httpPort, httpsPort int, certs *CertManager, log *logger.Logger) *BuiltinProxy {
+	p := &BuiltinProxy{
+		httpPort:  httpPort,
+		httpsPort: httpsPort,
+		certs:     certs,
+		logger:    log,
+		byProject: make(map[string][]*builtinRoute),
+		tlsCache:  make(map[string]*tls.Certificate),
+	}
+	p.proxy = &httputil.ReverseProxy{
+		Rewrite:        p.rewrite,
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler:   p.proxyError,
+	}
+	return p
+}
+
+func (p *BuiltinProxy) Name() string {
+	return ProviderBuiltin
+}
+
+func (p *BuiltinProxy) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		return fmt.Errorf("proxy já está rodando")
+	}
+
+	httpListener, err := net.Listen("tcp", ":"+strconv.Itoa(p.httpPort))
+	if err != nil {
+		return fmt.Errorf("erro ao abrir a porta HTTP %d: %w", p.httpPort, err)
+	}
+	servers := []*http.Server{p.newServer()}
+	go p.serve(servers[0], httpListener, false)
+
+	if p.httpsEnabled() {
+		httpsListener, err := net.Listen("tcp", ":"+strconv.Itoa(p.httpsPort))
+		if err != nil {
+			servers[0].Close()
+			return fmt.Errorf("erro ao abrir a porta HTTPS %d: %w", p.httpsPort, err)
+		}
+		server := p.newServer()
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: p.getCertificate,
+		}
+		servers = append(servers, server)
+		go p.serve(server, httpsListener, true)
+	}
+
+	p.servers = servers
+	p.running = true
+
+	go func() {
+		<-ctx.Done()
+		p.Stop()
+	}()
+
+	p.logger.Info("Proxy embutido iniciado", map[string]interface{}{
+		"http_port":  p.httpPort,
+		"https_port": p.httpsPort,
+		"https":      p.httpsEnabled(),
+	})
+	return nil
+}
+
+func (p *BuiltinProxy) newServer() *http.Server {
+	return &http.Server{
+		Handler:           p,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+}
+
+func (p *BuiltinProxy) serve(server *http.Server, listener net.Listener, secure bool) {
+	var err error
+	if secure {
+		err = server.ServeTLS(listener, "", "")
+	} else {
+		err = server.Serve(listener)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		p.logger.Warn("Proxy embutido encerrado com erro", map[string]interface{}{
+			"address": listener.Addr().String(),
+			"error":   err.Error(),
+		})
+		p.mu.Lock()
+		p.running = false
+		p.mu.Unlock()
+	}
+}
+
+func (p *BuiltinProxy) Stop() error {
+	p.mu.Lock()
+	servers := p.servers
+	p.servers = nil
+	p.running = false
+	p.mu.Unlock()
+
+	if len(servers) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), builtinShutdownTimeout)
+	defer cancel()
+	var errs []error
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			server.Close()
+			errs = append(errs, err)
+		}
+	}
+
+	p.logger.Info("Proxy embutido parado", nil)
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("erro ao parar o proxy: %w", err)
+	}
+	return nil
+}
+
+func (p *BuiltinProxy) Restart(ctx context.Context) error {
+	p.logger.Info("Reiniciando proxy embutido", nil)
+	_ = p.Stop()
+	return p.Start(ctx)
+}
+
+func (p *BuiltinProxy) IsRunning() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.running
+}
+
+func (p *BuiltinProxy) AddProject(project *domain.Project, routes []Route) error {
+	if len(routes) == 0 {
+		return fmt.Errorf("projeto não tem rotas configuradas")
+	}
+
+	compiled := make([]*builtinRoute, 0, len(routes))
+	for _, route := range routes {
+		target, err := url.Parse(fmt.Sprintf("http://localhost:%d", route.Port))
+		if err != nil {
+			return fmt.Errorf("erro ao montar destino da rota %s: %w", route.Name, err)
+		}
+		route.HTTPSRedirect = route.HTTPSRedirect && p.httpsEnabled()
+		compiled = append(compiled, &builtinRoute{Route: route, projectID: project.ID, project: project.Name, target: target})
+	}
+
+	p.mu.Lock()
+	p.byProject[project.ID] = compiled
+	p.rebuildTable()
+	p.mu.Unlock()
+
+	p.logger.Info("Projeto adicionado ao proxy", map[string]interface{}{
+		"project": project.Name,
+		"domain":  project.Domain,
+		"port":    project.Port,
+		"routes":  len(routes),
+	})
+	return nil
+}
+
+func (p *BuiltinProxy) OnRequest(handler AccessHandler) {
+	p.mu.Lock()
+	p.onRequest = handler
+	p.mu.Unlock()
+}
+
+func (p *BuiltinProxy) RemoveProject(projectID string) error {
+	p.mu.Lock()
+	delete(p.byProject, projectID)
+	p.rebuildTable()
+	p.mu.Unlock()
+
+	p.logger.Info("Projeto removido do proxy", map[string]interface{}{
+		"project_id": projectID,
+	})
+	return nil
+}
+
+// rebuildTable monta uma tabela nova em vez de alterar a atual, que pode
+// estar sendo lida por requisições em andamento.
+func (p *BuiltinProxy) rebuildTable() {
+	table := []*builtinRoute{}
+	for _, routes := range p.byProject {
+		table = append(table, routes...)
+	}
+	sort.SliceStable(table, func(i, j int) bool { return table[i].Name < table[j].Name })
+	sortRoutes(table)
+	p.table = table
+}
+
+func (p *BuiltinProxy) lookup(host, path string) *builtinRoute {
+	p.mu.RLock()
+	table := p.table
+	p.mu.RUnlock()
+
+	for _, route := range table {
+		if route.match(host, path) {
+			return route
+		}
+	}
+	return nil
+}
+
+func (p *BuiltinProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := requestHost(r)
+
+	route := p.lookup(host, r.URL.Path)
+	if route == nil {
+		http.Error(w, fmt.Sprintf("Relief: nenhum projeto publicado em %s%s", host, r.URL.Path), http.StatusNotFound)
+		return
+	}
+
+	p.mu.RLock()
+	handler := p.onRequest
+	p.mu.RUnlock()
+	if handler != nil {
+		recorder := &accessRecorder{ResponseWriter: w, status: http.StatusOK}
+		started := time.Now()
+		defer func() {
+			handler(route.accessRecord(r, host, recorder, started))
+		}()
+		w = recorder
+	}
+
+	if r.TLS == nil && route.HTTPSRedirect {
+		target := "https://" + host
+		if p.httpsPort != 443 {
+			target += ":" + strconv.Itoa(p.httpsPort)
+		}
+		http.Redirect(w, r, target+r.URL.RequestURI(), http.StatusTemporaryRedirect)
+		return
+	}
+
+	p.proxy.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, route)))
+}
+
+// rewrite mantém o Host original, como o passHostHeader do Traefik, e
+// acrescenta os cabeçalhos X-Forwarded-*. Com StripPrefix, o prefixo sai do
+// caminho e vai para X-Forwarded-Prefix.
+func (p *BuiltinProxy) rewrite(pr *httputil.ProxyRequest) {
+	route := pr.In.Context().Value(routeKey{}).(*builtinRoute)
+
+	if route.StripPrefix {
+		pr.Out.URL.Path = stripPath(route.PathPrefix, pr.Out.URL.Path)
+		if pr.Out.URL.RawPath != "" {
+			pr.Out.URL.RawPath = stripPath(route.PathPrefix, pr.Out.URL.RawPath)
+		}
+		pr.Out.Header.Set("X-Forwarded-Prefix", route.PathPrefix)
+	}
+
+	pr.SetURL(route.target)
+	pr.Out.Host = pr.In.Host
+	pr.SetXForwarded()
+	setHeaders(pr.Out.Header, route.RequestHeaders)
+}
+
+func (p *BuiltinProxy) modifyResponse(resp *http.Response) error {
+	if route, ok := resp.Request.Context().Value(routeKey{}).(*builtinRoute); ok {
+		setHeaders(resp.Header, route.ResponseHeaders)
+	}
+	return nil
+}
+
+// setHeaders segue o middleware headers do Traefik: valor vazio remove o
+// cabeçalho.
+func setHeaders(header http.Header, values map[string]string) {
+	for name, value := range values {
+		if value == "" {
+			header.Del(name)
+		} else {
+			header.Set(name, value)
+		}
+	}
+}
+
+func (p *BuiltinProxy) proxyError(w http.ResponseWriter, r *http.Request, err error) {
+	route, _ := r.Context().Value(routeKey{}).(*builtinRoute)
+	if route == nil || errors.Is(err, context.Canceled) {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	p.logger.Debug("Erro ao encaminhar requisição", map[string]interface{}{
+		"project": route.project,
+		"path":    r.URL.Path,
+		"error":   err.Error(),
+	})
+	http.Error(w, fmt.Sprintf("Relief: %s não respondeu em %s: %v", route.project, route.target.Host, err), http.StatusBadGateway)
+}
+
+func (p *BuiltinProxy) httpsEnabled() bool {
+	return p.certs != nil && p.httpsPort > 0
+}
+
+// getCertificate escolhe o certificado pelo SNI. Nomes sem rota recebem o
+// certificado de defaultCertDomain, para não emitir certificados para
+// qualquer nome que chegue.
+func (p *BuiltinProxy) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	name := p.certificateName(strings.ToLower(strings.TrimSuffix(hello.ServerName, ".")))
+
+	p.tlsMu.Lock()
+	defer p.tlsMu.Unlock()
+
+	if cert, ok := p.tlsCache[name]; ok && time.Until(cert.Leaf.NotAfter) > leafRenewBefore {
+		return cert, nil
+	}
+
+	certFile, keyFile, err := p.certs.Certificate(name)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar certificado de %s: %w", name, err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, fmt.Errorf("erro ao carregar certificado de %s: %w", name, err)
+		}
+	}
+	p.tlsCache[name] = &cert
+	return &cert, nil
+}
+
+// certificateName é o host da rota que atende serverName, exato ou curinga,
+// ou defaultCertDomain.
+func (p *BuiltinProxy) certificateName(serverName string) string {
+	p.mu.RLock()
+	table := p.table
+	p.mu.RUnlock()
+
+	for _, route := range table {
+		for _, pattern := range route.Hosts {
+			if matchHost(pattern, serverName) {
+				return pattern
+			}
+		}
+	}
+	return defaultCertDomain
+}
+
+// requestHost é o Host da requisição sem a porta, em minúsculas.
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
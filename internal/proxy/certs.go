@@ -0,0 +1,332 @@
+package proxy
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Maycon-Santos/relief/pkg/fileutil"
+	"github.com/Maycon-Santos/relief/pkg/logger"
+)
+
+const (
+	caCertFile = "ca.pem"
+	caKeyFile  = "ca-key.pem"
+
+	caValidity = 10 * 365 * 24 * time.Hour
+	// Navegadores recusam certificados de servidor com mais de 398 dias.
+	leafValidity = 397 * 24 * time.Hour
+	// leafRenewBefore é a antecedência com que um certificado é reemitido.
+	leafRenewBefore = 30 * 24 * time.Hour
+)
+
+// CertManager mantém a CA local do Relief em ~/.relief/certs e emite, sob
+// demanda, os certificados dos domínios dos projetos assinados por ela.
+type CertManager struct {
+	dir    string
+	mu     sync.Mutex
+	ca     *x509.Certificate
+	caKey  crypto.Signer
+	logger *logger.Logger
+}
+
+// CAInfo descreve a CA local e se ela já está no repositório de confiança do
+// sistema.
+type CAInfo struct {
+	Path        string `json:"path"`
+	Subject     string `json:"subject"`
+	Fingerprint string `json:"fingerprint"`
+	NotAfter    string `json:"not_after"`
+	Trusted     bool   `json:"trusted"`
+}
+
+func NewCertManager(log *logger.Logger) (*CertManager, error) {
+	dir, err := fileutil.GetReliefSubDir("certs")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de certificados: %w", err)
+	}
+	return &CertManager{dir: dir, logger: log}, nil
+}
+
+// CAPath é o certificado da CA em PEM, o arquivo a importar em navegadores ou
+// ferramentas que não usam o repositório do sistema.
+func (c *CertManager) CAPath() string {
+	return filepath.Join(c.dir, caCertFile)
+}
+
+// Info carrega (ou cria) a CA e a descreve.
+func (c *CertManager) Info() (*CAInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.loadCA(); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(c.ca.Raw)
+	return &CAInfo{
+		Path:        c.CAPath(),
+		Subject:     c.ca.Subject.CommonName,
+		Fingerprint: strings.ToUpper(hex.EncodeToString(sum[:])),
+		NotAfter:    c.ca.NotAfter.Format(time.RFC3339),
+		Trusted:     c.isTrusted(),
+	}, nil
+}
+
+// Certificate retorna os arquivos de certificado e chave do domínio, emitindo
+// um novo quando ainda não existe, está perto de vencer ou foi assinado por
+// outra CA.
+func (c *CertManager) Certificate(domain string) (certFile, keyFile string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.loadCA(); err != nil {
+		return "", "", err
+	}
+
+	name := strings.ReplaceAll(strings.ToLower(domain), "*", "_wildcard")
+	certFile = filepath.Join(c.dir, name+".pem")
+	keyFile = filepath.Join(c.dir, name+"-key.pem")
+
+	if c.leafValid(certFile, keyFile, domain) {
+		return certFile, keyFile, nil
+	}
+	if err := c.issue(domain, certFile, keyFile); err != nil {
+		return "", "", err
+	}
+
+	c.logger.Info("Certificado emitido pela CA local", map[string]interface{}{
+		"domain": domain,
+		"path":   certFile,
+	})
+	return certFile, keyFile, nil
+}
+
+func (c *CertManager) loadCA() error {
+	if c.ca != nil {
+		return nil
+	}
+
+	certPath := filepath.Join(c.dir, caCertFile)
+	keyPath := filepath.Join(c.dir, caKeyFile)
+	if !fileutil.Exists(certPath) || !fileutil.Exists(keyPath) {
+		return c.createCA(certPath, keyPath)
+	}
+
+	cert, err := readCertificate(certPath)
+	if err != nil {
+		return fmt.Errorf("erro ao ler a CA local: %w", err)
+	}
+	key, err := readPrivateKey(keyPath)
+	if err != nil {
+		return fmt.Errorf("erro ao ler a chave da CA local: %w", err)
+	}
+	c.ca, c.caKey = cert, key
+	return nil
+}
+
+func (c *CertManager) createCA(certPath, keyPath string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar chave da CA: %w", err)
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization:       []string{"Relief"},
+			OrganizationalUnit: []string{caOwner()},
+			CommonName:         "Relief Local CA " + caOwner(),
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return fmt.Errorf("erro ao criar a CA local: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return fmt.Errorf("erro ao criar a CA local: %w", err)
+	}
+
+	if err := writeKey(keyPath, key); err != nil {
+		return err
+	}
+	if err := writeCertificate(certPath, der); err != nil {
+		return err
+	}
+
+	c.ca, c.caKey = cert, key
+	c.logger.Info("CA local criada", map[string]interface{}{
+		"path": certPath,
+	})
+	return nil
+}
+
+func (c *CertManager) leafValid(certFile, keyFile, domain string) bool {
+	if !fileutil.Exists(keyFile) {
+		return false
+	}
+	cert, err := readCertificate(certFile)
+	if err != nil {
+		return false
+	}
+	if time.Until(cert.NotAfter) < leafRenewBefore {
+		return false
+	}
+	if cert.CheckSignatureFrom(c.ca) != nil {
+		return false
+	}
+	for _, name := range cert.DNSNames {
+		if strings.EqualFold(name, domain) {
+			return true
+		}
+	}
+	ip := net.ParseIP(domain)
+	for _, addr := range cert.IPAddresses {
+		if ip != nil && addr.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CertManager) issue(domain, certFile, keyFile string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar chave do certificado: %w", err)
+	}
+
+	serial, err := randomSerial()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization:       []string{"Relief"},
+			OrganizationalUnit: []string{caOwner()},
+			CommonName:         domain,
+		},
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(leafValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(domain); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{domain}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, c.ca, key.Public(), c.caKey)
+	if err != nil {
+		return fmt.Errorf("erro ao emitir certificado para %s: %w", domain, err)
+	}
+
+	if err := writeKey(keyFile, key); err != nil {
+		return err
+	}
+	return writeCertificate(certFile, der)
+}
+
+func randomSerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao gerar número de série: %w", err)
+	}
+	return serial, nil
+}
+
+// caOwner identifica a máquina e o usuário no nome da CA, para distingui-la
+// no repositório de confiança de quem usa o Relief em mais de uma conta.
+func caOwner() string {
+	name := "relief"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+	return name
+}
+
+func readCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%s não contém um certificado PEM", path)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func readPrivateKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s não contém uma chave PEM", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%s: tipo de chave não suportado", path)
+	}
+	return signer, nil
+}
+
+func writeCertificate(path string, der []byte) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("erro ao gravar certificado: %w", err)
+	}
+	return nil
+}
+
+func writeKey(path string, key crypto.Signer) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar chave: %w", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("erro ao gravar chave: %w", err)
+	}
+	return nil
+}
@@ -13,18 +13,28 @@ type TraefikService struct {
 
 type TraefikConfig struct {
 	HTTP HTTPConfig `yaml:"http"`
+	TLS  *TLSConfig `yaml:"tls,omitempty"`
 }
 
 type HTTPConfig struct {
-	Routers  map[string]Router  `yaml:"routers"`
-	Services map[string]Service `yaml:"services"`
+	Routers     map[string]Router     `yaml:"routers"`
+	Services    map[string]Service    `yaml:"services"`
+	Middlewares map[string]Middleware `yaml:"middlewares,omitempty"`
 }
 
 type Router struct {
-	Rule    string `yaml:"rule"`
-	Service string `yaml:"service"`
+	Rule        string     `yaml:"rule"`
+	Service     string     `yaml:"service"`
+	EntryPoints []string   `yaml:"entryPoints,omitempty"`
+	Middlewares []string   `yaml:"middlewares,omitempty"`
+	Priority    int        `yaml:"priority,omitempty"`
+	TLS         *RouterTLS `yaml:"tls,omitempty"`
 }
 
+// RouterTLS vazio faz o router responder com TLS usando os certificados do
+// store padrão.
+type RouterTLS struct{}
+
 type Service struct {
 	LoadBalancer LoadBalancer `yaml:"loadBalancer"`
 }
@@ -36,3 +46,41 @@ type LoadBalancer struct {
 type Server struct {
 	URL string `yaml:"url"`
 }
+
+type Middleware struct {
+	RedirectScheme *RedirectScheme `yaml:"redirectScheme,omitempty"`
+	StripPrefix    *StripPrefix    `yaml:"stripPrefix,omitempty"`
+	Headers        *Headers        `yaml:"headers,omitempty"`
+}
+
+type RedirectScheme struct {
+	Scheme    string `yaml:"scheme"`
+	Port      string `yaml:"port,omitempty"`
+	Permanent bool   `yaml:"permanent"`
+}
+
+type StripPrefix struct {
+	Prefixes []string `yaml:"prefixes"`
+}
+
+// Headers acrescenta cabeçalhos à requisição e à resposta; valor vazio
+// remove o cabeçalho.
+type Headers struct {
+	CustomRequestHeaders  map[string]string `yaml:"customRequestHeaders,omitempty"`
+	CustomResponseHeaders map[string]string `yaml:"customResponseHeaders,omitempty"`
+}
+
+type TLSConfig struct {
+	Certificates []Certificate       `yaml:"certificates,omitempty"`
+	Stores       map[string]TLSStore `yaml:"stores,omitempty"`
+}
+
+type Certificate struct {
+	CertFile string   `yaml:"certFile"`
+	KeyFile  string   `yaml:"keyFile"`
+	Stores   []string `yaml:"stores,omitempty"`
+}
+
+type TLSStore struct {
+	DefaultCertificate *Certificate `yaml:"defaultCertificate,omitempty"`
+}
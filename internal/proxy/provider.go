@@ -0,0 +1,47 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/pkg/logger"
+)
+
+const (
+	ProviderTraefik = "traefik"
+	ProviderBuiltin = "builtin"
+)
+
+// Provider publica os domínios dos projetos em execução, em HTTP e, quando
+// há CertManager, em HTTPS.
+type Provider interface {
+	Name() string
+	Start(ctx context.Context) error
+	Stop() error
+	Restart(ctx context.Context) error
+	IsRunning() bool
+	// AddProject publica as rotas do projeto, substituindo as anteriores.
+	AddProject(project *domain.Project, routes []Route) error
+	RemoveProject(projectID string) error
+	// OnRequest registra quem recebe as requisições atendidas nas rotas dos
+	// projetos. Deve ser chamado antes de Start.
+	OnRequest(handler AccessHandler)
+}
+
+// NewProvider cria o provider escolhido em proxy.provider na configuração.
+// Vazio é o Traefik.
+func NewProvider(name string, httpPort, httpsPort int, certs *CertManager, log *logger.Logger) (Provider, error) {
+	switch name {
+	case "", ProviderTraefik:
+		traefik, err := NewTraefikManager(httpPort, httpsPort, certs, log)
+		if err != nil {
+			return nil, err
+		}
+		return traefik, nil
+	case ProviderBuiltin:
+		return NewBuiltinProxy(httpPort, httpsPort, certs, log), nil
+	default:
+		return nil, fmt.Errorf("provider de proxy desconhecido: %s", name)
+	}
+}
@@ -0,0 +1,92 @@
+package proxy
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Route é uma rota publicada no proxy: hosts e, opcionalmente, um prefixo de
+// caminho que levam a uma porta local.
+type Route struct {
+	// Name identifica a rota entre as de todos os projetos; o Traefik o usa
+	// no nome dos routers, services e middlewares.
+	Name string
+	// Hosts aceita nomes exatos e curingas "*.dominio", que valem para um
+	// nível de subdomínio.
+	Hosts       []string
+	PathPrefix  string
+	StripPrefix bool
+	Port        int
+	// Priority desempata rotas que atendem a mesma requisição; a maior vence.
+	// Zero usa o tamanho da regra, como o Traefik.
+	Priority        int
+	RequestHeaders  map[string]string
+	ResponseHeaders map[string]string
+	HTTPSRedirect   bool
+}
+
+// rule é a regra do router do Traefik v3 para a rota.
+func (r Route) rule() string {
+	hosts := make([]string, 0, len(r.Hosts))
+	for _, host := range r.Hosts {
+		if suffix, ok := strings.CutPrefix(host, "*."); ok {
+			hosts = append(hosts, fmt.Sprintf("HostRegexp(`^[^.]+\\.%s$`)", regexp.QuoteMeta(suffix)))
+		} else {
+			hosts = append(hosts, fmt.Sprintf("Host(`%s`)", host))
+		}
+	}
+
+	rule := strings.Join(hosts, " || ")
+	if r.PathPrefix == "" {
+		return rule
+	}
+	if len(hosts) > 1 {
+		rule = "(" + rule + ")"
+	}
+	return fmt.Sprintf("%s && PathPrefix(`%s`)", rule, r.PathPrefix)
+}
+
+// matchHost indica se host (sem porta, em minúsculas) é atendido por
+// pattern, um nome exato ou um curinga de um nível.
+func matchHost(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		label, rest, found := strings.Cut(host, ".")
+		return found && label != "" && rest == suffix
+	}
+	return pattern == host
+}
+
+// matchPath segue o PathPrefix do Traefik: comparação simples de prefixo.
+func matchPath(prefix, path string) bool {
+	return prefix == "" || strings.HasPrefix(path, prefix)
+}
+
+// priority segue o Traefik: sem prioridade explícita, vale o tamanho da
+// regra, de modo que regras mais específicas (com prefixo de caminho, mais
+// longo) são testadas antes.
+func (r Route) priority() int {
+	if r.Priority > 0 {
+		return r.Priority
+	}
+	return len(r.rule())
+}
+
+// sortRoutes ordena as rotas na ordem em que são testadas, da maior
+// prioridade para a menor.
+func sortRoutes(routes []*builtinRoute) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].priority() > routes[j].priority()
+	})
+}
+
+// stripPath remove o prefixo do caminho como o stripPrefix do Traefik,
+// mantendo a barra inicial.
+func stripPath(prefix, path string) string {
+	path = strings.TrimPrefix(path, prefix)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
@@ -0,0 +1,296 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Maycon-Santos/relief/pkg/logger"
+)
+
+const (
+	// StatusProjectHeader leva o ID do projeto nas rotas estacionadas na
+	// página de status. O proxy sobrescreve o valor enviado pelo cliente.
+	StatusProjectHeader = "X-Relief-Project"
+	// StatusPageHeader marca as respostas da página de status; quando ele
+	// some, o projeto voltou a atender e a página recarrega.
+	StatusPageHeader = "X-Relief-Placeholder"
+
+	statusProbeHeader  = "X-Relief-Probe"
+	statusActionHeader = "X-Relief-Action"
+
+	statusPollInterval = time.Second
+)
+
+// ProjectState é o que a página de status mostra de um projeto. Label é
+// preenchido pela própria página.
+type ProjectState struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Label  string `json:"label"`
+	Error  string `json:"error,omitempty"`
+}
+
+// StatusController é quem informa o estado dos projetos e os inicia pela
+// página de status.
+type StatusController interface {
+	ProjectState(id string) (ProjectState, error)
+	StartProject(id string) error
+}
+
+// StatusPages atende os domínios dos projetos que não estão prontos. As rotas
+// continuam no proxy, apontadas para este servidor com StatusProjectHeader,
+// e quem abre o domínio vê o status, o último erro e um botão para iniciar. A
+// página consulta o status a cada segundo e recarrega quando a resposta
+// deixa de vir daqui.
+type StatusPages struct {
+	controller StatusController
+	logger     *logger.Logger
+	server     *http.Server
+	port       int
+}
+
+func NewStatusPages(controller StatusController, log *logger.Logger) *StatusPages {
+	s := &StatusPages{
+		controller: controller,
+		logger:     log,
+	}
+	s.server = &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Start escuta numa porta livre de loopback.
+func (s *StatusPages) Start() error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("erro ao abrir porta da página de status: %w", err)
+	}
+	s.port = listener.Addr().(*net.TCPAddr).Port
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Warn("Página de status encerrada com erro", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}()
+	return nil
+}
+
+// Port é a porta de destino das rotas estacionadas.
+func (s *StatusPages) Port() int {
+	return s.port
+}
+
+func (s *StatusPages) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), builtinShutdownTimeout)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+func (s *StatusPages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(StatusPageHeader, "1")
+	w.Header().Set("Cache-Control", "no-store")
+
+	id := r.Header.Get(StatusProjectHeader)
+	if id == "" {
+		http.Error(w, "Relief: rota sem projeto", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodPost && r.Header.Get(statusActionHeader) == "start" {
+		s.start(w, r, id)
+		return
+	}
+
+	state, err := s.state(id)
+	if err != nil {
+		http.Error(w, "Relief: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if r.Header.Get(statusProbeHeader) != "" {
+		writeState(w, http.StatusOK, state)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Retry-After", "1")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	if err := statusPage.Execute(w, statusPageData{
+		ProjectState: state,
+		Host:         hostWithoutPort(r.Host),
+		PollInterval: statusPollInterval.Milliseconds(),
+		ProbeHeader:  statusProbeHeader,
+		PageHeader:   StatusPageHeader,
+		ActionHeader: statusActionHeader,
+	}); err != nil {
+		s.logger.Debug("Erro ao gerar página de status", map[string]interface{}{
+			"project": state.Name,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// start inicia o projeto pelo botão da página. O cabeçalho de ação obriga o
+// navegador a fazer preflight em requisições de outra origem, que este
+// servidor não autoriza; Origin, quando vem, tem de ser o próprio domínio.
+// O início roda em segundo plano e a resposta sai na hora como "starting";
+// um erro aparece na consulta seguinte, no último erro do projeto.
+func (s *StatusPages) start(w http.ResponseWriter, r *http.Request, id string) {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+			http.Error(w, "Relief: origem não permitida", http.StatusForbidden)
+			return
+		}
+	}
+
+	state, err := s.state(id)
+	if err != nil {
+		http.Error(w, "Relief: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	go func() {
+		if err := s.controller.StartProject(id); err != nil {
+			s.logger.Warn("Erro ao iniciar projeto pela página de status", map[string]interface{}{
+				"project": state.Name,
+				"error":   err.Error(),
+			})
+		}
+	}()
+
+	if state.Status != "running" {
+		state.Status = "starting"
+		state.Label = statusLabels[state.Status]
+		state.Error = ""
+	}
+	writeState(w, http.StatusAccepted, state)
+}
+
+func (s *StatusPages) state(id string) (ProjectState, error) {
+	state, err := s.controller.ProjectState(id)
+	if err != nil {
+		return state, err
+	}
+	state.Label = statusLabels[state.Status]
+	if state.Label == "" {
+		state.Label = state.Status
+	}
+	return state, nil
+}
+
+func writeState(w http.ResponseWriter, status int, state ProjectState) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(state)
+}
+
+type statusPageData struct {
+	ProjectState
+	Host         string
+	PollInterval int64
+	ProbeHeader  string
+	PageHeader   string
+	ActionHeader string
+}
+
+// statusLabels são os nomes dos status na página, como no app.
+var statusLabels = map[string]string{
+	"stopped":    "Parado",
+	"starting":   "Iniciando",
+	"running":    "Rodando",
+	"error":      "Com erro",
+	"crash_loop": "Crash loop",
+}
+
+var statusPage = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Name}} · Relief</title>
+<style>
+	body { margin: 0; min-height: 100vh; display: flex; align-items: center; justify-content: center;
+		background: #09090b; color: #e4e4e7; font-family: system-ui, -apple-system, sans-serif; }
+	main { width: min(32rem, 90vw); padding: 2rem; border: 1px solid #27272a; border-radius: 0.75rem; background: #18181b; }
+	h1 { margin: 0 0 0.25rem; font-size: 1.25rem; }
+	.host { margin: 0 0 1.5rem; color: #a1a1aa; font-size: 0.875rem; font-family: ui-monospace, monospace; }
+	.badge { display: inline-flex; align-items: center; gap: 0.4rem; padding: 0.2rem 0.6rem; border-radius: 999px;
+		font-size: 0.8rem; border: 1px solid #3f3f46; color: #a1a1aa; }
+	.badge::before { content: ""; width: 0.4rem; height: 0.4rem; border-radius: 50%; background: currentColor; }
+	.starting, .running { color: #fbbf24; border-color: #78350f; }
+	.error, .crash_loop { color: #f87171; border-color: #7f1d1d; }
+	pre { margin: 1rem 0 0; padding: 0.75rem; border-radius: 0.5rem; background: #09090b; color: #fca5a5;
+		font-size: 0.8rem; white-space: pre-wrap; word-break: break-word; }
+	pre:empty { display: none; }
+	button { margin-top: 1.5rem; padding: 0.5rem 1rem; border: 0; border-radius: 0.5rem; background: #2563eb;
+		color: #fff; font-size: 0.875rem; cursor: pointer; }
+	button:disabled { opacity: 0.5; cursor: default; }
+	.hint { margin: 1rem 0 0; color: #71717a; font-size: 0.8rem; }
+</style>
+</head>
+<body>
+<main>
+	<h1>{{.Name}}</h1>
+	<p class="host">{{.Host}}</p>
+	<span id="status" class="badge {{.Status}}">{{.Label}}</span>
+	<pre id="error">{{.Error}}</pre>
+	<button id="start" type="button">Iniciar</button>
+	<p class="hint">A página recarrega sozinha quando o projeto estiver pronto.</p>
+</main>
+<script>
+	const badge = document.getElementById("status");
+	const error = document.getElementById("error");
+	const start = document.getElementById("start");
+
+	function render(state) {
+		badge.className = "badge " + state.status;
+		badge.textContent = state.label;
+		error.textContent = state.error || "";
+		start.disabled = state.status === "starting" || state.status === "running";
+	}
+
+	async function poll() {
+		try {
+			const resp = await fetch(location.href, { headers: { "{{.ProbeHeader}}": "1" }, cache: "no-store" });
+			if (!resp.headers.has("{{.PageHeader}}")) {
+				location.reload();
+				return;
+			}
+			if (resp.ok) render(await resp.json());
+		} catch (err) {}
+		setTimeout(poll, {{.PollInterval}});
+	}
+
+	start.addEventListener("click", async () => {
+		start.disabled = true;
+		try {
+			const resp = await fetch(location.href, { method: "POST", headers: { "{{.ActionHeader}}": "start" } });
+			if (resp.ok) {
+				render(await resp.json());
+			} else {
+				error.textContent = await resp.text();
+				start.disabled = false;
+			}
+		} catch (err) {
+			error.textContent = String(err);
+			start.disabled = false;
+		}
+	});
+
+	render({{.ProjectState}});
+	setTimeout(poll, {{.PollInterval}});
+</script>
+</body>
+</html>
+`))
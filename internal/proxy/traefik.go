@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
@@ -21,6 +22,19 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	entryPointWeb       = "web"
+	entryPointWebSecure = "websecure"
+
+	// httpsRedirectMiddleware é aplicado ao router HTTP dos projetos que pedem
+	// https_redirect.
+	httpsRedirectMiddleware = "relief-https-redirect"
+
+	// defaultCertDomain é o certificado servido quando o SNI não corresponde a
+	// nenhum projeto, no lugar do autoassinado do Traefik.
+	defaultCertDomain = "localhost"
+)
+
 type TraefikManager struct {
 	configPath string
 	binaryPath string
@@ -31,9 +45,16 @@ type TraefikManager struct {
 	mu         sync.RWMutex
 	logger     *logger.Logger
 	projects   map[string]*domain.Project
+	routes     map[string][]Route
+	certs      *CertManager
+	onRequest  AccessHandler
+	stopTail   context.CancelFunc
 }
 
-func NewTraefikManager(httpPort, httpsPort int, log *logger.Logger) (*TraefikManager, error) {
+// NewTraefikManager cria o gerenciador do Traefik. Com certs, o entrypoint
+// websecure é aberto em httpsPort com certificados da CA local; sem ele, só
+// HTTP.
+func NewTraefikManager(httpPort, httpsPort int, certs *CertManager, log *logger.Logger) (*TraefikManager, error) {
 	traefikDir, err := fileutil.GetReliefSubDir("traefik")
 	if err != nil {
 		return nil, fmt.Errorf("erro ao criar diretório traefik: %w", err)
@@ -55,9 +76,15 @@ func NewTraefikManager(httpPort, httpsPort int, log *logger.Logger) (*TraefikMan
 		running:    false,
 		logger:     log,
 		projects:   make(map[string]*domain.Project),
+		routes:     make(map[string][]Route),
+		certs:      certs,
 	}, nil
 }
 
+func (t *TraefikManager) Name() string {
+	return ProviderTraefik
+}
+
 func (t *TraefikManager) Start(ctx context.Context) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -79,14 +106,37 @@ func (t *TraefikManager) Start(ctx context.Context) error {
 
 	logDir := filepath.Dir(t.configPath)
 	logFile := filepath.Join(logDir, "traefik.log")
+	accessLogFile := t.AccessLogPath()
+
+	// O access log recomeça a cada start: as linhas de execuções anteriores
+	// já foram lidas ou não interessam mais.
+	if err := os.Remove(accessLogFile); err != nil && !os.IsNotExist(err) {
+		t.logger.Warn("Erro ao limpar o access log do Traefik", map[string]interface{}{
+			"path":  accessLogFile,
+			"error": err.Error(),
+		})
+	}
 
-	cmd := exec.CommandContext(ctx, t.binaryPath,
-		"--providers.file.filename="+t.configPath,
-		"--entrypoints.web.address=:"+fmt.Sprintf("%d", t.httpPort),
+	args := []string{
+		"--providers.file.filename=" + t.configPath,
+		"--entrypoints.web.address=:" + fmt.Sprintf("%d", t.httpPort),
+	}
+	if t.httpsEnabled() {
+		args = append(args, "--entrypoints.websecure.address=:"+fmt.Sprintf("%d", t.httpsPort))
+	}
+	args = append(args,
 		"--log.level=INFO",
 		"--log.filepath="+logFile,
-		"--accesslog=false",
+		"--accesslog=true",
+		"--accesslog.format=json",
+		"--accesslog.filepath="+accessLogFile,
+		"--accesslog.fields.headers.defaultmode=keep",
 	)
+	for _, name := range traefikRedactedHeaders {
+		args = append(args, "--accesslog.fields.headers.names."+name+"=redact")
+	}
+
+	cmd := exec.CommandContext(ctx, t.binaryPath, args...)
 
 	cmd.Stdout = nil
 	cmd.Stderr = nil
@@ -97,9 +147,16 @@ func (t *TraefikManager) Start(ctx context.Context) error {
 
 	t.process = cmd
 
+	if t.onRequest != nil {
+		tailCtx, cancel := context.WithCancel(ctx)
+		t.stopTail = cancel
+		go tailFile(tailCtx, accessLogFile, t.readAccessLine)
+	}
+
 	t.logger.Info("Traefik iniciado", map[string]interface{}{
 		"http_port":  t.httpPort,
 		"https_port": t.httpsPort,
+		"https":      t.httpsEnabled(),
 		"pid":        cmd.Process.Pid,
 		"config":     t.configPath,
 	})
@@ -116,6 +173,11 @@ func (t *TraefikManager) Stop() error {
 		return nil
 	}
 
+	if t.stopTail != nil {
+		t.stopTail()
+		t.stopTail = nil
+	}
+
 	if t.process != nil && t.process.Process != nil {
 		if err := t.process.Process.Kill(); err != nil {
 			return fmt.Errorf("erro ao parar traefik: %w", err)
@@ -128,15 +190,18 @@ func (t *TraefikManager) Stop() error {
 	return nil
 }
 
-func (t *TraefikManager) AddProject(project *domain.Project) error {
+// AddProject publica (ou substitui) as rotas do projeto em HTTP e, com HTTPS
+// habilitado, em HTTPS.
+func (t *TraefikManager) AddProject(project *domain.Project, routes []Route) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	if project.Domain == "" {
-		return fmt.Errorf("projeto não tem domínio configurado")
+	if len(routes) == 0 {
+		return fmt.Errorf("projeto não tem rotas configuradas")
 	}
 
 	t.projects[project.ID] = project
+	t.routes[project.ID] = routes
 
 	if err := t.generateConfig(); err != nil {
 		return fmt.Errorf("erro ao regenerar configuração: %w", err)
@@ -146,6 +211,7 @@ func (t *TraefikManager) AddProject(project *domain.Project) error {
 		"project": project.Name,
 		"domain":  project.Domain,
 		"port":    project.Port,
+		"routes":  len(routes),
 	})
 
 	return nil
@@ -156,6 +222,7 @@ func (t *TraefikManager) RemoveProject(projectID string) error {
 	defer t.mu.Unlock()
 
 	delete(t.projects, projectID)
+	delete(t.routes, projectID)
 
 	if err := t.generateConfig(); err != nil {
 		return fmt.Errorf("erro ao regenerar configuração: %w", err)
@@ -168,34 +235,126 @@ func (t *TraefikManager) RemoveProject(projectID string) error {
 	return nil
 }
 
+func (t *TraefikManager) OnRequest(handler AccessHandler) {
+	t.mu.Lock()
+	t.onRequest = handler
+	t.mu.Unlock()
+}
+
+// AccessLogPath é o access log em JSON que o Relief lê para registrar as
+// requisições dos projetos.
+func (t *TraefikManager) AccessLogPath() string {
+	return filepath.Join(filepath.Dir(t.configPath), "access.log")
+}
+
+// readAccessLine atribui a linha do access log ao projeto dono da rota. Linhas
+// de rotas já removidas são descartadas.
+func (t *TraefikManager) readAccessLine(line []byte) {
+	record, ok := parseTraefikAccess(line)
+	if !ok {
+		return
+	}
+
+	t.mu.RLock()
+	handler := t.onRequest
+	for projectID, routes := range t.routes {
+		for _, route := range routes {
+			if route.Name == record.Route {
+				record.ProjectID = projectID
+			}
+		}
+	}
+	t.mu.RUnlock()
+
+	if handler != nil && record.ProjectID != "" {
+		handler(record)
+	}
+}
+
 func (t *TraefikManager) generateConfig() error {
 	config := TraefikConfig{
 		HTTP: HTTPConfig{
-			Routers:  make(map[string]Router),
-			Services: make(map[string]Service),
+			Routers:     make(map[string]Router),
+			Services:    make(map[string]Service),
+			Middlewares: make(map[string]Middleware),
 		},
 	}
 
-	for _, project := range t.projects {
-		routerName := fmt.Sprintf("%s-router", project.Name)
-		serviceName := fmt.Sprintf("%s-service", project.Name)
+	https := t.httpsEnabled()
+	if https {
+		config.TLS = t.tlsConfig()
+	}
+	issued := map[string]bool{}
+
+	for _, routes := range t.routes {
+		for _, route := range routes {
+			serviceName := route.Name + "-service"
+			rule := route.rule()
+
+			var middlewares []string
+			if route.StripPrefix {
+				name := route.Name + "-strip"
+				config.HTTP.Middlewares[name] = Middleware{
+					StripPrefix: &StripPrefix{Prefixes: []string{route.PathPrefix}},
+				}
+				middlewares = append(middlewares, name)
+			}
+			if len(route.RequestHeaders) > 0 || len(route.ResponseHeaders) > 0 {
+				name := route.Name + "-headers"
+				config.HTTP.Middlewares[name] = Middleware{
+					Headers: &Headers{
+						CustomRequestHeaders:  route.RequestHeaders,
+						CustomResponseHeaders: route.ResponseHeaders,
+					},
+				}
+				middlewares = append(middlewares, name)
+			}
 
-		config.HTTP.Routers[routerName] = Router{
-			Rule:    fmt.Sprintf("Host(`%s`)", project.Domain),
-			Service: serviceName,
-		}
+			router := Router{
+				Rule:        rule,
+				Service:     serviceName,
+				EntryPoints: []string{entryPointWeb},
+				Middlewares: middlewares,
+				Priority:    route.Priority,
+			}
+
+			if https && t.issueCertificates(config.TLS, route, issued) {
+				config.HTTP.Routers[route.Name+"-secure-router"] = Router{
+					Rule:        rule,
+					Service:     serviceName,
+					EntryPoints: []string{entryPointWebSecure},
+					Middlewares: middlewares,
+					Priority:    route.Priority,
+					TLS:         &RouterTLS{},
+				}
+				if route.HTTPSRedirect {
+					// O redirecionamento vem antes: a requisição HTTP não chega
+					// a passar pelos outros middlewares.
+					router.Middlewares = []string{httpsRedirectMiddleware}
+					config.HTTP.Middlewares[httpsRedirectMiddleware] = t.redirectMiddleware()
+				}
+			}
+
+			config.HTTP.Routers[route.Name+"-router"] = router
 
-		config.HTTP.Services[serviceName] = Service{
-			LoadBalancer: LoadBalancer{
-				Servers: []Server{
-					{
-						URL: fmt.Sprintf("http://localhost:%d", project.Port),
+			config.HTTP.Services[serviceName] = Service{
+				LoadBalancer: LoadBalancer{
+					Servers: []Server{
+						{
+							URL: fmt.Sprintf("http://localhost:%d", route.Port),
+						},
 					},
 				},
-			},
+			}
 		}
 	}
 
+	if config.TLS != nil {
+		sort.Slice(config.TLS.Certificates, func(i, j int) bool {
+			return config.TLS.Certificates[i].CertFile < config.TLS.Certificates[j].CertFile
+		})
+	}
+
 	data, err := yaml.Marshal(config)
 	if err != nil {
 		return fmt.Errorf("erro ao serializar configuração: %w", err)
@@ -213,6 +372,62 @@ func (t *TraefikManager) generateConfig() error {
 	return nil
 }
 
+func (t *TraefikManager) httpsEnabled() bool {
+	return t.certs != nil && t.httpsPort > 0
+}
+
+// tlsConfig monta o store padrão com o certificado de defaultCertDomain. Os
+// certificados dos projetos são acrescentados por issueCertificate.
+func (t *TraefikManager) tlsConfig() *TLSConfig {
+	cfg := &TLSConfig{Certificates: []Certificate{}}
+	certFile, keyFile, err := t.certs.Certificate(defaultCertDomain)
+	if err != nil {
+		t.logger.Warn("Erro ao emitir certificado padrão", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return cfg
+	}
+	cfg.Stores = map[string]TLSStore{
+		"default": {DefaultCertificate: &Certificate{CertFile: certFile, KeyFile: keyFile}},
+	}
+	return cfg
+}
+
+// issueCertificates garante os certificados dos hosts da rota e os acrescenta
+// ao store padrão; issued evita repetir hosts compartilhados entre rotas. Se
+// algum falhar, a rota fica só em HTTP.
+func (t *TraefikManager) issueCertificates(cfg *TLSConfig, route Route, issued map[string]bool) bool {
+	for _, host := range route.Hosts {
+		if issued[host] {
+			continue
+		}
+		certFile, keyFile, err := t.certs.Certificate(host)
+		if err != nil {
+			t.logger.Warn("Erro ao emitir certificado, rota disponível só em HTTP", map[string]interface{}{
+				"route": route.Name,
+				"host":  host,
+				"error": err.Error(),
+			})
+			return false
+		}
+		cfg.Certificates = append(cfg.Certificates, Certificate{
+			CertFile: certFile,
+			KeyFile:  keyFile,
+			Stores:   []string{"default"},
+		})
+		issued[host] = true
+	}
+	return true
+}
+
+func (t *TraefikManager) redirectMiddleware() Middleware {
+	redirect := &RedirectScheme{Scheme: "https"}
+	if t.httpsPort != 443 {
+		redirect.Port = fmt.Sprintf("%d", t.httpsPort)
+	}
+	return Middleware{RedirectScheme: redirect}
+}
+
 func (t *TraefikManager) IsRunning() bool {
 	addr := fmt.Sprintf(":%d", t.httpPort)
 	conn, err := net.DialTimeout("tcp", addr, time.Second)
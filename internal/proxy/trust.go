@@ -0,0 +1,219 @@
+package proxy
+
+import (
+	"crypto/sha1"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Maycon-Santos/relief/pkg/fileutil"
+)
+
+// linuxTrustStore é onde cada família de distribuição procura CAs extras e
+// o comando que regenera o bundle depois da cópia.
+type linuxTrustStore struct {
+	dir    string
+	update []string
+}
+
+var linuxTrustStores = []linuxTrustStore{
+	{dir: "/usr/local/share/ca-certificates", update: []string{"update-ca-certificates"}},
+	{dir: "/etc/pki/ca-trust/source/anchors", update: []string{"update-ca-trust", "extract"}},
+	{dir: "/etc/ca-certificates/trust-source/anchors", update: []string{"trust", "extract-compat"}},
+	{dir: "/usr/share/pki/trust/anchors", update: []string{"update-ca-certificates"}},
+}
+
+// linuxTrustFile é o nome da CA no repositório do Linux; update-ca-certificates
+// só considera arquivos .crt.
+const linuxTrustFile = "relief-local-ca.crt"
+
+// nssNickname identifica a CA no banco NSS usado pelo Chrome no Linux.
+const nssNickname = "Relief Local CA"
+
+// InstallTrust instala a CA no repositório de confiança do sistema, pedindo
+// privilégios administrativos quando preciso. É um passo opcional: sem ele o
+// HTTPS funciona, mas o navegador alerta sobre o certificado.
+func (c *CertManager) InstallTrust() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.loadCA(); err != nil {
+		return err
+	}
+	caPath := c.CAPath()
+
+	c.logger.Info("Instalando a CA local no repositório de confiança", map[string]interface{}{
+		"path": caPath,
+		"os":   runtime.GOOS,
+	})
+
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf(
+			`do shell script "security add-trusted-cert -d -r trustRoot -k /Library/Keychains/System.keychain %s" with administrator privileges`,
+			shellQuote(caPath),
+		)
+		if output, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+			return fmt.Errorf("usuário cancelou ou erro ao instalar a CA: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+
+	case "linux":
+		store, ok := findLinuxTrustStore()
+		if !ok {
+			return fmt.Errorf("repositório de confiança do sistema não encontrado. Importe manualmente %s", caPath)
+		}
+		target := filepath.Join(store.dir, linuxTrustFile)
+		script := fmt.Sprintf("cp %s %s && %s", shellQuote(caPath), shellQuote(target), strings.Join(store.update, " "))
+		if err := runPrivileged(script); err != nil {
+			return err
+		}
+		c.installNSS(caPath)
+
+	case "windows":
+		if output, err := exec.Command("certutil", "-addstore", "-user", "-f", "Root", caPath).CombinedOutput(); err != nil {
+			return fmt.Errorf("erro ao instalar a CA: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+
+	default:
+		return fmt.Errorf("sistema operacional não suportado: %s", runtime.GOOS)
+	}
+
+	c.logger.Info("CA local instalada no repositório de confiança", nil)
+	return nil
+}
+
+// UninstallTrust remove a CA do repositório de confiança do sistema. Os
+// arquivos em ~/.relief/certs continuam no lugar.
+func (c *CertManager) UninstallTrust() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.loadCA(); err != nil {
+		return err
+	}
+	caPath := c.CAPath()
+
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf(
+			`do shell script "security remove-trusted-cert -d %s; security delete-certificate -Z %X /Library/Keychains/System.keychain" with administrator privileges`,
+			shellQuote(caPath), sha1Fingerprint(c.ca),
+		)
+		if output, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+			return fmt.Errorf("usuário cancelou ou erro ao remover a CA: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+
+	case "linux":
+		store, ok := findLinuxTrustStore()
+		if ok && fileutil.Exists(filepath.Join(store.dir, linuxTrustFile)) {
+			script := fmt.Sprintf("rm -f %s && %s", shellQuote(filepath.Join(store.dir, linuxTrustFile)), strings.Join(store.update, " "))
+			if err := runPrivileged(script); err != nil {
+				return err
+			}
+		}
+		if db, ok := nssDatabase(); ok {
+			_ = exec.Command("certutil", "-d", "sql:"+db, "-D", "-n", nssNickname).Run()
+		}
+
+	case "windows":
+		if output, err := exec.Command("certutil", "-delstore", "-user", "Root", c.ca.SerialNumber.Text(16)).CombinedOutput(); err != nil {
+			return fmt.Errorf("erro ao remover a CA: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+
+	default:
+		return fmt.Errorf("sistema operacional não suportado: %s", runtime.GOOS)
+	}
+
+	c.logger.Info("CA local removida do repositório de confiança", nil)
+	return nil
+}
+
+// isTrusted indica se o sistema já confia na CA. No Linux o pool do sistema
+// é lido uma vez por processo, então a presença do arquivo também conta.
+func (c *CertManager) isTrusted() bool {
+	if runtime.GOOS == "linux" {
+		if store, ok := findLinuxTrustStore(); ok && fileutil.Exists(filepath.Join(store.dir, linuxTrustFile)) {
+			return true
+		}
+	}
+	_, err := c.ca.Verify(x509.VerifyOptions{})
+	return err == nil
+}
+
+// installNSS adiciona a CA ao banco NSS do usuário, que o Chrome e o Chromium
+// usam no Linux no lugar do repositório do sistema. Sem o certutil do NSS o
+// passo é ignorado.
+func (c *CertManager) installNSS(caPath string) {
+	db, ok := nssDatabase()
+	if !ok {
+		return
+	}
+	if output, err := exec.Command("certutil", "-d", "sql:"+db, "-A", "-t", "C,,", "-n", nssNickname, "-i", caPath).CombinedOutput(); err != nil {
+		c.logger.Warn("Erro ao instalar a CA no banco NSS", map[string]interface{}{
+			"db":     db,
+			"error":  err.Error(),
+			"output": strings.TrimSpace(string(output)),
+		})
+	}
+}
+
+func nssDatabase() (string, bool) {
+	if _, err := exec.LookPath("certutil"); err != nil {
+		return "", false
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	db := filepath.Join(home, ".pki", "nssdb")
+	return db, fileutil.IsDir(db)
+}
+
+func findLinuxTrustStore() (linuxTrustStore, bool) {
+	for _, store := range linuxTrustStores {
+		if !fileutil.IsDir(store.dir) {
+			continue
+		}
+		if _, err := exec.LookPath(store.update[0]); err != nil {
+			continue
+		}
+		return store, true
+	}
+	return linuxTrustStore{}, false
+}
+
+// runPrivileged roda script com sh, direto quando o Relief já é root e via
+// pkexec caso contrário, como a escrita do /etc/hosts.
+func runPrivileged(script string) error {
+	var cmd *exec.Cmd
+	switch {
+	case os.Geteuid() == 0:
+		cmd = exec.Command("sh", "-c", script)
+	case hasCommand("pkexec"):
+		cmd = exec.Command("pkexec", "sh", "-c", script)
+	default:
+		return fmt.Errorf("permissão negada. Execute manualmente como root: %s", script)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("erro ao executar com privilégios administrativos: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func sha1Fingerprint(cert *x509.Certificate) []byte {
+	sum := sha1.Sum(cert.Raw)
+	return sum[:]
+}
@@ -0,0 +1,541 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/pkg/logger"
+	"github.com/Maycon-Santos/relief/pkg/pathutil"
+	"github.com/Maycon-Santos/relief/pkg/shellenv"
+)
+
+const (
+	composeLabelProject = "com.docker.compose.project"
+	composeLabelService = "com.docker.compose.service"
+	composePollInterval = 2 * time.Second
+)
+
+type ComposeRunner struct {
+	*BaseRunner
+	*callbackRegistry
+	client    *dockerClient
+	clientErr error
+	stacks    map[string]*ComposeStack
+	mu        sync.RWMutex
+	logger    *logger.Logger
+}
+
+type ComposeStack struct {
+	Project     *domain.Project
+	Name        string
+	Compose     *ComposeFile
+	MainService string
+	StartedAt   time.Time
+	Cancel      context.CancelFunc
+	// pending marca a stack reservada por Start enquanto o "up" roda; Cancel
+	// interrompe o "up".
+	pending   bool
+	following map[string]bool
+	followMu  sync.Mutex
+}
+
+func NewComposeRunner(log *logger.Logger) *ComposeRunner {
+	client, err := newDockerClient()
+	return &ComposeRunner{
+		BaseRunner:       NewBaseRunner(RunnerTypeCompose),
+		callbackRegistry: newCallbackRegistry(),
+		client:           client,
+		clientErr:        err,
+		stacks:           make(map[string]*ComposeStack),
+		logger:           log,
+	}
+}
+
+// UsesCompose indica se um projeto docker deve rodar como stack compose: quando
+// docker.compose_file está definido ou não há docker.image e existe um arquivo
+// compose na raiz do projeto.
+func UsesCompose(project *domain.Project) bool {
+	var docker *domain.DockerConfig
+	if project.Manifest != nil {
+		docker = project.Manifest.Docker
+	}
+	if docker != nil && docker.ComposeFile != "" {
+		return true
+	}
+	if docker != nil && docker.Image != "" {
+		return false
+	}
+	return FindComposeFile(pathutil.FromRelativeHome(project.Path), "") != ""
+}
+
+func composeProjectName(project *domain.Project) string {
+	return strings.ToLower(containerName(project))
+}
+
+// Start reserva o projeto em r.stacks e solta o lock durante o "docker
+// compose up", que pode baixar imagens e fazer builds; enquanto isso, Status
+// informa "starting" e Stop cancela o início.
+func (r *ComposeRunner) Start(ctx context.Context, project *domain.Project) error {
+	if r.clientErr != nil {
+		return r.clientErr
+	}
+
+	var configuredFile, configuredMain string
+	if project.Manifest != nil && project.Manifest.Docker != nil {
+		configuredFile = project.Manifest.Docker.ComposeFile
+		configuredMain = project.Manifest.Docker.MainService
+	}
+
+	projectPath := pathutil.FromRelativeHome(project.Path)
+	composePath := FindComposeFile(projectPath, configuredFile)
+	if composePath == "" {
+		return fmt.Errorf("arquivo compose não encontrado no projeto %s", project.Name)
+	}
+
+	compose, err := ParseComposeFile(composePath)
+	if err != nil {
+		return err
+	}
+
+	mainService := compose.MainService(configuredMain)
+	if configuredMain != "" && mainService == "" {
+		return fmt.Errorf("serviço principal '%s' não existe em %s", configuredMain, filepath.Base(composePath))
+	}
+	if mainService != "" && project.Port == 0 {
+		project.Port = compose.Services[mainService].PublishedPort()
+	}
+
+	startCtx, cancelStart := context.WithCancel(ctx)
+	defer cancelStart()
+	stack := &ComposeStack{
+		Project:     project,
+		Name:        composeProjectName(project),
+		Compose:     compose,
+		MainService: mainService,
+		Cancel:      cancelStart,
+		pending:     true,
+		following:   make(map[string]bool),
+	}
+
+	r.mu.Lock()
+	if _, exists := r.stacks[project.ID]; exists {
+		r.mu.Unlock()
+		return fmt.Errorf("projeto %s já está em execução", project.Name)
+	}
+	r.stacks[project.ID] = stack
+	r.mu.Unlock()
+
+	r.logger.Info("Iniciando stack compose", map[string]interface{}{
+		"project":      project.Name,
+		"file":         composePath,
+		"services":     compose.ServiceNames(),
+		"main_service": mainService,
+	})
+
+	upErr := r.runCompose(startCtx, stack, "up", "-d", "--remove-orphans")
+
+	r.mu.Lock()
+	if r.stacks[project.ID] != stack {
+		r.mu.Unlock()
+		// Stop chegou durante o "up": desfaz o que ele chegou a criar.
+		if err := r.runCompose(context.Background(), stack, "down", "--remove-orphans"); err != nil {
+			r.logger.Warn("Erro ao desfazer stack compose cancelada", map[string]interface{}{
+				"project": project.Name,
+				"error":   err.Error(),
+			})
+		}
+		return fmt.Errorf("início do projeto %s cancelado", project.Name)
+	}
+	if upErr != nil {
+		delete(r.stacks, project.ID)
+		r.mu.Unlock()
+		return fmt.Errorf("erro ao iniciar stack compose: %w", upErr)
+	}
+	stackCtx, cancel := context.WithCancel(context.Background())
+	stack.Cancel = cancel
+	stack.StartedAt = time.Now()
+	stack.pending = false
+	r.mu.Unlock()
+
+	if status, err := r.stackStatus(ctx, stack); err == nil {
+		project.PID = status.PID
+	}
+	project.UpdateStatus(domain.StatusStarting)
+
+	r.followNewContainers(stackCtx, stack)
+	go r.watchStack(stackCtx, stack)
+
+	r.logger.Info("Stack compose iniciada", map[string]interface{}{
+		"project": project.Name,
+		"name":    stack.Name,
+	})
+
+	return nil
+}
+
+func (r *ComposeRunner) Stop(ctx context.Context, projectID string) error {
+	r.mu.Lock()
+	stack, exists := r.stacks[projectID]
+	var pending bool
+	if exists {
+		pending = stack.pending
+		delete(r.stacks, projectID)
+	}
+	r.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("projeto não está em execução")
+	}
+
+	r.removeLogCallback(projectID)
+	r.removeStatusCallback(projectID)
+	stack.Cancel()
+
+	// Ainda no "up": Start vê a reserva sumir e faz o "down".
+	if pending {
+		return nil
+	}
+
+	if err := r.runCompose(ctx, stack, "down", "--remove-orphans"); err != nil {
+		return fmt.Errorf("erro ao parar stack compose: %w", err)
+	}
+
+	r.logger.Info("Stack compose parada", map[string]interface{}{
+		"project": stack.Project.Name,
+	})
+
+	return nil
+}
+
+func (r *ComposeRunner) Status(projectID string) (*RunnerStatus, error) {
+	r.mu.RLock()
+	stack, exists := r.stacks[projectID]
+	pending := exists && stack.pending
+	r.mu.RUnlock()
+
+	if !exists {
+		return &RunnerStatus{
+			ProjectID: projectID,
+			Status:    domain.StatusStopped,
+		}, nil
+	}
+	if pending {
+		return &RunnerStatus{
+			ProjectID: projectID,
+			Status:    domain.StatusStarting,
+			Port:      stack.Project.Port,
+			Message:   "Iniciando stack compose",
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.stackStatus(ctx, stack)
+}
+
+func (r *ComposeRunner) stackStatus(ctx context.Context, stack *ComposeStack) (*RunnerStatus, error) {
+	containers, err := r.client.ListContainers(ctx, map[string]string{composeLabelProject: stack.Name})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar containers da stack: %w", err)
+	}
+
+	byService := map[string]dockerContainerSummary{}
+	for _, c := range containers {
+		byService[c.Labels[composeLabelService]] = c
+	}
+
+	status := &RunnerStatus{
+		ProjectID: stack.Project.ID,
+		Status:    domain.StatusStopped,
+		Port:      stack.Project.Port,
+	}
+
+	running := 0
+	for _, name := range stack.Compose.ServiceNames() {
+		svc := ServiceStatus{
+			Name:  name,
+			State: "missing",
+			Main:  name == stack.MainService,
+			Port:  stack.Compose.Services[name].PublishedPort(),
+		}
+		if c, ok := byService[name]; ok {
+			svc.State = c.State
+			svc.Status = c.Status
+			svc.ContainerID = c.ID
+		}
+		if svc.State == "running" {
+			running++
+		}
+		status.Services = append(status.Services, svc)
+
+		if svc.Main && svc.ContainerID != "" {
+			if inspect, err := r.client.InspectContainer(ctx, svc.ContainerID); err == nil {
+				status.PID = inspect.State.Pid
+			}
+		}
+	}
+
+	if running > 0 {
+		status.Status = domain.StatusRunning
+		status.Uptime = time.Since(stack.StartedAt)
+		status.Message = fmt.Sprintf("%d/%d serviços rodando há %s", running, len(status.Services), status.Uptime.Round(time.Second))
+	}
+
+	return status, nil
+}
+
+func (r *ComposeRunner) GetLogs(projectID string, tail int) ([]domain.LogEntry, error) {
+	return r.GetLogsFromBuffer(projectID, tail), nil
+}
+
+func (r *ComposeRunner) Restart(ctx context.Context, project *domain.Project) error {
+	r.mu.RLock()
+	_, exists := r.stacks[project.ID]
+	r.mu.RUnlock()
+
+	if exists {
+		if err := r.Stop(ctx, project.ID); err != nil {
+			return fmt.Errorf("erro ao parar projeto: %w", err)
+		}
+	}
+
+	return r.Start(ctx, project)
+}
+
+func (r *ComposeRunner) emitLog(projectID, level, message string) {
+	entry := r.AddLog(projectID, level, message)
+	if fn := r.getLogCallback(projectID); fn != nil {
+		fn(entry)
+	}
+}
+
+func (r *ComposeRunner) emitEntry(entry domain.LogEntry) {
+	r.AddLogEntry(entry)
+	if fn := r.getLogCallback(entry.ProjectID); fn != nil {
+		fn(entry)
+	}
+}
+
+func (r *ComposeRunner) composeCommand(ctx context.Context, args ...string) (*exec.Cmd, error) {
+	if docker, err := shellenv.LookPath("docker"); err == nil {
+		probe := exec.Command(docker, "compose", "version")
+		probe.Env = shellenv.EnrichedEnv()
+		if probe.Run() == nil {
+			return exec.CommandContext(ctx, docker, append([]string{"compose"}, args...)...), nil
+		}
+	}
+
+	if legacy, err := shellenv.LookPath("docker-compose"); err == nil {
+		return exec.CommandContext(ctx, legacy, args...), nil
+	}
+
+	return nil, fmt.Errorf("docker compose não encontrado (instale o plugin compose ou docker-compose)")
+}
+
+func (r *ComposeRunner) runCompose(ctx context.Context, stack *ComposeStack, args ...string) error {
+	fullArgs := append([]string{"-p", stack.Name, "-f", stack.Compose.Path}, args...)
+	cmd, err := r.composeCommand(ctx, fullArgs...)
+	if err != nil {
+		return err
+	}
+
+	cmd.Dir = filepath.Dir(stack.Compose.Path)
+	cmd.Env = shellenv.EnrichedEnv()
+	for key, value := range stack.Project.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	projectID := stack.Project.ID
+	_ = scanLines(output, func(line string) {
+		if strings.TrimSpace(line) != "" {
+			r.emitLog(projectID, "info", "[compose] "+line)
+		}
+	})
+
+	return cmd.Wait()
+}
+
+// followNewContainers passa a acompanhar os logs dos containers da stack que
+// ainda não estão sendo seguidos (novos ou reiniciados).
+func (r *ComposeRunner) followNewContainers(ctx context.Context, stack *ComposeStack) []dockerContainerSummary {
+	containers, err := r.client.ListContainers(ctx, map[string]string{composeLabelProject: stack.Name})
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Warn("Erro ao listar containers da stack", map[string]interface{}{
+				"project": stack.Project.Name,
+				"error":   err.Error(),
+			})
+		}
+		return nil
+	}
+
+	stack.followMu.Lock()
+	defer stack.followMu.Unlock()
+
+	for _, c := range containers {
+		if c.State != "running" || stack.following[c.ID] {
+			continue
+		}
+		stack.following[c.ID] = true
+		go r.followServiceLogs(ctx, stack, c.ID, c.Labels[composeLabelService])
+	}
+
+	return containers
+}
+
+func (r *ComposeRunner) followServiceLogs(ctx context.Context, stack *ComposeStack, containerID, service string) {
+	projectID := stack.Project.ID
+	prefix := fmt.Sprintf("[%s] ", service)
+
+	err := r.client.FollowLogs(ctx, containerID, func(stream, line string) {
+		entry, ok := parseLogLine(projectID, stream, line)
+		if !ok {
+			return
+		}
+		entry.Message = prefix + entry.Message
+		if entry.Fields == nil {
+			entry.Fields = map[string]string{}
+		}
+		entry.Fields["service"] = service
+		r.emitEntry(entry)
+	})
+	if err != nil && ctx.Err() == nil {
+		r.logger.Error("Erro ao ler logs do serviço", err, map[string]interface{}{
+			"project_id": projectID,
+			"service":    service,
+		})
+	}
+
+	stack.followMu.Lock()
+	delete(stack.following, containerID)
+	stack.followMu.Unlock()
+}
+
+// watchStack acompanha a stack enquanto ela estiver ativa. A stack é
+// considerada encerrada quando o serviço principal (ou, sem ele, todos os
+// serviços) deixa de rodar.
+func (r *ComposeRunner) watchStack(ctx context.Context, stack *ComposeStack) {
+	ticker := time.NewTicker(composePollInterval)
+	defer ticker.Stop()
+
+	projectID := stack.Project.ID
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		containers := r.followNewContainers(ctx, stack)
+		if containers == nil {
+			continue
+		}
+
+		exited, failed := r.stackExited(ctx, containers, stack.MainService)
+		if !exited {
+			continue
+		}
+
+		r.mu.Lock()
+		current, exists := r.stacks[projectID]
+		if !exists || current != stack {
+			r.mu.Unlock()
+			return
+		}
+		delete(r.stacks, projectID)
+		r.mu.Unlock()
+
+		stack.Cancel()
+
+		if failed != "" {
+			msg := fmt.Sprintf("Stack compose encerrada: %s", failed)
+			r.logger.Warn("Stack compose terminou com erro", map[string]interface{}{
+				"project": stack.Project.Name,
+				"detail":  failed,
+			})
+			r.emitLog(projectID, "error", msg)
+			if fn := r.getStatusCallback(projectID); fn != nil {
+				fn(projectID, domain.StatusError, msg, nil)
+			}
+		} else {
+			r.emitLog(projectID, "info", "Stack compose encerrada normalmente")
+			if fn := r.getStatusCallback(projectID); fn != nil {
+				fn(projectID, domain.StatusStopped, "", nil)
+			}
+		}
+
+		r.removeLogCallback(projectID)
+		r.removeStatusCallback(projectID)
+		return
+	}
+}
+
+// stackExited diz se a stack terminou e, nesse caso, quais serviços saíram
+// com código diferente de zero. O código vem do inspect de cada container;
+// containers pausados continuam contando como vivos.
+func (r *ComposeRunner) stackExited(ctx context.Context, containers []dockerContainerSummary, mainService string) (bool, string) {
+	if len(containers) == 0 {
+		return true, ""
+	}
+
+	exited := []dockerContainerSummary{}
+	for _, c := range containers {
+		if mainService != "" && c.Labels[composeLabelService] != mainService {
+			continue
+		}
+		switch c.State {
+		case "running", "restarting", "created", "paused":
+			return false, ""
+		}
+		exited = append(exited, c)
+	}
+
+	failures := []string{}
+	for _, c := range exited {
+		service := c.Labels[composeLabelService]
+		info, err := r.client.InspectContainer(ctx, c.ID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, ""
+			}
+			failures = append(failures, fmt.Sprintf("%s %s", service, c.Status))
+			continue
+		}
+		if info.State.ExitCode != 0 {
+			failures = append(failures, fmt.Sprintf("%s saiu com código %d", service, info.State.ExitCode))
+		}
+	}
+
+	sort.Strings(failures)
+	return true, strings.Join(failures, "; ")
+}
+
+func (r *ComposeRunner) GetRunningStacks() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projectIDs := make([]string, 0, len(r.stacks))
+	for id := range r.stacks {
+		projectIDs = append(projectIDs, id)
+	}
+	return projectIDs
+}
@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/Maycon-Santos/relief/pkg/logger"
+)
+
+func composeContainer(id, service, state, status string) dockerContainerSummary {
+	return dockerContainerSummary{
+		ID:     id,
+		State:  state,
+		Status: status,
+		Labels: map[string]string{composeLabelService: service},
+	}
+}
+
+func TestComposeStackExited(t *testing.T) {
+	engine := newFakeEngine(t)
+	engine.exited = map[string]int{"api": 0, "worker": 2, "db": 137}
+	r := NewComposeRunner(logger.New("error", io.Discard))
+	ctx := context.Background()
+
+	cases := []struct {
+		name        string
+		containers  []dockerContainerSummary
+		mainService string
+		exited      bool
+		failed      string
+	}{
+		{
+			name: "pausado continua vivo",
+			containers: []dockerContainerSummary{
+				composeContainer("api", "api", "exited", "Exited (0) 1 second ago"),
+				composeContainer("cache", "cache", "paused", "Up 2 minutes (Paused)"),
+			},
+		},
+		{
+			name: "código vem do inspect, não do texto",
+			containers: []dockerContainerSummary{
+				// O texto diz (0), mas o inspect diz 2.
+				composeContainer("worker", "worker", "exited", "Exited (0) 1 second ago"),
+				composeContainer("api", "api", "exited", "Exited (1) 1 second ago"),
+			},
+			exited: true,
+			failed: "worker saiu com código 2",
+		},
+		{
+			name: "só o serviço principal conta",
+			containers: []dockerContainerSummary{
+				composeContainer("api", "api", "exited", "Exited (0) 1 second ago"),
+				composeContainer("db", "db", "exited", "Exited (137) 1 second ago"),
+			},
+			mainService: "api",
+			exited:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			exited, failed := r.stackExited(ctx, tc.containers, tc.mainService)
+			if exited != tc.exited || failed != tc.failed {
+				t.Fatalf("stackExited = (%v, %q), esperado (%v, %q)", exited, failed, tc.exited, tc.failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const composeMainLabel = "relief.main"
+
+var defaultComposeFiles = []string{
+	"compose.yaml",
+	"compose.yml",
+	"docker-compose.yaml",
+	"docker-compose.yml",
+}
+
+type ComposeFile struct {
+	Path     string
+	Services map[string]ComposeService `yaml:"services"`
+}
+
+type ComposeService struct {
+	Image     string      `yaml:"image"`
+	Build     interface{} `yaml:"build"`
+	Ports     []yaml.Node `yaml:"ports"`
+	Labels    yaml.Node   `yaml:"labels"`
+	DependsOn yaml.Node   `yaml:"depends_on"`
+}
+
+// FindComposeFile procura o arquivo de compose informado ou, quando vazio, os
+// nomes padrão aceitos pelo docker compose.
+func FindComposeFile(projectPath, configured string) string {
+	if configured != "" {
+		if !filepath.IsAbs(configured) {
+			configured = filepath.Join(projectPath, configured)
+		}
+		if _, err := os.Stat(configured); err == nil {
+			return configured
+		}
+		return ""
+	}
+
+	for _, name := range defaultComposeFiles {
+		candidate := filepath.Join(projectPath, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func ParseComposeFile(path string) (*ComposeFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo compose: %w", err)
+	}
+
+	var compose ComposeFile
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("formato inválido no arquivo compose: %w", err)
+	}
+	if len(compose.Services) == 0 {
+		return nil, fmt.Errorf("nenhum serviço definido em %s", filepath.Base(path))
+	}
+
+	compose.Path = path
+	return &compose, nil
+}
+
+func (c *ComposeFile) ServiceNames() []string {
+	names := make([]string, 0, len(c.Services))
+	for name := range c.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MainService retorna o serviço HTTP principal: o configurado no relief.yaml,
+// o que tiver o label relief.main=true ou, na falta deles, o único serviço
+// com porta publicada.
+func (c *ComposeFile) MainService(configured string) string {
+	if configured != "" {
+		if _, ok := c.Services[configured]; ok {
+			return configured
+		}
+		return ""
+	}
+
+	withPorts := []string{}
+	for _, name := range c.ServiceNames() {
+		svc := c.Services[name]
+		if v, ok := svc.labels()[composeMainLabel]; ok && v == "true" {
+			return name
+		}
+		if svc.PublishedPort() > 0 {
+			withPorts = append(withPorts, name)
+		}
+	}
+
+	if len(withPorts) == 1 {
+		return withPorts[0]
+	}
+	return ""
+}
+
+func (s ComposeService) labels() map[string]string {
+	labels := map[string]string{}
+	switch s.Labels.Kind {
+	case yaml.MappingNode:
+		_ = s.Labels.Decode(&labels)
+	case yaml.SequenceNode:
+		var list []string
+		_ = s.Labels.Decode(&list)
+		for _, item := range list {
+			parts := strings.SplitN(item, "=", 2)
+			if len(parts) == 2 {
+				labels[parts[0]] = parts[1]
+			} else {
+				labels[parts[0]] = ""
+			}
+		}
+	}
+	return labels
+}
+
+// PublishedPort retorna a primeira porta TCP publicada no host, aceitando a
+// sintaxe curta ("8080:80", "127.0.0.1:8080:80/tcp") e a longa (published/target).
+func (s ComposeService) PublishedPort() int {
+	for _, node := range s.Ports {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			if port := parseShortPort(node.Value); port > 0 {
+				return port
+			}
+		case yaml.MappingNode:
+			var long struct {
+				Published interface{} `yaml:"published"`
+				Protocol  string      `yaml:"protocol"`
+			}
+			if err := node.Decode(&long); err != nil || (long.Protocol != "" && long.Protocol != "tcp") {
+				continue
+			}
+			if port, err := strconv.Atoi(fmt.Sprint(long.Published)); err == nil && port > 0 {
+				return port
+			}
+		}
+	}
+	return 0
+}
+
+func parseShortPort(spec string) int {
+	if strings.HasSuffix(spec, "/udp") {
+		return 0
+	}
+	spec = strings.TrimSuffix(spec, "/tcp")
+
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 {
+		return 0
+	}
+
+	published := parts[len(parts)-2]
+	if i := strings.Index(published, "-"); i >= 0 {
+		published = published[:i]
+	}
+	port, err := strconv.Atoi(published)
+	if err != nil {
+		return 0
+	}
+	return port
+}
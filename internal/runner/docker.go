@@ -3,44 +3,488 @@ package runner
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Maycon-Santos/relief/internal/domain"
 	"github.com/Maycon-Santos/relief/pkg/logger"
+	"github.com/Maycon-Santos/relief/pkg/pathutil"
 )
 
+const (
+	dockerLabelProject = "relief.project"
+	dockerStopTimeout  = 10 * time.Second
+)
+
+var invalidContainerChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
 type DockerRunner struct {
 	*BaseRunner
-	logger *logger.Logger
+	*callbackRegistry
+	client     *dockerClient
+	clientErr  error
+	containers map[string]*ContainerInfo
+	mu         sync.RWMutex
+	logger     *logger.Logger
+}
+
+type ContainerInfo struct {
+	Project     *domain.Project
+	ContainerID string
+	Name        string
+	PID         int
+	StartedAt   time.Time
+	Cancel      context.CancelFunc
 }
 
 func NewDockerRunner(log *logger.Logger) *DockerRunner {
+	client, err := newDockerClient()
 	return &DockerRunner{
-		BaseRunner: NewBaseRunner(RunnerTypeDocker),
-		logger:     log,
+		BaseRunner:       NewBaseRunner(RunnerTypeDocker),
+		callbackRegistry: newCallbackRegistry(),
+		client:           client,
+		clientErr:        err,
+		containers:       make(map[string]*ContainerInfo),
+		logger:           log,
 	}
 }
 
+func containerName(project *domain.Project) string {
+	return "relief-" + strings.Trim(invalidContainerChars.ReplaceAllString(project.Name, "-"), "-.")
+}
+
+// Start reserva o projeto em r.containers e solta o lock antes das chamadas à
+// Engine API, que num pull podem levar minutos; enquanto isso, Status informa
+// "starting" e Stop cancela o início.
 func (r *DockerRunner) Start(ctx context.Context, project *domain.Project) error {
+	if project.Manifest == nil || project.Manifest.Docker == nil || project.Manifest.Docker.Image == "" {
+		return fmt.Errorf("imagem docker não definida para o projeto %s (docker.image no relief.yaml)", project.Name)
+	}
+
+	if r.clientErr != nil {
+		return r.clientErr
+	}
+
+	startCtx, cancelStart := context.WithCancel(ctx)
+	defer cancelStart()
+	reserved := &ContainerInfo{
+		Project: project,
+		Name:    containerName(project),
+		Cancel:  cancelStart,
+	}
+
+	r.mu.Lock()
+	if _, exists := r.containers[project.ID]; exists {
+		r.mu.Unlock()
+		return fmt.Errorf("projeto %s já está em execução", project.Name)
+	}
+	r.containers[project.ID] = reserved
+	r.mu.Unlock()
+
+	info, err := r.startContainer(startCtx, project)
+
+	r.mu.Lock()
+	if r.containers[project.ID] != reserved {
+		r.mu.Unlock()
+		if err == nil {
+			r.discardContainer(info)
+		}
+		return fmt.Errorf("início do projeto %s cancelado", project.Name)
+	}
+	if err != nil {
+		delete(r.containers, project.ID)
+		r.mu.Unlock()
+		return err
+	}
+	containerCtx, cancel := context.WithCancel(context.Background())
+	info.Cancel = cancel
+	r.containers[project.ID] = info
+	r.mu.Unlock()
+
+	project.PID = info.PID
+	project.UpdateStatus(domain.StatusStarting)
+
+	go r.followLogs(containerCtx, project.ID, info.ContainerID)
+	go r.monitorContainer(containerCtx, info)
+
+	r.logger.Info("Container iniciado", map[string]interface{}{
+		"project":   project.Name,
+		"container": info.Name,
+		"pid":       info.PID,
+	})
+
+	return nil
+}
+
+// startContainer baixa a imagem se preciso, cria e inicia o container. Roda
+// sem r.mu.
+func (r *DockerRunner) startContainer(ctx context.Context, project *domain.Project) (*ContainerInfo, error) {
+	pingCtx, cancelPing := context.WithTimeout(ctx, 5*time.Second)
+	defer cancelPing()
+	if err := r.client.Ping(pingCtx); err != nil {
+		return nil, fmt.Errorf("Docker não está disponível: %w", err)
+	}
+
+	image := project.Manifest.Docker.Image
+	exists, err := r.client.ImageExists(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar imagem %s: %w", image, err)
+	}
+	if !exists {
+		r.emitLog(project.ID, "info", fmt.Sprintf("Baixando imagem %s...", image))
+		if err := r.client.PullImage(ctx, image, func(status string) {
+			r.emitLog(project.ID, "info", status)
+		}); err != nil {
+			return nil, fmt.Errorf("erro ao baixar imagem %s: %w", image, err)
+		}
+	}
+
+	name := containerName(project)
+	if err := r.client.RemoveContainer(ctx, name); err != nil {
+		return nil, fmt.Errorf("erro ao remover container antigo %s: %w", name, err)
+	}
+
+	for _, network := range project.Manifest.Networks {
+		if err := r.client.EnsureNetwork(ctx, network); err != nil {
+			return nil, fmt.Errorf("erro ao criar rede %s: %w", network, err)
+		}
+	}
+
+	cfg := r.buildContainerConfig(project)
 
-	r.logger.Info("DockerRunner.Start chamado", map[string]interface{}{
-		"project": project.Name,
+	r.logger.Info("Criando container", map[string]interface{}{
+		"project":   project.Name,
+		"container": name,
+		"image":     image,
 	})
 
-	return fmt.Errorf("DockerRunner ainda não implementado - use NativeRunner")
+	containerID, err := r.client.CreateContainer(ctx, name, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar container: %w", err)
+	}
+
+	if len(project.Manifest.Networks) > 1 {
+		for _, network := range project.Manifest.Networks[1:] {
+			if err := r.client.ConnectNetwork(ctx, network, containerID); err != nil {
+				_ = r.client.RemoveContainer(context.Background(), containerID)
+				return nil, fmt.Errorf("erro ao conectar container à rede %s: %w", network, err)
+			}
+		}
+	}
+
+	if err := r.client.StartContainer(ctx, containerID); err != nil {
+		_ = r.client.RemoveContainer(context.Background(), containerID)
+		return nil, fmt.Errorf("erro ao iniciar container: %w", err)
+	}
+
+	info := &ContainerInfo{
+		Project:     project,
+		ContainerID: containerID,
+		Name:        name,
+		StartedAt:   time.Now(),
+	}
+
+	if inspect, err := r.client.InspectContainer(ctx, containerID); err == nil {
+		info.PID = inspect.State.Pid
+		if startedAt, err := time.Parse(time.RFC3339Nano, inspect.State.StartedAt); err == nil {
+			info.StartedAt = startedAt
+		}
+	}
+
+	return info, nil
+}
+
+// discardContainer para e remove um container cujo início foi cancelado por
+// Stop depois de ele já ter sido criado.
+func (r *DockerRunner) discardContainer(info *ContainerInfo) {
+	ctx, cancel := context.WithTimeout(context.Background(), dockerStopTimeout+5*time.Second)
+	defer cancel()
+	_ = r.client.StopContainer(ctx, info.ContainerID, dockerStopTimeout)
+	if err := r.client.RemoveContainer(ctx, info.ContainerID); err != nil {
+		r.logger.Warn("Erro ao remover container", map[string]interface{}{
+			"project":   info.Project.Name,
+			"container": info.Name,
+			"error":     err.Error(),
+		})
+	}
+}
+
+func (r *DockerRunner) buildContainerConfig(project *domain.Project) *dockerContainerConfig {
+	manifest := project.Manifest
+	projectPath := pathutil.FromRelativeHome(project.Path)
+
+	env := make([]string, 0, len(project.Env)+len(manifest.Docker.Environment)+1)
+	hasPort := false
+	for key, value := range project.Env {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+		hasPort = hasPort || key == "PORT"
+	}
+	for key, value := range manifest.Docker.Environment {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+		hasPort = hasPort || key == "PORT"
+	}
+	if project.Port > 0 && !hasPort {
+		env = append(env, fmt.Sprintf("PORT=%d", project.Port))
+	}
+
+	ports := map[int]bool{}
+	for _, port := range manifest.Ports {
+		if port > 0 {
+			ports[port] = true
+		}
+	}
+	if project.Port > 0 {
+		ports[project.Port] = true
+	}
+
+	exposed := make(map[string]struct{}, len(ports))
+	bindings := make(map[string][]dockerPortBinding, len(ports))
+	for port := range ports {
+		key := fmt.Sprintf("%d/tcp", port)
+		exposed[key] = struct{}{}
+		bindings[key] = []dockerPortBinding{{HostIP: "127.0.0.1", HostPort: strconv.Itoa(port)}}
+	}
+
+	var binds []string
+	anonymous := map[string]struct{}{}
+	for _, volume := range manifest.Volumes {
+		parts := strings.SplitN(volume, ":", 2)
+		if len(parts) == 1 {
+			anonymous[volume] = struct{}{}
+			continue
+		}
+		host := pathutil.FromRelativeHome(parts[0])
+		if strings.HasPrefix(host, ".") {
+			host = filepath.Join(projectPath, host)
+		}
+		binds = append(binds, host+":"+parts[1])
+	}
+
+	cfg := &dockerContainerConfig{
+		Image:        manifest.Docker.Image,
+		Cmd:          manifest.Docker.Command,
+		Env:          env,
+		WorkingDir:   manifest.Docker.WorkingDir,
+		Labels:       map[string]string{dockerLabelProject: project.ID},
+		ExposedPorts: exposed,
+		HostConfig: dockerHostConfig{
+			Binds:        binds,
+			PortBindings: bindings,
+		},
+	}
+	if len(anonymous) > 0 {
+		cfg.Volumes = anonymous
+	}
+	if len(manifest.Networks) > 0 {
+		cfg.HostConfig.NetworkMode = manifest.Networks[0]
+	}
+
+	return cfg
 }
 
 func (r *DockerRunner) Stop(ctx context.Context, projectID string) error {
-	return fmt.Errorf("DockerRunner ainda não implementado")
+	r.mu.Lock()
+	info, exists := r.containers[projectID]
+	if exists {
+		delete(r.containers, projectID)
+	}
+	r.mu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("projeto não está em execução")
+	}
+
+	r.removeLogCallback(projectID)
+	r.removeStatusCallback(projectID)
+
+	// Ainda iniciando: Start vê a reserva sumir e descarta o container.
+	if info.ContainerID == "" {
+		info.Cancel()
+		return nil
+	}
+
+	stopErr := r.client.StopContainer(ctx, info.ContainerID, dockerStopTimeout)
+	info.Cancel()
+
+	if err := r.client.RemoveContainer(ctx, info.ContainerID); err != nil {
+		r.logger.Warn("Erro ao remover container", map[string]interface{}{
+			"project":   info.Project.Name,
+			"container": info.Name,
+			"error":     err.Error(),
+		})
+	}
+
+	if stopErr != nil && !isDockerNotFound(stopErr) {
+		return fmt.Errorf("erro ao parar container: %w", stopErr)
+	}
+
+	r.logger.Info("Container parado", map[string]interface{}{
+		"project": info.Project.Name,
+	})
+
+	return nil
 }
 
 func (r *DockerRunner) Status(projectID string) (*RunnerStatus, error) {
-	return nil, fmt.Errorf("DockerRunner ainda não implementado")
+	r.mu.RLock()
+	info, exists := r.containers[projectID]
+	r.mu.RUnlock()
+
+	if !exists {
+		return &RunnerStatus{
+			ProjectID: projectID,
+			Status:    domain.StatusStopped,
+		}, nil
+	}
+	if info.ContainerID == "" {
+		return &RunnerStatus{
+			ProjectID: projectID,
+			Status:    domain.StatusStarting,
+			Port:      info.Project.Port,
+			Message:   "Iniciando container",
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	inspect, err := r.client.InspectContainer(ctx, info.ContainerID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao inspecionar container: %w", err)
+	}
+
+	status := &RunnerStatus{
+		ProjectID: projectID,
+		Status:    domain.StatusStopped,
+		PID:       inspect.State.Pid,
+		Port:      info.Project.Port,
+		Message:   inspect.State.Status,
+	}
+
+	if inspect.State.Running {
+		startedAt := info.StartedAt
+		if t, err := time.Parse(time.RFC3339Nano, inspect.State.StartedAt); err == nil {
+			startedAt = t
+		}
+		status.Status = domain.StatusRunning
+		status.Uptime = time.Since(startedAt)
+		status.Message = fmt.Sprintf("Rodando há %s", status.Uptime.Round(time.Second))
+	} else if inspect.State.ExitCode != 0 || inspect.State.Error != "" {
+		status.Status = domain.StatusError
+		status.Message = fmt.Sprintf("Container terminou com código %d %s", inspect.State.ExitCode, inspect.State.Error)
+	}
+
+	return status, nil
 }
 
 func (r *DockerRunner) GetLogs(projectID string, tail int) ([]domain.LogEntry, error) {
-	return nil, fmt.Errorf("DockerRunner ainda não implementado")
+	return r.GetLogsFromBuffer(projectID, tail), nil
 }
 
 func (r *DockerRunner) Restart(ctx context.Context, project *domain.Project) error {
-	return fmt.Errorf("DockerRunner ainda não implementado")
+	r.mu.RLock()
+	_, exists := r.containers[project.ID]
+	r.mu.RUnlock()
+
+	if exists {
+		if err := r.Stop(ctx, project.ID); err != nil {
+			return fmt.Errorf("erro ao parar projeto: %w", err)
+		}
+	}
+
+	return r.Start(ctx, project)
+}
+
+func (r *DockerRunner) emitLog(projectID, level, message string) {
+	entry := r.AddLog(projectID, level, message)
+	if fn := r.getLogCallback(projectID); fn != nil {
+		fn(entry)
+	}
+}
+
+func (r *DockerRunner) emitEntry(entry domain.LogEntry) {
+	r.AddLogEntry(entry)
+	if fn := r.getLogCallback(entry.ProjectID); fn != nil {
+		fn(entry)
+	}
+}
+
+func (r *DockerRunner) followLogs(ctx context.Context, projectID, containerID string) {
+	err := r.client.FollowLogs(ctx, containerID, func(stream, line string) {
+		if entry, ok := parseLogLine(projectID, stream, line); ok {
+			r.emitEntry(entry)
+		}
+	})
+	if err != nil && ctx.Err() == nil {
+		r.logger.Error("Erro ao ler logs do container", err, map[string]interface{}{
+			"project_id": projectID,
+		})
+	}
+}
+
+func (r *DockerRunner) monitorContainer(ctx context.Context, info *ContainerInfo) {
+	projectID := info.Project.ID
+
+	exitCode, err := r.client.WaitContainer(ctx, info.ContainerID)
+	if ctx.Err() != nil {
+		return
+	}
+
+	r.mu.Lock()
+	current, exists := r.containers[projectID]
+	if !exists || current != info {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.containers, projectID)
+	r.mu.Unlock()
+
+	var exit *ExitInfo
+	if err == nil {
+		exit = &ExitInfo{Code: exitCode}
+	}
+
+	if err != nil || exitCode != 0 {
+		msg := fmt.Sprintf("Container terminou com código %d", exitCode)
+		if err != nil {
+			msg = fmt.Sprintf("%s: %s", msg, err.Error())
+		}
+
+		r.logger.Warn("Container terminou com erro", map[string]interface{}{
+			"project":   info.Project.Name,
+			"exit_code": exitCode,
+		})
+
+		r.emitLog(projectID, "error", msg)
+		if fn := r.getStatusCallback(projectID); fn != nil {
+			fn(projectID, domain.StatusError, msg, exit)
+		}
+	} else {
+		r.logger.Info("Container terminou", map[string]interface{}{
+			"project": info.Project.Name,
+		})
+		r.emitLog(projectID, "info", "Container encerrado normalmente")
+		if fn := r.getStatusCallback(projectID); fn != nil {
+			fn(projectID, domain.StatusStopped, "", exit)
+		}
+	}
+
+	info.Cancel()
+	r.removeLogCallback(projectID)
+	r.removeStatusCallback(projectID)
+}
+
+func (r *DockerRunner) GetRunningContainers() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projectIDs := make([]string, 0, len(r.containers))
+	for id := range r.containers {
+		projectIDs = append(projectIDs, id)
+	}
+	return projectIDs
 }
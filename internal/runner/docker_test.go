@@ -0,0 +1,363 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/pkg/logger"
+)
+
+// fakeEngine é uma Docker Engine API mínima servida num socket unix.
+type fakeEngine struct {
+	mux *http.ServeMux
+
+	mu    sync.Mutex
+	calls []string
+
+	// pullGate, quando não nulo, segura o pull até ser fechado.
+	pullGate chan struct{}
+	// pullBody substitui o stream de progresso do pull.
+	pullBody string
+	// exitCode é devolvido por wait; com exitCode < 0, wait só volta quando a
+	// requisição é cancelada.
+	exitCode int
+	// exited dá o código de saída que o inspect devolve para cada container
+	// terminado; os demais aparecem rodando.
+	exited map[string]int
+}
+
+func newFakeEngine(t *testing.T) *fakeEngine {
+	t.Helper()
+	e := &fakeEngine{mux: http.NewServeMux(), exitCode: -1}
+
+	e.handle("GET /v1.41/_ping", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	})
+	e.handle("GET /v1.41/images/{image...}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"No such image"}`))
+	})
+	e.handle("POST /v1.41/images/create", func(w http.ResponseWriter, r *http.Request) {
+		if e.pullGate != nil {
+			select {
+			case <-e.pullGate:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if e.pullBody != "" {
+			_, _ = w.Write([]byte(e.pullBody))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"Pulling from library/nginx","id":"1.25"}` + "\n" +
+			`{"status":"Downloaded newer image for nginx:1.25"}` + "\n"))
+	})
+	e.handle("POST /v1.41/containers/create", func(w http.ResponseWriter, r *http.Request) {
+		var cfg dockerContainerConfig
+		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil || cfg.Image != "nginx:1.25" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"Id":"c1"}`))
+	})
+	e.handle("POST /v1.41/containers/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	e.handle("GET /v1.41/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		if code, ok := e.exited[r.PathValue("id")]; ok {
+			_, _ = w.Write([]byte(`{"Id":"` + r.PathValue("id") + `","State":{"Status":"exited","ExitCode":` + strconv.Itoa(code) + `}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Id":"c1","State":{"Status":"running","Running":true,"Pid":4242,"StartedAt":"2026-01-02T03:04:05Z"}}`))
+	})
+	e.handle("GET /v1.41/containers/{id}/logs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(dockerFrame(1, "server ready\n"))
+	})
+	e.handle("POST /v1.41/containers/{id}/wait", func(w http.ResponseWriter, r *http.Request) {
+		if e.exitCode < 0 {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte(`{"StatusCode":` + strconv.Itoa(e.exitCode) + `}`))
+	})
+	e.handle("POST /v1.41/containers/{id}/stop", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	e.handle("DELETE /v1.41/containers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "c1" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"No such container"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// Caminho curto: sockets unix têm limite de ~100 bytes.
+	dir, err := os.MkdirTemp("", "rdk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(e.mux)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	t.Setenv("DOCKER_HOST", "unix://"+socket)
+	return e
+}
+
+func (e *fakeEngine) handle(pattern string, fn http.HandlerFunc) {
+	e.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		e.calls = append(e.calls, r.Method+" "+strings.TrimPrefix(r.URL.Path, "/"+dockerAPIVersion))
+		e.mu.Unlock()
+		fn(w, r)
+	})
+}
+
+func (e *fakeEngine) called(call string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, c := range e.calls {
+		if c == call {
+			return true
+		}
+	}
+	return false
+}
+
+func dockerFrame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func dockerProject() *domain.Project {
+	return &domain.Project{
+		ID:       "p1",
+		Name:     "web",
+		Manifest: &domain.Manifest{Docker: &domain.DockerConfig{Image: "nginx:1.25"}},
+	}
+}
+
+func TestDemuxDockerStream(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(dockerFrame(1, "hello\nwor"))
+	stream.Write(dockerFrame(2, "oops\r\n"))
+	stream.Write(dockerFrame(1, "ld\n"))
+	stream.Write(dockerFrame(2, "no newline"))
+	// Cabeçalho cortado no fim do stream, como numa conexão encerrada.
+	stream.Write([]byte{1, 0, 0})
+
+	var got []string
+	err := demuxDockerStream(&stream, func(name, line string) {
+		got = append(got, name+": "+line)
+	})
+	if err != nil {
+		t.Fatalf("demuxDockerStream: %v", err)
+	}
+
+	want := []string{"stdout: hello", "stderr: oops", "stdout: world", "stderr: no newline"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("linhas = %q, esperado %q", got, want)
+	}
+}
+
+func TestDemuxDockerStreamTruncatedFrame(t *testing.T) {
+	frame := dockerFrame(1, "complete\n")
+	frame = append(frame, dockerFrame(1, "cut short")[:12]...)
+
+	var got []string
+	err := demuxDockerStream(bytes.NewReader(frame), func(_, line string) {
+		got = append(got, line)
+	})
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("erro = %v, esperado io.ErrUnexpectedEOF", err)
+	}
+	if len(got) != 1 || got[0] != "complete" {
+		t.Fatalf("linhas = %q", got)
+	}
+}
+
+func TestPullImageProgress(t *testing.T) {
+	engine := newFakeEngine(t)
+	client, err := newDockerClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var progress []string
+	if err := client.PullImage(context.Background(), "nginx:1.25", func(status string) {
+		progress = append(progress, status)
+	}); err != nil {
+		t.Fatalf("PullImage: %v", err)
+	}
+	if len(progress) != 1 || progress[0] != "Downloaded newer image for nginx:1.25" {
+		t.Fatalf("progresso = %q", progress)
+	}
+	if !engine.called("POST /images/create") {
+		t.Fatal("pull não chamou /images/create")
+	}
+}
+
+func TestPullImageError(t *testing.T) {
+	engine := newFakeEngine(t)
+	// O erro do pull vem no meio do stream, com status 200.
+	engine.pullBody = `{"status":"Pulling from library/nope"}` + "\n" +
+		`{"error":"manifest for nope:latest not found"}` + "\n"
+	client, err := newDockerClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.PullImage(context.Background(), "nope", nil)
+	if err == nil || !strings.Contains(err.Error(), "manifest for nope:latest not found") {
+		t.Fatalf("erro = %v", err)
+	}
+}
+
+func TestDockerRunnerNonZeroExit(t *testing.T) {
+	engine := newFakeEngine(t)
+	engine.exitCode = 3
+
+	r := NewDockerRunner(logger.New("error", io.Discard))
+	project := dockerProject()
+
+	var logMu sync.Mutex
+	var logs []string
+	r.SetLogCallback(project.ID, func(entry domain.LogEntry) {
+		logMu.Lock()
+		logs = append(logs, entry.Message)
+		logMu.Unlock()
+	})
+	type exitEvent struct {
+		status domain.Status
+		exit   *ExitInfo
+	}
+	exited := make(chan exitEvent, 1)
+	r.SetStatusCallback(project.ID, func(_ string, status domain.Status, _ string, exit *ExitInfo) {
+		exited <- exitEvent{status, exit}
+	})
+
+	if err := r.Start(context.Background(), project); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if project.PID != 4242 {
+		t.Fatalf("PID = %d, esperado 4242", project.PID)
+	}
+
+	select {
+	case ev := <-exited:
+		if ev.status != domain.StatusError || ev.exit == nil || ev.exit.Code != 3 {
+			t.Fatalf("saída = %v %+v", ev.status, ev.exit)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("status de saída não recebido")
+	}
+
+	for _, call := range []string{"GET /_ping", "POST /images/create", "POST /containers/create", "POST /containers/c1/start", "POST /containers/c1/wait"} {
+		if !engine.called(call) {
+			t.Errorf("chamada %q não feita", call)
+		}
+	}
+
+	logMu.Lock()
+	output := strings.Join(logs, "\n")
+	logMu.Unlock()
+	if !strings.Contains(output, "Container terminou com código 3") {
+		t.Errorf("logs sem a saída do container: %q", output)
+	}
+
+	status, err := r.Status(project.ID)
+	if err != nil || status.Status != domain.StatusStopped {
+		t.Fatalf("Status depois da saída = %+v, %v", status, err)
+	}
+}
+
+func TestDockerRunnerStop(t *testing.T) {
+	engine := newFakeEngine(t)
+
+	r := NewDockerRunner(logger.New("error", io.Discard))
+	project := dockerProject()
+	if err := r.Start(context.Background(), project); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	status, err := r.Status(project.ID)
+	if err != nil || status.Status != domain.StatusRunning {
+		t.Fatalf("Status = %+v, %v", status, err)
+	}
+
+	if err := r.Stop(context.Background(), project.ID); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if !engine.called("POST /containers/c1/stop") || !engine.called("DELETE /containers/c1") {
+		t.Fatal("Stop não parou e removeu o container")
+	}
+	if status, _ := r.Status(project.ID); status.Status != domain.StatusStopped {
+		t.Fatalf("Status depois do Stop = %s", status.Status)
+	}
+}
+
+// Um pull demorado não pode segurar Status e Stop dos demais chamadores.
+func TestDockerRunnerStopDuringPull(t *testing.T) {
+	engine := newFakeEngine(t)
+	engine.pullGate = make(chan struct{})
+	defer close(engine.pullGate)
+
+	r := NewDockerRunner(logger.New("error", io.Discard))
+	project := dockerProject()
+
+	started := make(chan error, 1)
+	go func() { started <- r.Start(context.Background(), project) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !engine.called("POST /images/create") {
+		if time.Now().After(deadline) {
+			t.Fatal("pull não começou")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	status, err := r.Status(project.ID)
+	if err != nil || status.Status != domain.StatusStarting {
+		t.Fatalf("Status durante o pull = %+v, %v", status, err)
+	}
+
+	if err := r.Stop(context.Background(), project.ID); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	select {
+	case err := <-started:
+		if err == nil {
+			t.Fatal("Start cancelado retornou sem erro")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start não retornou depois do Stop")
+	}
+	if engine.called("POST /containers/create") {
+		t.Fatal("container criado depois do Stop")
+	}
+}
@@ -0,0 +1,389 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	dockerAPIVersion  = "v1.41"
+	defaultDockerHost = "unix:///var/run/docker.sock"
+)
+
+type dockerClient struct {
+	http *http.Client
+}
+
+type dockerContainerConfig struct {
+	Image        string              `json:"Image"`
+	Cmd          []string            `json:"Cmd,omitempty"`
+	Env          []string            `json:"Env,omitempty"`
+	WorkingDir   string              `json:"WorkingDir,omitempty"`
+	Labels       map[string]string   `json:"Labels,omitempty"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+	Volumes      map[string]struct{} `json:"Volumes,omitempty"`
+	HostConfig   dockerHostConfig    `json:"HostConfig"`
+}
+
+type dockerHostConfig struct {
+	Binds        []string                       `json:"Binds,omitempty"`
+	PortBindings map[string][]dockerPortBinding `json:"PortBindings,omitempty"`
+	NetworkMode  string                         `json:"NetworkMode,omitempty"`
+}
+
+type dockerPortBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+type dockerContainerState struct {
+	Status     string `json:"Status"`
+	Running    bool   `json:"Running"`
+	Pid        int    `json:"Pid"`
+	ExitCode   int    `json:"ExitCode"`
+	Error      string `json:"Error"`
+	StartedAt  string `json:"StartedAt"`
+	FinishedAt string `json:"FinishedAt"`
+}
+
+type dockerContainerInfo struct {
+	ID     string               `json:"Id"`
+	Name   string               `json:"Name"`
+	State  dockerContainerState `json:"State"`
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+type dockerContainerSummary struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	State  string            `json:"State"`
+	Status string            `json:"Status"`
+	Labels map[string]string `json:"Labels"`
+}
+
+type dockerAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *dockerAPIError) Error() string {
+	return fmt.Sprintf("docker API %d: %s", e.StatusCode, e.Message)
+}
+
+func isDockerNotFound(err error) bool {
+	apiErr, ok := err.(*dockerAPIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// newDockerClient conecta ao Docker Engine pelo socket indicado em DOCKER_HOST
+// (unix:// ou tcp://), usando /var/run/docker.sock quando não definido.
+func newDockerClient() (*dockerClient, error) {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = defaultDockerHost
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("DOCKER_HOST inválido: %w", err)
+	}
+
+	var dial func(ctx context.Context, network, addr string) (net.Conn, error)
+	switch u.Scheme {
+	case "unix":
+		socketPath := u.Path
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socketPath)
+		}
+	case "tcp":
+		tcpAddr := u.Host
+		dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "tcp", tcpAddr)
+		}
+	default:
+		return nil, fmt.Errorf("esquema de DOCKER_HOST não suportado: %s", u.Scheme)
+	}
+
+	return &dockerClient{
+		http: &http.Client{
+			Transport: &http.Transport{DialContext: dial},
+		},
+	}, nil
+}
+
+func (c *dockerClient) do(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	target := "http://docker/" + dockerAPIVersion + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao Docker: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return nil, &dockerAPIError{StatusCode: resp.StatusCode, Message: apiErr.Message}
+	}
+
+	return resp, nil
+}
+
+func (c *dockerClient) doJSON(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	resp, err := c.do(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *dockerClient) Ping(ctx context.Context) error {
+	return c.doJSON(ctx, http.MethodGet, "/_ping", nil, nil, nil)
+}
+
+func (c *dockerClient) ImageExists(ctx context.Context, image string) (bool, error) {
+	err := c.doJSON(ctx, http.MethodGet, "/images/"+image+"/json", nil, nil, nil)
+	if isDockerNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PullImage baixa a imagem e repassa cada linha de progresso para onProgress.
+func (c *dockerClient) PullImage(ctx context.Context, image string, onProgress func(string)) error {
+	name, tag := image, "latest"
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		name, tag = image[:i], image[i+1:]
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/images/create", url.Values{"fromImage": {name}, "tag": {tag}}, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Status string `json:"status"`
+			ID     string `json:"id"`
+			Error  string `json:"error"`
+		}
+		if err := decoder.Decode(&msg); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("erro ao baixar imagem %s: %s", image, msg.Error)
+		}
+		if onProgress != nil && msg.Status != "" && msg.ID == "" {
+			onProgress(msg.Status)
+		}
+	}
+}
+
+func (c *dockerClient) CreateContainer(ctx context.Context, name string, cfg *dockerContainerConfig) (string, error) {
+	var out struct {
+		ID string `json:"Id"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, "/containers/create", url.Values{"name": {name}}, cfg, &out); err != nil {
+		return "", err
+	}
+	return out.ID, nil
+}
+
+func (c *dockerClient) StartContainer(ctx context.Context, id string) error {
+	return c.doJSON(ctx, http.MethodPost, "/containers/"+id+"/start", nil, nil, nil)
+}
+
+func (c *dockerClient) StopContainer(ctx context.Context, id string, timeout time.Duration) error {
+	query := url.Values{"t": {fmt.Sprintf("%d", int(timeout.Seconds()))}}
+	return c.doJSON(ctx, http.MethodPost, "/containers/"+id+"/stop", query, nil, nil)
+}
+
+func (c *dockerClient) RemoveContainer(ctx context.Context, id string) error {
+	err := c.doJSON(ctx, http.MethodDelete, "/containers/"+id, url.Values{"force": {"1"}, "v": {"1"}}, nil, nil)
+	if isDockerNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *dockerClient) InspectContainer(ctx context.Context, id string) (*dockerContainerInfo, error) {
+	var info dockerContainerInfo
+	if err := c.doJSON(ctx, http.MethodGet, "/containers/"+id+"/json", nil, nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// ListContainers lista containers (inclusive parados) que possuem todos os labels informados.
+func (c *dockerClient) ListContainers(ctx context.Context, labels map[string]string) ([]dockerContainerSummary, error) {
+	labelFilters := make([]string, 0, len(labels))
+	for k, v := range labels {
+		labelFilters = append(labelFilters, k+"="+v)
+	}
+	filters, err := json.Marshal(map[string][]string{"label": labelFilters})
+	if err != nil {
+		return nil, err
+	}
+
+	var out []dockerContainerSummary
+	query := url.Values{"all": {"1"}, "filters": {string(filters)}}
+	if err := c.doJSON(ctx, http.MethodGet, "/containers/json", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WaitContainer bloqueia até o container terminar e retorna o exit code.
+func (c *dockerClient) WaitContainer(ctx context.Context, id string) (int, error) {
+	var out struct {
+		StatusCode int `json:"StatusCode"`
+		Error      *struct {
+			Message string `json:"Message"`
+		} `json:"Error"`
+	}
+	if err := c.doJSON(ctx, http.MethodPost, "/containers/"+id+"/wait", nil, nil, &out); err != nil {
+		return -1, err
+	}
+	if out.Error != nil && out.Error.Message != "" {
+		return out.StatusCode, fmt.Errorf("%s", out.Error.Message)
+	}
+	return out.StatusCode, nil
+}
+
+func (c *dockerClient) EnsureNetwork(ctx context.Context, name string) error {
+	err := c.doJSON(ctx, http.MethodGet, "/networks/"+name, nil, nil, nil)
+	if err == nil {
+		return nil
+	}
+	if !isDockerNotFound(err) {
+		return err
+	}
+
+	body := map[string]interface{}{
+		"Name":           name,
+		"CheckDuplicate": true,
+		"Labels":         map[string]string{"relief.managed": "true"},
+	}
+	return c.doJSON(ctx, http.MethodPost, "/networks/create", nil, body, nil)
+}
+
+func (c *dockerClient) ConnectNetwork(ctx context.Context, network, containerID string) error {
+	body := map[string]string{"Container": containerID}
+	return c.doJSON(ctx, http.MethodPost, "/networks/"+network+"/connect", nil, body, nil)
+}
+
+// FollowLogs acompanha stdout/stderr do container até o stream terminar ou o
+// contexto ser cancelado, entregando cada linha com o stream de origem.
+func (c *dockerClient) FollowLogs(ctx context.Context, id string, onLine func(stream, line string)) error {
+	query := url.Values{"follow": {"1"}, "stdout": {"1"}, "stderr": {"1"}}
+	resp, err := c.do(ctx, http.MethodGet, "/containers/"+id+"/logs", query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("Content-Type") == "application/vnd.docker.raw-stream" {
+		return scanLines(resp.Body, func(line string) { onLine("stdout", line) })
+	}
+	return demuxDockerStream(resp.Body, onLine)
+}
+
+// demuxDockerStream decodifica o formato multiplexado da API (cabeçalho de 8
+// bytes: tipo do stream + tamanho do frame) usado por containers sem TTY.
+func demuxDockerStream(r io.Reader, onLine func(stream, line string)) error {
+	header := make([]byte, 8)
+	partial := map[string]string{}
+
+	flush := func(stream string, chunk string) {
+		data := partial[stream] + chunk
+		for {
+			i := strings.IndexByte(data, '\n')
+			if i < 0 {
+				break
+			}
+			onLine(stream, strings.TrimRight(data[:i], "\r"))
+			data = data[i+1:]
+		}
+		for len(data) >= maxLogLineBytes {
+			onLine(stream, data[:maxLogLineBytes])
+			data = data[maxLogLineBytes:]
+		}
+		partial[stream] = data
+	}
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			for stream, rest := range partial {
+				if rest != "" {
+					onLine(stream, rest)
+				}
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return err
+		}
+
+		stream := "stdout"
+		if header[0] == 2 {
+			stream = "stderr"
+		}
+
+		size := binary.BigEndian.Uint32(header[4:])
+		frame := make([]byte, size)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return err
+		}
+		flush(stream, string(frame))
+	}
+}
@@ -2,9 +2,12 @@ package runner
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/Maycon-Santos/relief/internal/domain"
 	"github.com/Maycon-Santos/relief/pkg/logger"
+	"github.com/Maycon-Santos/relief/pkg/pathutil"
 )
 
 type Factory struct {
@@ -18,25 +21,81 @@ func NewFactory(log *logger.Logger) *Factory {
 }
 
 func (f *Factory) CreateRunner(project *domain.Project) (ProjectRunner, error) {
+	switch TypeFor(project) {
+	case RunnerTypeCompose:
+		return NewComposeRunner(f.logger), nil
+	case RunnerTypeDocker:
+		return NewDockerRunner(f.logger), nil
+	case RunnerTypeNative:
+		return NewNativeRunner(f.logger), nil
+	default:
+		return nil, fmt.Errorf("tipo de projeto não suportado: %s", project.Type)
+	}
+}
+
+// TypeFor é o runner usado pelo projeto, ou vazio para um tipo não suportado.
+func TypeFor(project *domain.Project) RunnerType {
 	switch project.Type {
 	case domain.ProjectTypeDocker:
-		return NewDockerRunner(f.logger), nil
+		if UsesCompose(project) {
+			return RunnerTypeCompose
+		}
+		return RunnerTypeDocker
 
 	case domain.ProjectTypeNode,
 		domain.ProjectTypePython,
 		domain.ProjectTypeGo,
 		domain.ProjectTypeJava,
 		domain.ProjectTypeRuby:
-		return NewNativeRunner(f.logger), nil
+		return RunnerTypeNative
 
 	default:
-		return nil, fmt.Errorf("tipo de projeto não suportado: %s", project.Type)
+		return ""
 	}
 }
 
 func (f *Factory) GetAllRunners() map[string]ProjectRunner {
 	return map[string]ProjectRunner{
-		"docker": NewDockerRunner(f.logger),
-		"native": NewNativeRunner(f.logger),
+		"docker":  NewDockerRunner(f.logger),
+		"compose": NewComposeRunner(f.logger),
+		"native":  NewNativeRunner(f.logger),
+	}
+}
+
+// Describe resume o que o runner executa para o projeto: o script dev, a
+// imagem Docker ou o arquivo compose. É o que fica registrado em cada execução.
+func Describe(project *domain.Project) string {
+	kind := TypeFor(project)
+	if kind == RunnerTypeNative {
+		return devScript(project)
+	}
+
+	var docker *domain.DockerConfig
+	if project.Manifest != nil {
+		docker = project.Manifest.Docker
+	}
+	if kind == RunnerTypeCompose {
+		configured := ""
+		if docker != nil {
+			configured = docker.ComposeFile
+		}
+		if file := FindComposeFile(pathutil.FromRelativeHome(project.Path), configured); file != "" {
+			return "docker compose -f " + filepath.Base(file) + " up"
+		}
+		return "docker compose up"
+	}
+	if docker != nil && docker.Image != "" {
+		return strings.TrimSpace("docker run " + docker.Image + " " + strings.Join(docker.Command, " "))
+	}
+	return ""
+}
+
+// devScript é o script dev do relief.yaml ou, sem ele, o da configuração.
+func devScript(project *domain.Project) string {
+	if project.Manifest != nil {
+		if script := project.Manifest.GetDevScript(); script != "" {
+			return script
+		}
 	}
+	return project.Scripts["dev"]
 }
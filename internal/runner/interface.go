@@ -2,6 +2,7 @@ package runner
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/Maycon-Santos/relief/internal/domain"
@@ -28,19 +29,32 @@ type RunnerStatus struct {
 	MemoryUsed int64
 	CPUUsed    float64
 	Message    string
+	Services   []ServiceStatus
+	Metrics    *ProcessMetrics
+}
+
+type ServiceStatus struct {
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	Status      string `json:"status,omitempty"`
+	Main        bool   `json:"main"`
+	Port        int    `json:"port,omitempty"`
+	ContainerID string `json:"container_id,omitempty"`
 }
 
 type RunnerType string
 
 const (
-	RunnerTypeDocker RunnerType = "docker"
-	RunnerTypeNative RunnerType = "native"
+	RunnerTypeDocker  RunnerType = "docker"
+	RunnerTypeCompose RunnerType = "compose"
+	RunnerTypeNative  RunnerType = "native"
 )
 
 type BaseRunner struct {
 	Type          RunnerType
 	LogBuffer     []domain.LogEntry
 	MaxLogEntries int
+	logMu         sync.RWMutex
 }
 
 func NewBaseRunner(runnerType RunnerType) *BaseRunner {
@@ -51,13 +65,20 @@ func NewBaseRunner(runnerType RunnerType) *BaseRunner {
 	}
 }
 
-func (b *BaseRunner) AddLog(projectID, level, message string) {
+func (b *BaseRunner) AddLog(projectID, level, message string) domain.LogEntry {
 	entry := domain.LogEntry{
 		ProjectID: projectID,
 		Level:     level,
 		Message:   message,
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
+	b.AddLogEntry(entry)
+	return entry
+}
+
+func (b *BaseRunner) AddLogEntry(entry domain.LogEntry) {
+	b.logMu.Lock()
+	defer b.logMu.Unlock()
 
 	b.LogBuffer = append(b.LogBuffer, entry)
 
@@ -67,6 +88,9 @@ func (b *BaseRunner) AddLog(projectID, level, message string) {
 }
 
 func (b *BaseRunner) GetLogsFromBuffer(projectID string, tail int) []domain.LogEntry {
+	b.logMu.RLock()
+	defer b.logMu.RUnlock()
+
 	projectLogs := []domain.LogEntry{}
 	for _, log := range b.LogBuffer {
 		if log.ProjectID == projectID {
@@ -82,6 +106,9 @@ func (b *BaseRunner) GetLogsFromBuffer(projectID string, tail int) []domain.LogE
 }
 
 func (b *BaseRunner) ClearLogs(projectID string) {
+	b.logMu.Lock()
+	defer b.logMu.Unlock()
+
 	filtered := []domain.LogEntry{}
 	for _, log := range b.LogBuffer {
 		if log.ProjectID != projectID {
@@ -90,3 +117,73 @@ func (b *BaseRunner) ClearLogs(projectID string) {
 	}
 	b.LogBuffer = filtered
 }
+
+type LogFunc func(entry domain.LogEntry)
+
+// ExitInfo descreve como o processo principal terminou: o código de saída ou,
+// quando ele foi morto por um sinal, o nome do sinal (e Code -1).
+type ExitInfo struct {
+	Code   int
+	Signal string
+}
+
+// StatusFunc recebe o novo status do projeto. exit vem preenchido quando o
+// runner sabe como o processo terminou.
+type StatusFunc func(projectID string, status domain.Status, lastError string, exit *ExitInfo)
+
+// CallbackRunner é implementado pelos runners que notificam logs e mudanças de
+// status do processo de forma assíncrona.
+type CallbackRunner interface {
+	SetLogCallback(projectID string, fn LogFunc)
+	SetStatusCallback(projectID string, fn StatusFunc)
+}
+
+type callbackRegistry struct {
+	logCallbacks    map[string]LogFunc
+	cbMu            sync.RWMutex
+	statusCallbacks map[string]StatusFunc
+	stMu            sync.RWMutex
+}
+
+func newCallbackRegistry() *callbackRegistry {
+	return &callbackRegistry{
+		logCallbacks:    make(map[string]LogFunc),
+		statusCallbacks: make(map[string]StatusFunc),
+	}
+}
+
+func (c *callbackRegistry) SetLogCallback(projectID string, fn LogFunc) {
+	c.cbMu.Lock()
+	defer c.cbMu.Unlock()
+	c.logCallbacks[projectID] = fn
+}
+
+func (c *callbackRegistry) removeLogCallback(projectID string) {
+	c.cbMu.Lock()
+	defer c.cbMu.Unlock()
+	delete(c.logCallbacks, projectID)
+}
+
+func (c *callbackRegistry) getLogCallback(projectID string) LogFunc {
+	c.cbMu.RLock()
+	defer c.cbMu.RUnlock()
+	return c.logCallbacks[projectID]
+}
+
+func (c *callbackRegistry) SetStatusCallback(projectID string, fn StatusFunc) {
+	c.stMu.Lock()
+	defer c.stMu.Unlock()
+	c.statusCallbacks[projectID] = fn
+}
+
+func (c *callbackRegistry) removeStatusCallback(projectID string) {
+	c.stMu.Lock()
+	defer c.stMu.Unlock()
+	delete(c.statusCallbacks, projectID)
+}
+
+func (c *callbackRegistry) getStatusCallback(projectID string) StatusFunc {
+	c.stMu.RLock()
+	defer c.stMu.RUnlock()
+	return c.statusCallbacks[projectID]
+}
@@ -0,0 +1,196 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+)
+
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+
+	// maxLogLineBytes limita o tamanho de uma linha de log; linhas maiores
+	// (bundles minificados, dumps) são quebradas em pedaços desse tamanho.
+	maxLogLineBytes = 16 * 1024
+)
+
+var (
+	ansiEscape   = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[@-Z\\-_]`)
+	logfmtLevel  = regexp.MustCompile(`(?i)(?:^|\s)(?:level|lvl|severity)="?([a-z]+)`)
+	bracketLevel = regexp.MustCompile(`(?i)\[(trace|debug|info|notice|warn|warning|error|err|fatal|panic|crit|critical)\]`)
+	prefixLevel  = regexp.MustCompile(`^(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERROR|FATAL|PANIC|CRITICAL)\b[:\s]`)
+)
+
+// scanLines entrega cada linha de r sem o terminador. Linhas acima de
+// maxLogLineBytes são entregues em pedaços, sem travar a leitura.
+func scanLines(r io.Reader, onLine func(string)) error {
+	reader := bufio.NewReaderSize(r, maxLogLineBytes)
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(chunk) > 0 {
+			onLine(string(bytes.TrimRight(chunk, "\r\n")))
+		}
+		switch {
+		case err == nil, errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err == io.EOF:
+			return nil
+		default:
+			return err
+		}
+	}
+}
+
+// cleanLogLine remove sequências ANSI e aplica os \r como um terminal faria:
+// barras de progresso que se reescrevem ficam só com o último estado.
+func cleanLogLine(line string) string {
+	line = ansiEscape.ReplaceAllString(line, "")
+	line = strings.TrimRight(line, "\r")
+	if i := strings.LastIndexByte(line, '\r'); i >= 0 {
+		line = line[i+1:]
+	}
+	return line
+}
+
+// parseLogLine monta a entrada de log de uma linha já enquadrada, detectando o
+// nível (JSON, level=, [ERROR], ERROR:) e guardando os campos de logs JSON.
+// Sem nível reconhecível, stderr conta como error e stdout como info.
+func parseLogLine(projectID, stream, line string) (domain.LogEntry, bool) {
+	line = cleanLogLine(line)
+	if strings.TrimSpace(line) == "" {
+		return domain.LogEntry{}, false
+	}
+
+	entry := domain.LogEntry{
+		ProjectID: projectID,
+		Stream:    stream,
+		Message:   line,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	if level, message, fields, ok := parseJSONLog(line); ok {
+		entry.Level = level
+		entry.Fields = fields
+		if message != "" {
+			entry.Message = message
+		}
+	} else if m := logfmtLevel.FindStringSubmatch(line); m != nil {
+		entry.Level = normalizeLevel(m[1])
+	} else if m := bracketLevel.FindStringSubmatch(line); m != nil {
+		entry.Level = normalizeLevel(m[1])
+	} else if m := prefixLevel.FindStringSubmatch(line); m != nil {
+		entry.Level = normalizeLevel(m[1])
+	}
+
+	if entry.Level == "" {
+		entry.Level = "info"
+		if stream == StreamStderr {
+			entry.Level = "error"
+		}
+	}
+
+	return entry, true
+}
+
+func parseJSONLog(line string) (level, message string, fields map[string]string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") || !strings.HasSuffix(trimmed, "}") {
+		return "", "", nil, false
+	}
+
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return "", "", nil, false
+	}
+
+	fields = make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch strings.ToLower(key) {
+		case "msg", "message":
+			if s, isString := value.(string); isString && message == "" {
+				message = s
+				continue
+			}
+		case "level", "lvl", "severity", "log.level":
+			if level == "" {
+				if level = jsonLevel(value); level != "" {
+					continue
+				}
+			}
+		}
+		fields[key] = fieldString(value)
+	}
+
+	if len(fields) == 0 {
+		fields = nil
+	}
+	return level, message, fields, true
+}
+
+// jsonLevel aceita níveis textuais e os numéricos do pino/bunyan
+// (10 trace, 20 debug, 30 info, 40 warn, 50 error, 60 fatal).
+func jsonLevel(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return normalizeLevel(v)
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return ""
+		}
+		switch {
+		case n <= 20:
+			return "debug"
+		case n <= 30:
+			return "info"
+		case n <= 40:
+			return "warn"
+		default:
+			return "error"
+		}
+	}
+	return ""
+}
+
+func normalizeLevel(level string) string {
+	switch strings.ToLower(level) {
+	case "trace", "debug", "verbose":
+		return "debug"
+	case "info", "information", "notice":
+		return "info"
+	case "warn", "warning":
+		return "warn"
+	case "error", "err", "fatal", "panic", "crit", "critical", "alert", "emerg":
+		return "error"
+	}
+	return ""
+}
+
+func fieldString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
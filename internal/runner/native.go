@@ -17,77 +17,39 @@ import (
 	"github.com/Maycon-Santos/relief/pkg/shellenv"
 )
 
-type LogFunc func(level, message string)
-
-type StatusFunc func(projectID string, status domain.Status, lastError string)
-
 type NativeRunner struct {
 	*BaseRunner
-	processes       map[string]*ProcessInfo
-	mu              sync.RWMutex
-	logger          *logger.Logger
-	logCallbacks    map[string]LogFunc
-	cbMu            sync.RWMutex
-	statusCallbacks map[string]StatusFunc
-	stMu            sync.RWMutex
+	*callbackRegistry
+	processes map[string]*ProcessInfo
+	mu        sync.RWMutex
+	logger    *logger.Logger
 }
 
 type ProcessInfo struct {
 	Project   *domain.Project
 	Cmd       *exec.Cmd
 	PID       int
+	PGID      int
 	StartedAt time.Time
 	Stdout    io.ReadCloser
 	Stderr    io.ReadCloser
 	Cancel    context.CancelFunc
+
+	stopping bool
+	done     chan struct{}
+	output   sync.WaitGroup
+	metrics  processSampler
 }
 
 func NewNativeRunner(log *logger.Logger) *NativeRunner {
 	return &NativeRunner{
-		BaseRunner:      NewBaseRunner(RunnerTypeNative),
-		processes:       make(map[string]*ProcessInfo),
-		logger:          log,
-		logCallbacks:    make(map[string]LogFunc),
-		statusCallbacks: make(map[string]StatusFunc),
+		BaseRunner:       NewBaseRunner(RunnerTypeNative),
+		callbackRegistry: newCallbackRegistry(),
+		processes:        make(map[string]*ProcessInfo),
+		logger:           log,
 	}
 }
 
-func (r *NativeRunner) SetLogCallback(projectID string, fn LogFunc) {
-	r.cbMu.Lock()
-	defer r.cbMu.Unlock()
-	r.logCallbacks[projectID] = fn
-}
-
-func (r *NativeRunner) removeLogCallback(projectID string) {
-	r.cbMu.Lock()
-	defer r.cbMu.Unlock()
-	delete(r.logCallbacks, projectID)
-}
-
-func (r *NativeRunner) getLogCallback(projectID string) LogFunc {
-	r.cbMu.RLock()
-	defer r.cbMu.RUnlock()
-	return r.logCallbacks[projectID]
-}
-
-func (r *NativeRunner) SetStatusCallback(projectID string, fn StatusFunc) {
-	r.stMu.Lock()
-	defer r.stMu.Unlock()
-	r.statusCallbacks[projectID] = fn
-}
-
-func (r *NativeRunner) removeStatusCallback(projectID string) {
-	r.stMu.Lock()
-	defer r.stMu.Unlock()
-	delete(r.statusCallbacks, projectID)
-}
-
-func (r *NativeRunner) getStatusCallback(projectID string) StatusFunc {
-	r.stMu.RLock()
-	defer r.stMu.RUnlock()
-	return r.statusCallbacks[projectID]
-}
-
 func (r *NativeRunner) Start(ctx context.Context, project *domain.Project) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -96,59 +58,54 @@ func (r *NativeRunner) Start(ctx context.Context, project *domain.Project) error
 		return fmt.Errorf("projeto %s já está em execução", project.Name)
 	}
 
-	var devScript string
-	if project.Manifest != nil {
-		devScript = project.Manifest.GetDevScript()
-	}
-	if devScript == "" {
-		devScript = project.Scripts["dev"]
-	}
-	if devScript == "" {
+	script := devScript(project)
+	if script == "" {
 		return fmt.Errorf("script 'dev' não encontrado no projeto %s", project.Name)
 	}
 
 	r.logger.Info("Starting project with script", map[string]interface{}{
 		"project": project.Name,
-		"script":  devScript,
+		"script":  script,
 	})
 
 	processCtx, cancel := context.WithCancel(ctx)
 
-	cmd := exec.CommandContext(processCtx, "sh", "-c", devScript)
+	cmd := exec.Command("sh", "-c", script)
 	cmd.Dir = project.Path
+	setProcessGroup(cmd)
 
+	// project.Env já vem resolvido pelo App, com PORT e PORT_<NOME>.
 	cmd.Env = shellenv.EnrichedEnv()
 	for key, value := range project.Env {
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
 	}
 
-	if project.Port > 0 {
-		hasPort := false
-		for _, env := range cmd.Env {
-			if strings.HasPrefix(env, "PORT=") {
-				hasPort = true
-				break
-			}
-		}
-		if !hasPort {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("PORT=%d", project.Port))
-		}
-	}
-
-	stdout, err := cmd.StdoutPipe()
+	// Pipes próprios em vez de StdoutPipe: o Wait fecharia a leitura assim que
+	// o sh saísse, perdendo as últimas linhas ainda no buffer.
+	stdout, stdoutW, err := os.Pipe()
 	if err != nil {
 		cancel()
 		return fmt.Errorf("erro ao criar pipe stdout: %w", err)
 	}
 
-	stderr, err := cmd.StderrPipe()
+	stderr, stderrW, err := os.Pipe()
 	if err != nil {
 		cancel()
+		stdout.Close()
+		stdoutW.Close()
 		return fmt.Errorf("erro ao criar pipe stderr: %w", err)
 	}
 
-	if err := cmd.Start(); err != nil {
+	cmd.Stdout = stdoutW
+	cmd.Stderr = stderrW
+
+	err = cmd.Start()
+	stdoutW.Close()
+	stderrW.Close()
+	if err != nil {
 		cancel()
+		stdout.Close()
+		stderr.Close()
 		return fmt.Errorf("erro ao iniciar processo: %w", err)
 	}
 
@@ -156,24 +113,39 @@ func (r *NativeRunner) Start(ctx context.Context, project *domain.Project) error
 		Project:   project,
 		Cmd:       cmd,
 		PID:       cmd.Process.Pid,
+		PGID:      cmd.Process.Pid,
 		StartedAt: time.Now(),
 		Stdout:    stdout,
 		Stderr:    stderr,
 		Cancel:    cancel,
+		done:      make(chan struct{}),
 	}
 	r.processes[project.ID] = processInfo
 
-	project.PID = cmd.Process.Pid
-	project.UpdateStatus(domain.StatusRunning)
+	project.PID = processInfo.PID
+	project.PGID = processInfo.PGID
+	project.UpdateStatus(domain.StatusStarting)
 
-	go r.captureOutput(project.ID, stdout, "info")
-	go r.captureOutput(project.ID, stderr, "error")
+	processInfo.output.Add(2)
+	go r.captureOutput(processInfo, stdout, StreamStdout)
+	go r.captureOutput(processInfo, stderr, StreamStderr)
 
-	go r.monitorProcess(project.ID)
+	go r.monitorProcess(processInfo)
+	go r.sampleMetrics(processInfo)
+
+	// Se o contexto do App for cancelado, encerra o grupo inteiro e não só o sh.
+	go func() {
+		select {
+		case <-processCtx.Done():
+			r.terminate(processInfo)
+		case <-processInfo.done:
+		}
+	}()
 
 	r.logger.Info("Projeto iniciado", map[string]interface{}{
 		"project": project.Name,
-		"pid":     cmd.Process.Pid,
+		"pid":     processInfo.PID,
+		"pgid":    processInfo.PGID,
 	})
 
 	return nil
@@ -181,33 +153,24 @@ func (r *NativeRunner) Start(ctx context.Context, project *domain.Project) error
 
 func (r *NativeRunner) Stop(ctx context.Context, projectID string) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	processInfo, exists := r.processes[projectID]
+	if exists {
+		processInfo.stopping = true
+	}
+	r.mu.Unlock()
+
 	if !exists {
 		return fmt.Errorf("projeto não está em execução")
 	}
 
+	r.terminate(processInfo)
 	processInfo.Cancel()
 
-	done := make(chan error, 1)
-	go func() {
-		done <- processInfo.Cmd.Wait()
-	}()
-
-	select {
-	case <-time.After(5 * time.Second):
-		if processInfo.Cmd.Process != nil {
-			r.logger.Warn("Forçando término do processo", map[string]interface{}{
-				"project": processInfo.Project.Name,
-				"pid":     processInfo.PID,
-			})
-			processInfo.Cmd.Process.Signal(syscall.SIGKILL)
-		}
-	case <-done:
+	r.mu.Lock()
+	if r.processes[projectID] == processInfo {
+		delete(r.processes, projectID)
 	}
-
-	delete(r.processes, projectID)
+	r.mu.Unlock()
 	r.removeLogCallback(projectID)
 	r.removeStatusCallback(projectID)
 
@@ -218,6 +181,37 @@ func (r *NativeRunner) Stop(ctx context.Context, projectID string) error {
 	return nil
 }
 
+// terminate encerra o grupo de processos do projeto: SIGTERM, espera o
+// stop_grace_period e então SIGKILL no grupo. Retorna quando o sh foi coletado.
+func (r *NativeRunner) terminate(processInfo *ProcessInfo) {
+	grace := processInfo.Project.Manifest.StopGracePeriodDuration()
+
+	forced, err := TerminateProcessGroup(processInfo.PGID, grace)
+	if err != nil {
+		r.logger.Warn("Erro ao encerrar grupo de processos", map[string]interface{}{
+			"project": processInfo.Project.Name,
+			"pgid":    processInfo.PGID,
+			"error":   err.Error(),
+		})
+	}
+	if forced {
+		r.logger.Warn("Forçando término do grupo de processos", map[string]interface{}{
+			"project": processInfo.Project.Name,
+			"pgid":    processInfo.PGID,
+			"grace":   grace.String(),
+		})
+	}
+
+	select {
+	case <-processInfo.done:
+	case <-time.After(5 * time.Second):
+		r.logger.Warn("Processo não terminou após SIGKILL", map[string]interface{}{
+			"project": processInfo.Project.Name,
+			"pid":     processInfo.PID,
+		})
+	}
+}
+
 func (r *NativeRunner) Status(projectID string) (*RunnerStatus, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -232,14 +226,39 @@ func (r *NativeRunner) Status(projectID string) (*RunnerStatus, error) {
 
 	uptime := time.Since(processInfo.StartedAt)
 
-	return &RunnerStatus{
+	status := &RunnerStatus{
 		ProjectID: projectID,
 		Status:    domain.StatusRunning,
 		PID:       processInfo.PID,
 		Port:      processInfo.Project.Port,
 		Uptime:    uptime,
 		Message:   fmt.Sprintf("Rodando há %s", uptime.Round(time.Second)),
-	}, nil
+	}
+
+	if metrics := processInfo.metrics.snapshot(); metrics != nil {
+		status.MemoryUsed = metrics.MemoryUsed
+		status.CPUUsed = metrics.CPUUsed
+		status.Metrics = metrics
+	}
+
+	return status, nil
+}
+
+// sampleMetrics coleta CPU, memória, threads e portas da árvore do processo
+// até ele terminar.
+func (r *NativeRunner) sampleMetrics(processInfo *ProcessInfo) {
+	ticker := time.NewTicker(metricsInterval)
+	defer ticker.Stop()
+
+	for {
+		processInfo.metrics.sample(processInfo.PID, processInfo.PGID)
+
+		select {
+		case <-processInfo.done:
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 func (r *NativeRunner) GetLogs(projectID string, tail int) ([]domain.LogEntry, error) {
@@ -258,52 +277,71 @@ func (r *NativeRunner) Restart(ctx context.Context, project *domain.Project) err
 	return r.Start(ctx, project)
 }
 
-func (r *NativeRunner) captureOutput(projectID string, reader io.ReadCloser, level string) {
+func (r *NativeRunner) captureOutput(processInfo *ProcessInfo, reader io.ReadCloser, stream string) {
+	defer processInfo.output.Done()
 	defer reader.Close()
 
-	buf := make([]byte, 4096)
-	for {
-		n, err := reader.Read(buf)
-		if n > 0 {
-			message := strings.TrimSpace(string(buf[:n]))
-			if message != "" {
-				r.AddLog(projectID, level, message)
-				if fn := r.getLogCallback(projectID); fn != nil {
-					fn(level, message)
-				}
-			}
+	projectID := processInfo.Project.ID
+
+	err := scanLines(reader, func(line string) {
+		entry, ok := parseLogLine(projectID, stream, line)
+		if !ok {
+			return
 		}
-		if err != nil {
-
-			if err != io.EOF && !errors.Is(err, os.ErrClosed) && !strings.Contains(err.Error(), "file already closed") {
-				r.logger.Error("Erro ao ler output", err, map[string]interface{}{
-					"project_id": projectID,
-				})
-			}
-			break
+		r.AddLogEntry(entry)
+		if fn := r.getLogCallback(projectID); fn != nil {
+			fn(entry)
 		}
+	})
+	if err != nil && !errors.Is(err, os.ErrClosed) && !strings.Contains(err.Error(), "file already closed") {
+		r.logger.Error("Erro ao ler output", err, map[string]interface{}{
+			"project_id": projectID,
+		})
 	}
 }
 
-func (r *NativeRunner) monitorProcess(projectID string) {
-	r.mu.RLock()
-	processInfo, exists := r.processes[projectID]
-	r.mu.RUnlock()
-
-	if !exists {
-		return
+func (r *NativeRunner) emitLog(projectID, level, message string) {
+	entry := r.AddLog(projectID, level, message)
+	if fn := r.getLogCallback(projectID); fn != nil {
+		fn(entry)
 	}
+}
+
+func (r *NativeRunner) monitorProcess(processInfo *ProcessInfo) {
+	projectID := processInfo.Project.ID
 
 	err := processInfo.Cmd.Wait()
 
 	r.mu.Lock()
-	delete(r.processes, projectID)
+	stopping := processInfo.stopping
+	if r.processes[projectID] == processInfo {
+		delete(r.processes, projectID)
+	}
 	r.mu.Unlock()
 
-	if err != nil {
+	// Filhos que sobreviveram ao sh continuam no grupo; não deixa órfãos.
+	if processGroupAlive(processInfo.PGID) && !stopping {
+		_ = signalProcessGroup(processInfo.PGID, syscall.SIGKILL)
+	}
+
+	// Drena o que ainda estiver nos pipes antes de anunciar o término.
+	drained := make(chan struct{})
+	go func() {
+		processInfo.output.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(2 * time.Second):
+	}
+	close(processInfo.done)
+
+	exit := exitInfo(processInfo.Cmd.ProcessState)
+
+	if err != nil && !stopping {
 		exitCode := -1
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode = exitErr.ExitCode()
+		if exit != nil {
+			exitCode = exit.Code
 		}
 
 		r.logger.Warn("Processo terminou com erro", map[string]interface{}{
@@ -312,24 +350,20 @@ func (r *NativeRunner) monitorProcess(projectID string) {
 		})
 
 		msg := fmt.Sprintf("Processo terminou com código %d", exitCode)
-		r.AddLog(projectID, "error", msg)
-		if fn := r.getLogCallback(projectID); fn != nil {
-			fn("error", msg)
+		if exit != nil && exit.Signal != "" {
+			msg = fmt.Sprintf("Processo encerrado pelo sinal %s", exit.Signal)
 		}
+		r.emitLog(projectID, "error", msg)
 		if fn := r.getStatusCallback(projectID); fn != nil {
-			fn(projectID, domain.StatusError, msg)
+			fn(projectID, domain.StatusError, msg, exit)
 		}
 	} else {
 		r.logger.Info("Processo terminou", map[string]interface{}{
 			"project": processInfo.Project.Name,
 		})
-		msg := "Processo encerrado normalmente"
-		r.AddLog(projectID, "info", msg)
-		if fn := r.getLogCallback(projectID); fn != nil {
-			fn("info", msg)
-		}
+		r.emitLog(projectID, "info", "Processo encerrado normalmente")
 		if fn := r.getStatusCallback(projectID); fn != nil {
-			fn(projectID, domain.StatusStopped, "")
+			fn(projectID, domain.StatusStopped, "", exit)
 		}
 	}
 	r.removeLogCallback(projectID)
@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+)
+
+const processGroupPollInterval = 100 * time.Millisecond
+
+// TerminateProcessGroup envia SIGTERM a todo o grupo de processos, aguarda até
+// grace para que todos saiam e, se algum continuar vivo, envia SIGKILL ao
+// grupo. Retorna true quando foi preciso forçar o término.
+func TerminateProcessGroup(pgid int, grace time.Duration) (bool, error) {
+	if !processGroupAlive(pgid) {
+		return false, nil
+	}
+
+	if err := signalProcessGroup(pgid, syscall.SIGTERM); err != nil {
+		return false, fmt.Errorf("erro ao enviar SIGTERM ao grupo %d: %w", pgid, err)
+	}
+	if waitProcessGroupExit(pgid, grace) {
+		return false, nil
+	}
+
+	if err := signalProcessGroup(pgid, syscall.SIGKILL); err != nil {
+		return true, fmt.Errorf("erro ao enviar SIGKILL ao grupo %d: %w", pgid, err)
+	}
+	waitProcessGroupExit(pgid, 2*time.Second)
+	return true, nil
+}
+
+func waitProcessGroupExit(pgid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for processGroupAlive(pgid) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(processGroupPollInterval)
+	}
+	return true
+}
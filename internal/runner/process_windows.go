@@ -0,0 +1,52 @@
+//go:build windows
+
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// signalProcessGroup usa taskkill /T, que encerra a árvore a partir do PID
+// líder. Sem /F o Windows pede o encerramento; com /F força.
+func signalProcessGroup(pgid int, sig syscall.Signal) error {
+	if pgid <= 0 {
+		return errors.New("pgid inválido")
+	}
+	args := []string{"/T", "/PID", strconv.Itoa(pgid)}
+	if sig == syscall.SIGKILL {
+		args = append([]string{"/F"}, args...)
+	}
+	_ = exec.Command("taskkill", args...).Run()
+	return nil
+}
+
+func processGroupAlive(pgid int) bool {
+	if pgid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pgid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
+
+// exitInfo lê o código de saída do processo. No Windows não há sinais.
+func exitInfo(state *os.ProcessState) *ExitInfo {
+	if state == nil {
+		return nil
+	}
+	return &ExitInfo{Code: state.ExitCode()}
+}
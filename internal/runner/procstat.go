@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	metricsInterval = 2 * time.Second
+
+	// metricsHistorySize guarda 3 minutos de amostras para os gráficos da UI.
+	metricsHistorySize = 90
+)
+
+var errMetricsUnsupported = errors.New("métricas de processo não suportadas neste sistema")
+
+// ProcessMetrics é o consumo de recursos da árvore de processos de um projeto
+// na última amostra, com o histórico recente para gráficos.
+type ProcessMetrics struct {
+	MemoryUsed int64           `json:"memory_used"`
+	CPUUsed    float64         `json:"cpu_used"`
+	Threads    int             `json:"threads"`
+	Processes  int             `json:"processes"`
+	Ports      []int           `json:"ports"`
+	SampledAt  string          `json:"sampled_at"`
+	History    []MetricsSample `json:"history"`
+}
+
+type MetricsSample struct {
+	Time       string  `json:"time"`
+	MemoryUsed int64   `json:"memory_used"`
+	CPUUsed    float64 `json:"cpu_used"`
+}
+
+// treeUsage é o que a plataforma sabe ler da árvore de processos. CPUTime é
+// acumulado desde o início de cada processo; o percentual sai da diferença
+// entre duas amostras.
+type treeUsage struct {
+	CPUTime   time.Duration
+	RSS       int64
+	Threads   int
+	Processes int
+	Ports     []int
+}
+
+// processSampler amostra periodicamente a árvore de um processo.
+type processSampler struct {
+	mu      sync.Mutex
+	current ProcessMetrics
+	history []MetricsSample
+	lastCPU time.Duration
+	lastAt  time.Time
+}
+
+func (s *processSampler) sample(pid, pgid int) {
+	usage, err := readTreeUsage(pid, pgid)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var cpu float64
+	if !s.lastAt.IsZero() && usage.CPUTime >= s.lastCPU {
+		if elapsed := now.Sub(s.lastAt); elapsed > 0 {
+			cpu = float64(usage.CPUTime-s.lastCPU) / float64(elapsed) * 100
+		}
+	}
+	s.lastCPU = usage.CPUTime
+	s.lastAt = now
+
+	timestamp := now.Format(time.RFC3339)
+	s.current = ProcessMetrics{
+		MemoryUsed: usage.RSS,
+		CPUUsed:    cpu,
+		Threads:    usage.Threads,
+		Processes:  usage.Processes,
+		Ports:      usage.Ports,
+		SampledAt:  timestamp,
+	}
+
+	s.history = append(s.history, MetricsSample{Time: timestamp, MemoryUsed: usage.RSS, CPUUsed: cpu})
+	if len(s.history) > metricsHistorySize {
+		s.history = s.history[len(s.history)-metricsHistorySize:]
+	}
+}
+
+// snapshot retorna nil enquanto nenhuma amostra foi coletada.
+func (s *processSampler) snapshot() *ProcessMetrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastAt.IsZero() {
+		return nil
+	}
+	metrics := s.current
+	metrics.History = append([]MetricsSample(nil), s.history...)
+	return &metrics
+}
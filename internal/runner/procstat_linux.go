@@ -0,0 +1,145 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Maycon-Santos/relief/pkg/portowner"
+)
+
+// clockTicks é o USER_HZ do kernel, fixo em 100 em todas as arquiteturas que
+// o Linux expõe em /proc/<pid>/stat.
+const clockTicks = 100
+
+type procStat struct {
+	ppid    int
+	pgrp    int
+	cpu     uint64
+	threads int
+	rss     int64
+}
+
+// readProcStat lê /proc/<pid>/stat. O nome do comando vem entre parênteses e
+// pode conter espaços, então os campos são contados a partir do último ')'.
+func readProcStat(pid int) (*procStat, error) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return nil, err
+	}
+
+	line := string(data)
+	end := strings.LastIndexByte(line, ')')
+	if end < 0 {
+		return nil, os.ErrInvalid
+	}
+	// fields[0] é o campo 3 (state) da documentação de proc(5).
+	fields := strings.Fields(line[end+1:])
+	if len(fields) < 22 {
+		return nil, os.ErrInvalid
+	}
+
+	ppid, _ := strconv.Atoi(fields[1])
+	pgrp, _ := strconv.Atoi(fields[2])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	threads, _ := strconv.Atoi(fields[17])
+	rssPages, _ := strconv.ParseInt(fields[21], 10, 64)
+
+	return &procStat{
+		ppid:    ppid,
+		pgrp:    pgrp,
+		cpu:     utime + stime,
+		threads: threads,
+		rss:     rssPages * int64(os.Getpagesize()),
+	}, nil
+}
+
+// readTreeUsage soma o processo pid, seus descendentes e qualquer outro
+// membro do grupo pgid (filhos que ficaram órfãos continuam no grupo).
+func readTreeUsage(pid, pgid int) (*treeUsage, error) {
+	root, err := readProcStat(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	stats := map[int]*procStat{pid: root}
+	children := map[int][]int{}
+	for _, entry := range entries {
+		p, err := strconv.Atoi(entry.Name())
+		if err != nil || p == pid {
+			continue
+		}
+		stat, err := readProcStat(p)
+		if err != nil {
+			continue
+		}
+		stats[p] = stat
+		children[stat.ppid] = append(children[stat.ppid], p)
+	}
+
+	tree := map[int]bool{}
+	queue := []int{pid}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if tree[p] {
+			continue
+		}
+		tree[p] = true
+		queue = append(queue, children[p]...)
+	}
+	if pgid > 0 {
+		for p, stat := range stats {
+			if stat.pgrp == pgid {
+				tree[p] = true
+			}
+		}
+	}
+
+	usage := &treeUsage{}
+	var ticks uint64
+	for p := range tree {
+		stat := stats[p]
+		ticks += stat.cpu
+		usage.RSS += stat.rss
+		usage.Threads += stat.threads
+		usage.Processes++
+	}
+	usage.CPUTime = time.Duration(ticks) * time.Second / clockTicks
+	usage.Ports = listeningPorts(tree)
+
+	return usage, nil
+}
+
+// listeningPorts retorna as portas TCP em LISTEN abertas pelos processos da árvore.
+func listeningPorts(tree map[int]bool) []int {
+	pids := make([]int, 0, len(tree))
+	for pid := range tree {
+		pids = append(pids, pid)
+	}
+
+	listeners, err := portowner.Listeners(pids...)
+	if err != nil {
+		return nil
+	}
+
+	seen := map[int]bool{}
+	ports := []int{}
+	for _, l := range listeners {
+		if !seen[l.Port] {
+			seen[l.Port] = true
+			ports = append(ports, l.Port)
+		}
+	}
+	sort.Ints(ports)
+	return ports
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package runner
+
+func readTreeUsage(pid, pgid int) (*treeUsage, error) {
+	return nil, errMetricsUnsupported
+}
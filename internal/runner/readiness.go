@@ -0,0 +1,198 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"github.com/Maycon-Santos/relief/pkg/shellenv"
+)
+
+// Readiness acompanha o readiness probe de um projeto recém-iniciado. Para
+// probes de log, o App repassa cada linha de saída em ObserveLog.
+type Readiness struct {
+	spec    *domain.ReadinessProbe
+	project *domain.Project
+	client  *http.Client
+	pattern *regexp.Regexp
+
+	matchOnce sync.Once
+	matched   chan struct{}
+}
+
+func NewReadiness(project *domain.Project) (*Readiness, error) {
+	if project.Manifest == nil || project.Manifest.Readiness == nil {
+		return nil, nil
+	}
+
+	spec := project.Manifest.Readiness
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &Readiness{
+		spec:    spec,
+		project: project,
+		client:  &http.Client{Timeout: probeAttemptTimeout(spec)},
+		matched: make(chan struct{}),
+	}
+
+	if spec.Log != "" {
+		r.pattern = regexp.MustCompile(spec.Log)
+	}
+
+	return r, nil
+}
+
+func (r *Readiness) Kind() string {
+	return r.spec.Kind()
+}
+
+func (r *Readiness) ObserveLog(message string) {
+	if r.pattern == nil || !r.pattern.MatchString(message) {
+		return
+	}
+	r.matchOnce.Do(func() { close(r.matched) })
+}
+
+// Wait bloqueia até o probe passar, o contexto ser cancelado ou o timeout
+// configurado expirar.
+func (r *Readiness) Wait(ctx context.Context) error {
+	timeout := r.spec.TimeoutDuration()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if r.pattern != nil {
+		select {
+		case <-r.matched:
+			return nil
+		case <-ctx.Done():
+			return r.waitError(ctx, timeout, fmt.Errorf("padrão %q não apareceu nos logs", r.spec.Log))
+		}
+	}
+
+	if delay := r.spec.InitialDelayDuration(); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return r.waitError(ctx, timeout, nil)
+		}
+	}
+
+	ticker := time.NewTicker(r.spec.IntervalDuration())
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		if lastErr = r.check(ctx); lastErr == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return r.waitError(ctx, timeout, lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Readiness) waitError(ctx context.Context, timeout time.Duration, lastErr error) error {
+	if ctx.Err() != context.DeadlineExceeded {
+		return ctx.Err()
+	}
+	if lastErr != nil {
+		return fmt.Errorf("readiness probe (%s) não passou em %s: %w", r.Kind(), timeout, lastErr)
+	}
+	return fmt.Errorf("readiness probe (%s) não passou em %s", r.Kind(), timeout)
+}
+
+func (r *Readiness) check(ctx context.Context) error {
+	switch {
+	case r.spec.HTTP != "":
+		return r.checkHTTP(ctx)
+	case r.spec.TCP > 0:
+		return r.checkTCP(ctx)
+	case r.spec.Command != "":
+		return r.checkCommand(ctx)
+	}
+	return fmt.Errorf("readiness probe sem verificação definida")
+}
+
+func (r *Readiness) checkHTTP(ctx context.Context) error {
+	url := r.spec.HTTP
+	if strings.HasPrefix(url, "/") {
+		if r.project.Port == 0 {
+			return fmt.Errorf("probe http %s precisa de uma porta no projeto", url)
+		}
+		url = fmt.Sprintf("http://127.0.0.1:%d%s", r.project.Port, url)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("url inválida no probe http: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if expected := r.spec.ExpectStatus; expected != 0 {
+		if resp.StatusCode != expected {
+			return fmt.Errorf("status %d, esperado %d", resp.StatusCode, expected)
+		}
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (r *Readiness) checkTCP(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: probeAttemptTimeout(r.spec)}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(r.spec.TCP)))
+	if err != nil {
+		return err
+	}
+	conn.Close()
+	return nil
+}
+
+func (r *Readiness) checkCommand(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, probeAttemptTimeout(r.spec))
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", r.spec.Command)
+	cmd.Dir = r.project.Path
+	cmd.Env = shellenv.EnrichedEnv()
+	for key, value := range r.project.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if out := strings.TrimSpace(string(output)); out != "" {
+			return fmt.Errorf("%w: %s", err, out)
+		}
+		return err
+	}
+	return nil
+}
+
+func probeAttemptTimeout(spec *domain.ReadinessProbe) time.Duration {
+	timeout := spec.IntervalDuration()
+	if timeout < 2*time.Second {
+		timeout = 2 * time.Second
+	}
+	return timeout
+}
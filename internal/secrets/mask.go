@@ -0,0 +1,84 @@
+package secrets
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask é o texto que substitui um segredo nos logs.
+const Mask = "********"
+
+// minMaskLength evita que segredos muito curtos ("1", "no") apaguem trechos
+// aleatórios dos logs.
+const minMaskLength = 4
+
+// Masker guarda os segredos resolvidos de cada projeto para removê-los das
+// linhas de log antes de serem gravadas ou enviadas ao frontend.
+type Masker struct {
+	mu      sync.RWMutex
+	secrets map[string][]string
+}
+
+func NewMasker() *Masker {
+	return &Masker{secrets: map[string][]string{}}
+}
+
+// Set troca os segredos do projeto pelos da execução atual.
+func (m *Masker) Set(projectID string, values []string) {
+	list := make([]string, 0, len(values))
+	seen := map[string]bool{}
+	add := func(v string) {
+		if len(v) >= minMaskLength && !seen[v] {
+			seen[v] = true
+			list = append(list, v)
+		}
+	}
+	for _, v := range values {
+		add(v)
+		// Cada linha de log é mascarada sozinha, então segredos de várias
+		// linhas (chaves privadas) também entram linha a linha.
+		if strings.Contains(v, "\n") {
+			for _, line := range strings.Split(v, "\n") {
+				add(strings.TrimSpace(line))
+			}
+		}
+	}
+	// Os mais longos primeiro, para que um segredo contido em outro não deixe
+	// sobras do maior.
+	sort.Slice(list, func(i, j int) bool { return len(list[i]) > len(list[j]) })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(list) == 0 {
+		delete(m.secrets, projectID)
+		return
+	}
+	m.secrets[projectID] = list
+}
+
+// Mask substitui os segredos do projeto em s.
+func (m *Masker) Mask(projectID, s string) string {
+	m.mu.RLock()
+	list := m.secrets[projectID]
+	m.mu.RUnlock()
+
+	return maskSorted(s, list)
+}
+
+// MaskValues substitui values em s, para saídas que não passam pelo Masker
+// (scripts executados sob demanda).
+func MaskValues(s string, values []string) string {
+	m := NewMasker()
+	m.Set("", values)
+	return m.Mask("", s)
+}
+
+func maskSorted(s string, list []string) string {
+	for _, secret := range list {
+		if strings.Contains(s, secret) {
+			s = strings.ReplaceAll(s, secret, Mask)
+		}
+	}
+	return s
+}
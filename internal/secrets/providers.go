@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Maycon-Santos/relief/pkg/dotenv"
+	"github.com/Maycon-Santos/relief/pkg/pathutil"
+	"github.com/Maycon-Santos/relief/pkg/shellenv"
+)
+
+// keyringProvider lê do chaveiro do sistema: secret://keyring/<serviço>/<conta>.
+// No macOS usa o Keychain (security), no Linux o Secret Service (secret-tool).
+type keyringProvider struct{}
+
+func (keyringProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	service, account, _ := strings.Cut(ref, "/")
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		args := []string{"find-generic-password", "-s", service, "-w"}
+		if account != "" {
+			args = append(args, "-a", account)
+		}
+		cmd = exec.CommandContext(ctx, "security", args...)
+	case "linux":
+		args := []string{"lookup", "service", service}
+		if account != "" {
+			args = append(args, "account", account)
+		}
+		cmd = exec.CommandContext(ctx, "secret-tool", args...)
+	default:
+		return "", fmt.Errorf("chaveiro não suportado em %s", runtime.GOOS)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("segredo não encontrado no chaveiro (%s): %w", cmd.Path, err)
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+// fileProvider lê o conteúdo de um arquivo: secret://file/<caminho>[#CHAVE].
+// Caminhos relativos partem de ~/.relief/secrets; com #CHAVE o arquivo é lido
+// como .env e apenas essa chave é usada.
+type fileProvider struct{}
+
+func (fileProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	path, key, _ := strings.Cut(ref, "#")
+
+	path = pathutil.FromRelativeHome(path)
+	if !filepath.IsAbs(path) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, ".relief", "secrets", path)
+	}
+
+	if key == "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	entries, err := dotenv.Read(path)
+	if err != nil {
+		return "", err
+	}
+	if value, ok := dotenv.ToMap(entries)[key]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("chave '%s' não encontrada em %s", key, path)
+}
+
+// execProvider usa a saída de um comando: secret://exec/<comando>, por exemplo
+// secret://exec/op read op://dev/db/password.
+type execProvider struct{}
+
+func (execProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	cmd := shellenv.CommandContext(ctx, ref)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
@@ -0,0 +1,102 @@
+// Package secrets resolve referências secret://<provedor>/<caminho> usadas como
+// valor de variáveis de ambiente. Os valores só são lidos quando um processo
+// vai iniciar: nada aqui é persistido, e os valores resolvidos ficam
+// registrados no Masker para serem apagados dos logs.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheme é o prefixo das referências.
+const Scheme = "secret://"
+
+// resolveTimeout limita cada leitura, já que keyring e exec podem travar
+// esperando um prompt.
+const resolveTimeout = 15 * time.Second
+
+// Provider lê um segredo. ref é o que vem depois de secret://<provedor>/.
+type Provider interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// ProviderFunc adapta uma função a Provider.
+type ProviderFunc func(ctx context.Context, ref string) (string, error)
+
+func (f ProviderFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+// Registry associa nomes de provedores às implementações.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+// NewRegistry cria um registro com os provedores keyring, file e exec.
+func NewRegistry() *Registry {
+	r := &Registry{providers: map[string]Provider{}}
+	r.Register("keyring", keyringProvider{})
+	r.Register("file", fileProvider{})
+	r.Register("exec", execProvider{})
+	return r
+}
+
+// Register adiciona (ou troca) um provedor.
+func (r *Registry) Register(name string, provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[name] = provider
+}
+
+// IsRef indica se o valor é uma referência a um segredo.
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, Scheme)
+}
+
+// Resolve lê o segredo da referência. Os erros citam apenas a referência,
+// nunca o valor.
+func (r *Registry) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+
+	rest := strings.TrimPrefix(value, Scheme)
+	slash := strings.IndexByte(rest, '/')
+	if slash <= 0 || slash == len(rest)-1 {
+		return "", fmt.Errorf("referência de segredo inválida '%s': use secret://<provedor>/<caminho>", value)
+	}
+	name, ref := rest[:slash], rest[slash+1:]
+
+	r.mu.RLock()
+	provider, ok := r.providers[name]
+	r.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("provedor de segredos '%s' desconhecido (disponíveis: %s)", name, strings.Join(r.names(), ", "))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+
+	secret, err := provider.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler %s: %w", value, err)
+	}
+	return secret, nil
+}
+
+func (r *Registry) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// maxProjectChanges é quantas alterações de definição ficam guardadas por projeto.
+const maxProjectChanges = 500
+
+type definitionField struct {
+	value  string
+	source string
+}
+
+// definitionFields achata a definição do projeto em campos com valor e origem.
+// É o formato de project_fields e o que é comparado para registrar alterações.
+// Valores secret:// ficam como referência: o Env salvo é sempre o da definição,
+// nunca o resolvido.
+func definitionFields(project *domain.Project) map[string]definitionField {
+	fields := map[string]definitionField{}
+	add := func(field, value string) {
+		if value != "" {
+			fields[field] = definitionField{value: value, source: project.Sources[field]}
+		}
+	}
+
+	add("path", project.Path)
+	add("domain", project.Domain)
+	add("type", string(project.Type))
+	// Na definição, Port é a porta fixada na configuração ou no relief.yaml;
+	// a porta alocada ao iniciar fica só na coluna port.
+	if project.Port > 0 {
+		add("port", strconv.Itoa(project.Port))
+	}
+	for name, command := range project.Scripts {
+		add("scripts."+name, command)
+	}
+	for key, value := range project.Env {
+		add("env."+key, value)
+	}
+	add("manifest", project.ManifestHash)
+	return fields
+}
+
+// SaveDefinition grava a definição do projeto (scripts, env, snapshot do
+// relief.yaml e a origem de cada campo) e registra em project_changes o que
+// mudou desde a última vez. A primeira gravação não gera alterações.
+func (r *ProjectRepository) SaveDefinition(project *domain.Project) error {
+	manifest, err := encodeManifest(project.Manifest)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao salvar definição do projeto: %w", err)
+	}
+	defer tx.Rollback()
+
+	previous, err := queryFields(tx, project.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao ler definição do projeto: %w", err)
+	}
+	current := definitionFields(project)
+
+	if _, err := tx.Exec(
+		`UPDATE projects SET path = ?, domain = ?, type = ?, manifest = ?, manifest_hash = ? WHERE id = ?`,
+		project.Path, project.Domain, project.Type, manifest, project.ManifestHash, project.ID,
+	); err != nil {
+		return fmt.Errorf("erro ao salvar definição do projeto: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM project_fields WHERE project_id = ?`, project.ID); err != nil {
+		return fmt.Errorf("erro ao salvar definição do projeto: %w", err)
+	}
+	for field, f := range current {
+		if _, err := tx.Exec(
+			`INSERT INTO project_fields (project_id, field, value, source) VALUES (?, ?, ?, ?)`,
+			project.ID, field, f.value, f.source,
+		); err != nil {
+			return fmt.Errorf("erro ao salvar campo %s: %w", field, err)
+		}
+	}
+
+	if len(previous) > 0 {
+		if err := recordChanges(tx, project.ID, previous, current); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func recordChanges(tx *sql.Tx, projectID string, previous, current map[string]definitionField) error {
+	names := make([]string, 0, len(current)+len(previous))
+	for field := range current {
+		names = append(names, field)
+	}
+	for field := range previous {
+		if _, ok := current[field]; !ok {
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+
+	now := time.Now().Format(time.RFC3339)
+	changed := false
+	for _, field := range names {
+		before, after := previous[field], current[field]
+		if before.value == after.value {
+			continue
+		}
+		source := after.source
+		if source == "" {
+			source = before.source
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO project_changes (project_id, field, old_value, new_value, source, changed_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			projectID, field, before.value, after.value, source, now,
+		); err != nil {
+			return fmt.Errorf("erro ao registrar alteração de %s: %w", field, err)
+		}
+		changed = true
+	}
+
+	if changed {
+		if _, err := tx.Exec(`
+			DELETE FROM project_changes WHERE project_id = ? AND id NOT IN (
+				SELECT id FROM project_changes WHERE project_id = ? ORDER BY id DESC LIMIT ?
+			)`, projectID, projectID, maxProjectChanges,
+		); err != nil {
+			return fmt.Errorf("erro ao limpar alterações antigas: %w", err)
+		}
+	}
+	return nil
+}
+
+// Changes lista as alterações de definição do projeto, das mais recentes para
+// as mais antigas.
+func (r *ProjectRepository) Changes(projectID string, limit int) ([]domain.ProjectChange, error) {
+	if limit <= 0 || limit > maxProjectChanges {
+		limit = maxProjectChanges
+	}
+
+	rows, err := r.db.conn.Query(`
+		SELECT id, project_id, field, old_value, new_value, source, changed_at
+		FROM project_changes WHERE project_id = ? ORDER BY id DESC LIMIT ?
+	`, projectID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar alterações do projeto: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []domain.ProjectChange{}
+	for rows.Next() {
+		var c domain.ProjectChange
+		if err := rows.Scan(&c.ID, &c.ProjectID, &c.Field, &c.OldValue, &c.NewValue, &c.Source, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("erro ao ler alteração do projeto: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// loadDefinition preenche Scripts, Env e Sources a partir de project_fields.
+func (r *ProjectRepository) loadDefinition(project *domain.Project) error {
+	fields, err := queryFields(r.db.conn, project.ID)
+	if err != nil {
+		return err
+	}
+
+	project.Scripts = map[string]string{}
+	project.Env = map[string]string{}
+	project.Sources = map[string]string{}
+	for field, f := range fields {
+		if f.source != "" {
+			project.Sources[field] = f.source
+		}
+		switch {
+		case strings.HasPrefix(field, "scripts."):
+			project.Scripts[strings.TrimPrefix(field, "scripts.")] = f.value
+		case strings.HasPrefix(field, "env."):
+			project.Env[strings.TrimPrefix(field, "env.")] = f.value
+		case field == "port":
+			if port, err := strconv.Atoi(f.value); err == nil && project.Port == 0 {
+				project.Port = port
+			}
+		}
+	}
+	return nil
+}
+
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func queryFields(q querier, projectID string) (map[string]definitionField, error) {
+	rows, err := q.Query(`SELECT field, value, source FROM project_fields WHERE project_id = ?`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := map[string]definitionField{}
+	for rows.Next() {
+		var field string
+		var f definitionField
+		if err := rows.Scan(&field, &f.value, &f.source); err != nil {
+			return nil, err
+		}
+		fields[field] = f
+	}
+	return fields, rows.Err()
+}
+
+// encodeManifest serializa o snapshot do relief.yaml guardado com o projeto.
+func encodeManifest(manifest *domain.Manifest) (sql.NullString, error) {
+	if manifest == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("erro ao serializar relief.yaml: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// decodeManifest lê o snapshot sem validá-lo de novo: ele foi validado quando
+// o relief.yaml foi lido.
+func decodeManifest(data string) *domain.Manifest {
+	if data == "" {
+		return nil
+	}
+	var manifest domain.Manifest
+	if err := yaml.Unmarshal([]byte(data), &manifest); err != nil {
+		return nil
+	}
+	return &manifest
+}
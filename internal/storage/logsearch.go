@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"strings"
+)
+
+const logSearchTriggers = `
+CREATE TRIGGER IF NOT EXISTS logs_fts_insert AFTER INSERT ON logs BEGIN
+    INSERT INTO logs_fts(rowid, message) VALUES (new.id, new.message);
+END;
+CREATE TRIGGER IF NOT EXISTS logs_fts_delete AFTER DELETE ON logs BEGIN
+    INSERT INTO logs_fts(logs_fts, rowid, message) VALUES ('delete', old.id, old.message);
+END;
+`
+
+// setupLogSearch cria o índice FTS5 dos logs, mantido por triggers. Sem o
+// módulo fts5 os triggers são removidos, senão todo INSERT em logs falharia
+// em um banco criado antes por um binário com FTS5.
+func (db *DB) setupLogSearch() {
+	// O IF NOT EXISTS não falha sem o módulo quando a tabela já existe, por
+	// isso a disponibilidade do FTS5 é consultada antes.
+	var fts5 bool
+	_ = db.conn.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&fts5)
+	if !fts5 {
+		_, _ = db.conn.Exec(`DROP TRIGGER IF EXISTS logs_fts_insert; DROP TRIGGER IF EXISTS logs_fts_delete;`)
+		db.logger.Info("Busca full-text de logs indisponível (SQLite sem FTS5), usando LIKE", nil)
+		return
+	}
+
+	if _, err := db.conn.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS logs_fts USING fts5(message, content='logs', content_rowid='id')`); err != nil {
+		db.logger.Warn("Erro ao criar índice de busca de logs", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Triggers ausentes significam índice novo ou defasado (o banco foi usado
+	// por um binário sem FTS5): reconstrói a partir da tabela de logs.
+	var triggers int
+	_ = db.conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN ('logs_fts_insert', 'logs_fts_delete')`).Scan(&triggers)
+
+	if _, err := db.conn.Exec(logSearchTriggers); err != nil {
+		db.logger.Warn("Erro ao criar triggers de busca de logs", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if triggers < 2 {
+		if _, err := db.conn.Exec(`INSERT INTO logs_fts(logs_fts) VALUES ('rebuild')`); err != nil {
+			db.logger.Warn("Erro ao reconstruir índice de busca de logs", map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	db.logSearch = true
+}
+
+// ftsQuery transforma o texto digitado em uma consulta FTS5 segura: cada termo
+// vira uma frase entre aspas, casada por prefixo como no LIKE, e todos
+// precisam aparecer.
+func ftsQuery(search string) string {
+	terms := strings.Fields(search)
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, `"`+strings.ReplaceAll(term, `"`, `""`)+`"*`)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,453 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keepBackups é quantas cópias do banco ficam em data/backups.
+const keepBackups = 5
+
+const schemaMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    applied_at DATETIME NOT NULL
+)`
+
+// Migration é um arquivo de migrations/. NNN_nome.sql aplica a mudança e o
+// NNN_nome.down.sql opcional a desfaz. Cada uma roda uma única vez, dentro de
+// uma transação, e fica registrada em schema_migrations com o checksum do
+// arquivo de subida.
+type Migration struct {
+	Version  int
+	Name     string
+	Checksum string
+	up       string
+	down     string
+}
+
+// MigrationStatus é uma migration embutida no binário ou registrada no banco.
+// Modified indica que o arquivo mudou depois de aplicado; Unknown, que o
+// banco tem uma migration que este binário não conhece (versão mais nova).
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Modified  bool       `json:"modified,omitempty"`
+	Unknown   bool       `json:"unknown,omitempty"`
+	HasDown   bool       `json:"has_down"`
+}
+
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// loadMigrations lê as migrations embutidas, em ordem de versão.
+func loadMigrations() ([]*Migration, error) {
+	entries, err := migrationsFS.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler diretório de migrations: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	downs := map[int]string{}
+	for _, entry := range entries {
+		filename := entry.Name()
+		if entry.IsDir() || filepath.Ext(filename) != ".sql" {
+			continue
+		}
+
+		base := strings.TrimSuffix(filename, ".sql")
+		isDown := strings.HasSuffix(base, ".down")
+		base = strings.TrimSuffix(base, ".down")
+
+		prefix, _, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: o nome deve começar pela versão (001_nome.sql)", filename)
+		}
+
+		content, err := migrationsFS.ReadFile(path.Join("migrations", filename))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler migration %s: %w", filename, err)
+		}
+
+		if isDown {
+			downs[version] = string(content)
+			continue
+		}
+		if other, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("migrations %s e %s têm a mesma versão", other.Name, base)
+		}
+		sum := sha256.Sum256(content)
+		byVersion[version] = &Migration{
+			Version:  version,
+			Name:     base,
+			Checksum: hex.EncodeToString(sum[:]),
+			up:       string(content),
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for version, m := range byVersion {
+		m.down = downs[version]
+		delete(downs, version)
+		migrations = append(migrations, m)
+	}
+	for version := range downs {
+		return nil, fmt.Errorf("migration de descida %03d sem a migration correspondente", version)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// migrate aplica as migrations pendentes. Antes de alterar um banco que já
+// tem dados, uma cópia é gravada em data/backups.
+func (db *DB) migrate() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.conn.Exec(schemaMigrationsTable); err != nil {
+		return fmt.Errorf("erro ao criar schema_migrations: %w", err)
+	}
+
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	// Um banco com tabelas e sem nenhuma migration registrada foi criado por
+	// uma versão anterior a este controle.
+	legacy := false
+	if len(applied) == 0 {
+		if legacy, err = db.tableExists("projects"); err != nil {
+			return err
+		}
+	}
+
+	known := map[int]bool{}
+	var pending []*Migration
+	for _, m := range migrations {
+		known[m.Version] = true
+		a, ok := applied[m.Version]
+		if !ok {
+			pending = append(pending, m)
+			continue
+		}
+		if a.checksum != m.Checksum {
+			return fmt.Errorf("migration %s foi alterada depois de aplicada (registrado %.12s, arquivo %.12s); crie uma nova migration em vez de editar a antiga", m.Name, a.checksum, m.Checksum)
+		}
+	}
+	for version, a := range applied {
+		if !known[version] {
+			db.logger.Warn("Banco tem migration desconhecida, provavelmente de uma versão mais nova do Relief", map[string]interface{}{
+				"version": version,
+				"name":    a.name,
+			})
+		}
+	}
+
+	if len(pending) > 0 {
+		if len(applied) > 0 || legacy {
+			if err := db.backup(); err != nil {
+				return err
+			}
+		}
+		if legacy {
+			if err := db.upgradeLegacy(); err != nil {
+				return err
+			}
+		}
+
+		for _, m := range pending {
+			if err := db.applyMigration(m); err != nil {
+				return err
+			}
+			db.logger.Info("Migration aplicada", map[string]interface{}{
+				"version": m.Version,
+				"name":    m.Name,
+			})
+		}
+	}
+
+	db.setupLogSearch()
+	return nil
+}
+
+func (db *DB) applyMigration(m *Migration) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação da migration %s: %w", m.Name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.up); err != nil {
+		return fmt.Errorf("erro ao executar migration %s: %w", m.Name, err)
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+		m.Version, m.Name, m.Checksum, time.Now(),
+	); err != nil {
+		return fmt.Errorf("erro ao registrar migration %s: %w", m.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao aplicar migration %s: %w", m.Name, err)
+	}
+	return nil
+}
+
+// MigrateDown desfaz as últimas steps migrations aplicadas, da mais nova para
+// a mais antiga, usando os arquivos .down.sql. É uma ferramenta de
+// desenvolvimento: a próxima inicialização aplica tudo de novo.
+func (db *DB) MigrateDown(steps int) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	// Tudo é validado antes de mexer no banco.
+	for _, version := range versions {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("migration %03d (%s) não existe neste binário", version, applied[version].name)
+		}
+		if m.down == "" {
+			return nil, fmt.Errorf("migration %s não tem %s.down.sql", m.Name, m.Name)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, nil
+	}
+
+	if err := db.backup(); err != nil {
+		return nil, err
+	}
+
+	reverted := make([]MigrationStatus, 0, len(versions))
+	for _, version := range versions {
+		m := byVersion[version]
+		if err := db.revertMigration(m); err != nil {
+			return reverted, err
+		}
+		db.logger.Info("Migration desfeita", map[string]interface{}{
+			"version": m.Version,
+			"name":    m.Name,
+		})
+		reverted = append(reverted, MigrationStatus{Version: m.Version, Name: m.Name, HasDown: true})
+	}
+	return reverted, nil
+}
+
+func (db *DB) revertMigration(m *Migration) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação da migration %s: %w", m.Name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.down); err != nil {
+		return fmt.Errorf("erro ao desfazer migration %s: %w", m.Name, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+		return fmt.Errorf("erro ao remover registro da migration %s: %w", m.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao desfazer migration %s: %w", m.Name, err)
+	}
+	return nil
+}
+
+// Migrations lista as migrations embutidas e as registradas no banco.
+func (db *DB) Migrations() ([]MigrationStatus, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name, HasDown: m.down != ""}
+		if a, ok := applied[m.Version]; ok {
+			appliedAt := a.appliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.Modified = a.checksum != m.Checksum
+			delete(applied, m.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for version, a := range applied {
+		appliedAt := a.appliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:   version,
+			Name:      a.name,
+			Applied:   true,
+			AppliedAt: &appliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+func (db *DB) appliedMigrations() (map[int]appliedMigration, error) {
+	applied := map[int]appliedMigration{}
+	if exists, err := db.tableExists("schema_migrations"); err != nil || !exists {
+		return applied, err
+	}
+
+	rows, err := db.conn.Query(`SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return nil, fmt.Errorf("erro ao ler schema_migrations: %w", err)
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+func (db *DB) tableExists(table string) (bool, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("erro ao ler esquema do banco: %w", err)
+	}
+	return count > 0, nil
+}
+
+// upgradeLegacy leva um banco anterior a schema_migrations ao formato de
+// 001_initial.sql, que a partir daí roda sem erro por usar IF NOT EXISTS.
+// Tabelas que ainda não existem ficam para a migration.
+// As colunas abaixo foram acrescentadas a tabelas que já existiam; o SQLite
+// não suporta ADD COLUMN IF NOT EXISTS.
+func (db *DB) upgradeLegacy() error {
+	columns := []struct{ table, column, definition string }{
+		{"projects", "pgid", "INTEGER NOT NULL DEFAULT 0"},
+		{"logs", "stream", "TEXT NOT NULL DEFAULT ''"},
+		{"logs", "fields", "TEXT"},
+		{"logs", "run_id", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, c := range columns {
+		if err := db.addColumnIfMissing(c.table, c.column, c.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *DB) addColumnIfMissing(table, column, definition string) error {
+	exists, err := db.tableExists(table)
+	if err != nil || !exists {
+		return err
+	}
+
+	rows, err := db.conn.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("erro ao ler colunas de %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return fmt.Errorf("erro ao ler colunas de %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao ler colunas de %s: %w", table, err)
+	}
+	rows.Close()
+
+	if _, err := db.conn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("erro ao adicionar coluna %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// backup grava uma cópia consistente do banco em data/backups com VACUUM
+// INTO, que inclui o que ainda está no WAL, e mantém só as keepBackups mais
+// recentes.
+func (db *DB) backup() error {
+	if db.path == "" {
+		return nil
+	}
+
+	dir := filepath.Join(filepath.Dir(db.path), "backups")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de backups: %w", err)
+	}
+
+	var version int
+	_ = db.conn.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+
+	name := strings.TrimSuffix(filepath.Base(db.path), ".db")
+	backupPath := filepath.Join(dir, fmt.Sprintf("%s-%s-v%03d.db", name, time.Now().Format("20060102-150405.000"), version))
+	if _, err := db.conn.Exec(`VACUUM INTO ?`, backupPath); err != nil {
+		return fmt.Errorf("erro ao fazer backup do banco antes das migrations: %w", err)
+	}
+	db.logger.Info("Backup do banco criado", map[string]interface{}{
+		"path": backupPath,
+	})
+
+	backups, _ := filepath.Glob(filepath.Join(dir, name+"-*.db"))
+	sort.Strings(backups)
+	for len(backups) > keepBackups {
+		_ = os.Remove(backups[0])
+		backups = backups[1:]
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+-- Desfaz 001_initial.sql. Os triggers de logs_fts caem junto com a tabela logs.
+DROP TABLE IF EXISTS logs_fts;
+DROP TABLE IF EXISTS port_allocations;
+DROP TABLE IF EXISTS settings;
+DROP TABLE IF EXISTS dependencies;
+DROP TABLE IF EXISTS logs;
+DROP TABLE IF EXISTS projects;
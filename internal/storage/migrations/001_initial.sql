@@ -8,6 +8,7 @@ CREATE TABLE IF NOT EXISTS projects (
     status TEXT NOT NULL,
     port INTEGER,
     pid INTEGER,
+    pgid INTEGER NOT NULL DEFAULT 0,
     last_error TEXT,
     created_at DATETIME NOT NULL,
     updated_at DATETIME NOT NULL
@@ -22,8 +23,11 @@ CREATE INDEX IF NOT EXISTS idx_projects_domain ON projects(domain);
 CREATE TABLE IF NOT EXISTS logs (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     project_id TEXT NOT NULL,
+    run_id TEXT NOT NULL DEFAULT '',
     level TEXT NOT NULL,
+    stream TEXT NOT NULL DEFAULT '',
     message TEXT NOT NULL,
+    fields TEXT,
     timestamp DATETIME NOT NULL,
     FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
 );
@@ -32,6 +36,7 @@ CREATE TABLE IF NOT EXISTS logs (
 CREATE INDEX IF NOT EXISTS idx_logs_project_id ON logs(project_id);
 CREATE INDEX IF NOT EXISTS idx_logs_timestamp ON logs(timestamp);
 CREATE INDEX IF NOT EXISTS idx_logs_level ON logs(level);
+CREATE INDEX IF NOT EXISTS idx_logs_project_run ON logs(project_id, run_id, id);
 
 -- Tabela de dependências
 CREATE TABLE IF NOT EXISTS dependencies (
@@ -56,3 +61,13 @@ CREATE TABLE IF NOT EXISTS settings (
     value TEXT NOT NULL,
     updated_at DATETIME NOT NULL
 );
+
+-- Portas alocadas automaticamente para projetos sem porta fixa
+CREATE TABLE IF NOT EXISTS port_allocations (
+    project_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    port INTEGER NOT NULL UNIQUE,
+    allocated_at DATETIME NOT NULL,
+    PRIMARY KEY(project_id, name),
+    FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
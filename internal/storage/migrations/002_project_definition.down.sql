@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS project_changes;
+DROP TABLE IF EXISTS project_fields;
+ALTER TABLE projects DROP COLUMN git_info;
+ALTER TABLE projects DROP COLUMN manifest_hash;
+ALTER TABLE projects DROP COLUMN manifest;
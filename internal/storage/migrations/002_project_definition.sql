@@ -0,0 +1,29 @@
+-- Definição completa do projeto: snapshot do relief.yaml e informações do git
+ALTER TABLE projects ADD COLUMN manifest TEXT;
+ALTER TABLE projects ADD COLUMN manifest_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE projects ADD COLUMN git_info TEXT;
+
+-- Campos resolvidos da definição (path, domain, port, scripts.<nome>,
+-- env.<NOME>...) e de onde cada um veio (config.yaml ou relief.yaml)
+CREATE TABLE project_fields (
+    project_id TEXT NOT NULL,
+    field TEXT NOT NULL,
+    value TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY(project_id, field),
+    FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+-- Histórico de alterações da definição
+CREATE TABLE project_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id TEXT NOT NULL,
+    field TEXT NOT NULL,
+    old_value TEXT NOT NULL DEFAULT '',
+    new_value TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT '',
+    changed_at DATETIME NOT NULL,
+    FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_project_changes_project ON project_changes(project_id, id);
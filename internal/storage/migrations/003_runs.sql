@@ -0,0 +1,36 @@
+-- Execuções dos projetos: o id é o run ID usado nos logs
+CREATE TABLE runs (
+    id TEXT NOT NULL,
+    project_id TEXT NOT NULL,
+    runner TEXT NOT NULL DEFAULT '',
+    script TEXT NOT NULL DEFAULT '',
+    git_branch TEXT NOT NULL DEFAULT '',
+    git_commit TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    end_reason TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    started_at DATETIME NOT NULL,
+    ready_at DATETIME,
+    ready_ms INTEGER,
+    ended_at DATETIME,
+    exit_code INTEGER,
+    signal TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER,
+    PRIMARY KEY(project_id, id),
+    FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_runs_open ON runs(project_id, ended_at);
+
+-- Diário do ciclo de vida: start, ready, stop, exit, restart...
+CREATE TABLE events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id TEXT NOT NULL,
+    run_id TEXT NOT NULL DEFAULT '',
+    type TEXT NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_events_project_run ON events(project_id, run_id, id);
@@ -0,0 +1,22 @@
+-- Requisições atendidas pelo proxy, lidas do access log do Traefik ou
+-- registradas pelo proxy embutido
+CREATE TABLE requests (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id TEXT NOT NULL,
+    route TEXT NOT NULL DEFAULT '',
+    method TEXT NOT NULL,
+    scheme TEXT NOT NULL DEFAULT 'http',
+    host TEXT NOT NULL,
+    path TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    duration_ms REAL NOT NULL DEFAULT 0,
+    upstream TEXT NOT NULL DEFAULT '',
+    request_size INTEGER NOT NULL DEFAULT 0,
+    response_size INTEGER NOT NULL DEFAULT 0,
+    headers TEXT,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY(project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_requests_project ON requests(project_id, id);
+CREATE INDEX idx_requests_project_status ON requests(project_id, status, id);
@@ -0,0 +1,6 @@
+-- Cabeçalhos de credenciais gravados antes da redação (proxy.RedactedHeaders).
+UPDATE requests SET headers = json_set(headers, '$.Authorization', 'REDACTED') WHERE json_valid(headers) AND json_extract(headers, '$.Authorization') IS NOT NULL;
+UPDATE requests SET headers = json_set(headers, '$."Proxy-Authorization"', 'REDACTED') WHERE json_valid(headers) AND json_extract(headers, '$."Proxy-Authorization"') IS NOT NULL;
+UPDATE requests SET headers = json_set(headers, '$.Cookie', 'REDACTED') WHERE json_valid(headers) AND json_extract(headers, '$.Cookie') IS NOT NULL;
+UPDATE requests SET headers = json_set(headers, '$."Set-Cookie"', 'REDACTED') WHERE json_valid(headers) AND json_extract(headers, '$."Set-Cookie"') IS NOT NULL;
+UPDATE requests SET headers = json_set(headers, '$."X-Api-Key"', 'REDACTED') WHERE json_valid(headers) AND json_extract(headers, '$."X-Api-Key"') IS NOT NULL;
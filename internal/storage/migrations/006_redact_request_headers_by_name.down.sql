@@ -0,0 +1,2 @@
+-- Os valores redigidos não podem ser recuperados; desfazer não muda nada.
+SELECT 1;
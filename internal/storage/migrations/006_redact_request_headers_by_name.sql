@@ -0,0 +1,11 @@
+-- Cabeçalhos de credenciais fora da lista da 005: qualquer nome com token,
+-- secret, auth, key, session, cookie ou password, como em proxy.sensitiveHeader.
+UPDATE requests SET headers = (
+    SELECT json_group_object(key, CASE
+        WHEN lower(key) LIKE '%token%' OR lower(key) LIKE '%secret%' OR lower(key) LIKE '%auth%'
+          OR lower(key) LIKE '%key%' OR lower(key) LIKE '%session%' OR lower(key) LIKE '%cookie%'
+          OR lower(key) LIKE '%password%'
+        THEN 'REDACTED' ELSE value END)
+    FROM json_each(requests.headers)
+)
+WHERE json_valid(headers) AND json_type(headers) = 'object';
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// PortRepository guarda as portas alocadas automaticamente, para que cada
+// projeto receba a mesma porta entre execuções.
+type PortRepository struct {
+	db *DB
+}
+
+func NewPortRepository(db *DB) *PortRepository {
+	return &PortRepository{db: db}
+}
+
+// GetByProjectID retorna as portas do projeto por nome ("main", "debug", ...).
+func (r *PortRepository) GetByProjectID(projectID string) (map[string]int, error) {
+	rows, err := r.db.conn.Query(`SELECT name, port FROM port_allocations WHERE project_id = ?`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar portas do projeto: %w", err)
+	}
+	defer rows.Close()
+
+	ports := map[string]int{}
+	for rows.Next() {
+		var name string
+		var port int
+		if err := rows.Scan(&name, &port); err != nil {
+			return nil, err
+		}
+		ports[name] = port
+	}
+	return ports, rows.Err()
+}
+
+// ListOwners retorna, para cada porta alocada, o ID do projeto dono. Alocações
+// de projetos que não existem mais são descartadas antes.
+func (r *PortRepository) ListOwners() (map[int]string, error) {
+	if _, err := r.db.conn.Exec(`DELETE FROM port_allocations WHERE project_id NOT IN (SELECT id FROM projects)`); err != nil {
+		return nil, fmt.Errorf("erro ao limpar portas órfãs: %w", err)
+	}
+
+	rows, err := r.db.conn.Query(`SELECT port, project_id FROM port_allocations`)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar portas alocadas: %w", err)
+	}
+	defer rows.Close()
+
+	owners := map[int]string{}
+	for rows.Next() {
+		var port int
+		var projectID string
+		if err := rows.Scan(&port, &projectID); err != nil {
+			return nil, err
+		}
+		owners[port] = projectID
+	}
+	return owners, rows.Err()
+}
+
+// Save grava (ou troca) a porta com esse nome do projeto.
+func (r *PortRepository) Save(projectID, name string, port int) error {
+	_, err := r.db.conn.Exec(`
+		INSERT INTO port_allocations (project_id, name, port, allocated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(project_id, name) DO UPDATE SET port = excluded.port, allocated_at = excluded.allocated_at
+	`, projectID, name, port, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("erro ao salvar porta do projeto: %w", err)
+	}
+	return nil
+}
+
+// Release remove a porta com esse nome do projeto, quando ela passa a ser fixa.
+func (r *PortRepository) Release(projectID, name string) error {
+	if _, err := r.db.conn.Exec(`DELETE FROM port_allocations WHERE project_id = ? AND name = ?`, projectID, name); err != nil {
+		return fmt.Errorf("erro ao liberar porta do projeto: %w", err)
+	}
+	return nil
+}
@@ -2,70 +2,38 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
-	"os"
-	"strconv"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/Maycon-Santos/relief/internal/domain"
 )
 
 type ProjectRepository struct {
-	db              *DB
-	manifestCache   map[string]*cachedManifest
-	manifestCacheMu sync.RWMutex
-}
-
-type cachedManifest struct {
-	manifest *domain.Manifest
-	modTime  time.Time
+	db *DB
 }
 
 func NewProjectRepository(db *DB) *ProjectRepository {
-	return &ProjectRepository{
-		db:            db,
-		manifestCache: make(map[string]*cachedManifest),
-	}
+	return &ProjectRepository{db: db}
 }
 
-func (r *ProjectRepository) getCachedManifest(path string) (*domain.Manifest, error) {
-	yamlPath := path + "/relief.yaml"
-	info, err := os.Stat(yamlPath)
-	if err != nil {
-		return nil, err
-	}
-
-	r.manifestCacheMu.RLock()
-	cached, exists := r.manifestCache[path]
-	r.manifestCacheMu.RUnlock()
+const projectColumns = `id, name, path, domain, type, status, port, pid, pgid, last_error, created_at, updated_at, manifest, manifest_hash, git_info`
 
-	if exists && cached.modTime.Equal(info.ModTime()) {
-		return cached.manifest, nil
-	}
-
-	manifest, err := domain.ParseManifest(path)
+// Create grava o projeto e sua definição. Um projeto novo não gera registros
+// de alteração.
+func (r *ProjectRepository) Create(project *domain.Project) error {
+	manifest, err := encodeManifest(project.Manifest)
 	if err != nil {
-		return nil, err
-	}
-
-	r.manifestCacheMu.Lock()
-	r.manifestCache[path] = &cachedManifest{
-		manifest: manifest,
-		modTime:  info.ModTime(),
+		return err
 	}
-	r.manifestCacheMu.Unlock()
 
-	return manifest, nil
-}
-
-func (r *ProjectRepository) Create(project *domain.Project) error {
 	query := `
-		INSERT INTO projects (id, name, path, domain, type, status, port, pid, last_error, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO projects (id, name, path, domain, type, status, port, pid, pgid, last_error, created_at, updated_at, manifest, manifest_hash, git_info)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.conn.Exec(query,
+	_, err = r.db.conn.Exec(query,
 		project.ID,
 		project.Name,
 		project.Path,
@@ -74,9 +42,13 @@ func (r *ProjectRepository) Create(project *domain.Project) error {
 		project.Status,
 		project.Port,
 		project.PID,
+		project.PGID,
 		project.LastError,
 		project.CreatedAt,
 		project.UpdatedAt,
+		manifest,
+		project.ManifestHash,
+		encodeGitInfo(project.GitInfo),
 	)
 
 	if err != nil {
@@ -87,14 +59,21 @@ func (r *ProjectRepository) Create(project *domain.Project) error {
 		return fmt.Errorf("erro ao salvar dependências: %w", err)
 	}
 
+	if err := r.SaveDefinition(project); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// Update grava o estado de execução do projeto. A definição (scripts, env,
+// manifesto) só muda por SaveDefinition: o Env de um projeto em execução é o
+// ambiente resolvido, com segredos, e nunca deve ir para o banco.
 func (r *ProjectRepository) Update(project *domain.Project) error {
 	query := `
 		UPDATE projects 
 		SET name = ?, path = ?, domain = ?, type = ?, status = ?, port = ?, 
-		    pid = ?, last_error = ?, updated_at = ?
+		    pid = ?, pgid = ?, last_error = ?, git_info = ?, updated_at = ?
 		WHERE id = ?
 	`
 
@@ -106,7 +85,9 @@ func (r *ProjectRepository) Update(project *domain.Project) error {
 		project.Status,
 		project.Port,
 		project.PID,
+		project.PGID,
 		project.LastError,
+		encodeGitInfo(project.GitInfo),
 		time.Now().Format(time.RFC3339),
 		project.ID,
 	)
@@ -128,9 +109,24 @@ func (r *ProjectRepository) Update(project *domain.Project) error {
 }
 
 func (r *ProjectRepository) Delete(id string) error {
-	query := `DELETE FROM projects WHERE id = ?`
+	tx, err := r.db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao deletar projeto: %w", err)
+	}
+	defer tx.Rollback()
+
+	// As foreign keys não estão ativas na conexão, então o ON DELETE CASCADE
+	// não vale: a definição, o histórico, os logs (e o índice FTS, pelo
+	// trigger) e as portas reservadas são apagados explicitamente.
+	for _, table := range []string{
+		"dependencies", "logs", "port_allocations", "project_fields", "project_changes", "runs", "events", "requests",
+	} {
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE project_id = ?", table), id); err != nil {
+			return fmt.Errorf("erro ao deletar projeto: %w", err)
+		}
+	}
 
-	result, err := r.db.conn.Exec(query, id)
+	result, err := tx.Exec(`DELETE FROM projects WHERE id = ?`, id)
 	if err != nil {
 		return fmt.Errorf("erro ao deletar projeto: %w", err)
 	}
@@ -140,30 +136,19 @@ func (r *ProjectRepository) Delete(id string) error {
 		return fmt.Errorf("projeto não encontrado")
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 func (r *ProjectRepository) GetByID(id string) (*domain.Project, error) {
-	query := `
-		SELECT id, name, path, domain, type, status, port, pid, last_error, created_at, updated_at
-		FROM projects WHERE id = ?
-	`
+	return r.getOne(`SELECT `+projectColumns+` FROM projects WHERE id = ?`, id)
+}
 
-	var project domain.Project
-	err := r.db.conn.QueryRow(query, id).Scan(
-		&project.ID,
-		&project.Name,
-		&project.Path,
-		&project.Domain,
-		&project.Type,
-		&project.Status,
-		&project.Port,
-		&project.PID,
-		&project.LastError,
-		&project.CreatedAt,
-		&project.UpdatedAt,
-	)
+func (r *ProjectRepository) GetByName(name string) (*domain.Project, error) {
+	return r.getOne(`SELECT `+projectColumns+` FROM projects WHERE name = ?`, name)
+}
 
+func (r *ProjectRepository) getOne(query string, arg string) (*domain.Project, error) {
+	project, err := scanProject(r.db.conn.QueryRow(query, arg))
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("projeto não encontrado")
 	}
@@ -171,44 +156,57 @@ func (r *ProjectRepository) GetByID(id string) (*domain.Project, error) {
 		return nil, fmt.Errorf("erro ao buscar projeto: %w", err)
 	}
 
-	if project.Scripts == nil {
-		project.Scripts = make(map[string]string)
+	if err := r.hydrate(project); err != nil {
+		return nil, err
 	}
-	if project.Env == nil {
-		project.Env = make(map[string]string)
+	return project, nil
+}
+
+func (r *ProjectRepository) List() ([]*domain.Project, error) {
+	projects, err := r.ListLight()
+	if err != nil {
+		return nil, err
 	}
-	if project.Dependencies == nil {
-		project.Dependencies = []domain.Dependency{}
+
+	for _, project := range projects {
+		if err := r.hydrate(project); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := r.loadDependencies(&project); err != nil {
-		return nil, fmt.Errorf("erro ao carregar dependências: %w", err)
+	return projects, nil
+}
+
+// ListLight retorna projetos sem definição nem dependências.
+func (r *ProjectRepository) ListLight() ([]*domain.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects ORDER BY name ASC`
+
+	rows, err := r.db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar projetos: %w", err)
 	}
+	defer rows.Close()
 
-	if manifest, err := r.getCachedManifest(project.Path); err == nil {
-		project.Manifest = manifest
-		if portStr, ok := manifest.Env["PORT"]; ok {
-			if port, err := strconv.Atoi(portStr); err == nil && project.Port == 0 {
-				project.Port = port
-			}
-		} else if manifest.Ports != nil {
-			if mainPort, ok := manifest.Ports["main"]; ok && project.Port == 0 {
-				project.Port = mainPort
-			}
+	projects := []*domain.Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao scanear projeto: %w", err)
 		}
+		projects = append(projects, project)
 	}
 
-	return &project, nil
+	return projects, rows.Err()
 }
 
-func (r *ProjectRepository) GetByName(name string) (*domain.Project, error) {
-	query := `
-		SELECT id, name, path, domain, type, status, port, pid, last_error, created_at, updated_at
-		FROM projects WHERE name = ?
-	`
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+func scanProject(row rowScanner) (*domain.Project, error) {
 	var project domain.Project
-	err := r.db.conn.QueryRow(query, name).Scan(
+	var manifest, gitInfo sql.NullString
+	err := row.Scan(
 		&project.ID,
 		&project.Name,
 		&project.Path,
@@ -217,151 +215,48 @@ func (r *ProjectRepository) GetByName(name string) (*domain.Project, error) {
 		&project.Status,
 		&project.Port,
 		&project.PID,
+		&project.PGID,
 		&project.LastError,
 		&project.CreatedAt,
 		&project.UpdatedAt,
+		&manifest,
+		&project.ManifestHash,
+		&gitInfo,
 	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("projeto não encontrado")
-	}
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar projeto: %w", err)
-	}
-
-	if project.Scripts == nil {
-		project.Scripts = make(map[string]string)
-	}
-	if project.Env == nil {
-		project.Env = make(map[string]string)
-	}
-	if project.Dependencies == nil {
-		project.Dependencies = []domain.Dependency{}
-	}
-
-	if err := r.loadDependencies(&project); err != nil {
-		return nil, fmt.Errorf("erro ao carregar dependências: %w", err)
+		return nil, err
 	}
 
-	if manifest, err := r.getCachedManifest(project.Path); err == nil {
-		project.Manifest = manifest
-		if portStr, ok := manifest.Env["PORT"]; ok {
-			if port, err := strconv.Atoi(portStr); err == nil && project.Port == 0 {
-				project.Port = port
-			}
-		} else if manifest.Ports != nil {
-			if mainPort, ok := manifest.Ports["main"]; ok && project.Port == 0 {
-				project.Port = mainPort
-			}
+	project.Manifest = decodeManifest(manifest.String)
+	if gitInfo.Valid && gitInfo.String != "" {
+		var info domain.GitInfo
+		if json.Unmarshal([]byte(gitInfo.String), &info) == nil {
+			project.GitInfo = &info
 		}
 	}
-
 	return &project, nil
 }
 
-func (r *ProjectRepository) List() ([]*domain.Project, error) {
-	query := `
-		SELECT id, name, path, domain, type, status, port, pid, last_error, created_at, updated_at
-		FROM projects
-		ORDER BY name ASC
-	`
-
-	rows, err := r.db.conn.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao listar projetos: %w", err)
+// hydrate carrega dependências e definição do projeto.
+func (r *ProjectRepository) hydrate(project *domain.Project) error {
+	if err := r.loadDependencies(project); err != nil {
+		return fmt.Errorf("erro ao carregar dependências: %w", err)
 	}
-	defer rows.Close()
-
-	projects := []*domain.Project{}
-	for rows.Next() {
-		var project domain.Project
-		err := rows.Scan(
-			&project.ID,
-			&project.Name,
-			&project.Path,
-			&project.Domain,
-			&project.Type,
-			&project.Status,
-			&project.Port,
-			&project.PID,
-			&project.LastError,
-			&project.CreatedAt,
-			&project.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("erro ao scanear projeto: %w", err)
-		}
-
-		if project.Scripts == nil {
-			project.Scripts = make(map[string]string)
-		}
-		if project.Env == nil {
-			project.Env = make(map[string]string)
-		}
-		if project.Dependencies == nil {
-			project.Dependencies = []domain.Dependency{}
-		}
-
-		if err := r.loadDependencies(&project); err != nil {
-			return nil, fmt.Errorf("erro ao carregar dependências: %w", err)
-		}
-
-		if manifest, err := r.getCachedManifest(project.Path); err == nil {
-			project.Manifest = manifest
-			if portStr, ok := manifest.Env["PORT"]; ok {
-				if port, err := strconv.Atoi(portStr); err == nil && project.Port == 0 {
-					project.Port = port
-				}
-			} else if manifest.Ports != nil {
-				if mainPort, ok := manifest.Ports["main"]; ok && project.Port == 0 {
-					project.Port = mainPort
-				}
-			}
-		}
-
-		projects = append(projects, &project)
+	if err := r.loadDefinition(project); err != nil {
+		return fmt.Errorf("erro ao carregar definição do projeto: %w", err)
 	}
-
-	return projects, nil
+	return nil
 }
 
-// ListLight retorna projetos com apenas id, name, path e status (sem ParseManifest, sem dependências).
-func (r *ProjectRepository) ListLight() ([]*domain.Project, error) {
-	query := `
-		SELECT id, name, path, domain, type, status, port, pid, last_error, created_at, updated_at
-		FROM projects
-		ORDER BY name ASC
-	`
-
-	rows, err := r.db.conn.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao listar projetos: %w", err)
+func encodeGitInfo(info *domain.GitInfo) sql.NullString {
+	if info == nil {
+		return sql.NullString{}
 	}
-	defer rows.Close()
-
-	projects := []*domain.Project{}
-	for rows.Next() {
-		var project domain.Project
-		err := rows.Scan(
-			&project.ID,
-			&project.Name,
-			&project.Path,
-			&project.Domain,
-			&project.Type,
-			&project.Status,
-			&project.Port,
-			&project.PID,
-			&project.LastError,
-			&project.CreatedAt,
-			&project.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("erro ao scanear projeto: %w", err)
-		}
-		projects = append(projects, &project)
+	data, err := json.Marshal(info)
+	if err != nil {
+		return sql.NullString{}
 	}
-
-	return projects, nil
+	return sql.NullString{String: string(data), Valid: true}
 }
 
 func (r *ProjectRepository) saveDependencies(project *domain.Project) error {
@@ -433,56 +328,252 @@ func NewLogRepository(db *DB) *LogRepository {
 	return &LogRepository{db: db}
 }
 
+const (
+	defaultLogPageSize = 200
+	maxLogPageSize     = 5000
+)
+
 func (r *LogRepository) Create(log *domain.LogEntry) error {
-	query := `INSERT INTO logs (project_id, level, message, timestamp) VALUES (?, ?, ?, ?)`
+	return r.CreateBatch([]*domain.LogEntry{log})
+}
+
+// CreateBatch grava as entradas numa única transação e preenche o ID de cada
+// uma.
+func (r *LogRepository) CreateBatch(logs []*domain.LogEntry) error {
+	if len(logs) == 0 {
+		return nil
+	}
 
-	result, err := r.db.conn.Exec(query, log.ProjectID, log.Level, log.Message, log.Timestamp)
+	tx, err := r.db.conn.Begin()
 	if err != nil {
 		return fmt.Errorf("erro ao criar log: %w", err)
 	}
+	defer tx.Rollback()
 
-	id, _ := result.LastInsertId()
-	log.ID = id
-
-	return nil
-}
+	stmt, err := tx.Prepare(`INSERT INTO logs (project_id, run_id, level, stream, message, fields, timestamp) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("erro ao criar log: %w", err)
+	}
+	defer stmt.Close()
 
-func (r *LogRepository) GetByProjectID(projectID string, limit int) ([]domain.LogEntry, error) {
-	query := `
-		SELECT id, project_id, level, message, timestamp
-		FROM logs WHERE project_id = ?
-		ORDER BY timestamp DESC
-		LIMIT ?
-	`
+	for _, log := range logs {
+		var fields sql.NullString
+		if len(log.Fields) > 0 {
+			data, err := json.Marshal(log.Fields)
+			if err != nil {
+				return fmt.Errorf("erro ao serializar campos do log: %w", err)
+			}
+			fields = sql.NullString{String: string(data), Valid: true}
+		}
 
-	rows, err := r.db.conn.Query(query, projectID, limit)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar logs: %w", err)
+		result, err := stmt.Exec(log.ProjectID, log.RunID, log.Level, log.Stream, log.Message, fields, log.Timestamp)
+		if err != nil {
+			return fmt.Errorf("erro ao criar log: %w", err)
+		}
+		log.ID, _ = result.LastInsertId()
 	}
-	defer rows.Close()
 
+	return tx.Commit()
+}
+
+const logColumns = `id, project_id, run_id, level, stream, message, fields, timestamp`
+
+func scanLogs(rows *sql.Rows) ([]domain.LogEntry, error) {
 	logs := []domain.LogEntry{}
 	for rows.Next() {
 		var log domain.LogEntry
-		err := rows.Scan(&log.ID, &log.ProjectID, &log.Level, &log.Message, &log.Timestamp)
+		var fields sql.NullString
+		err := rows.Scan(&log.ID, &log.ProjectID, &log.RunID, &log.Level, &log.Stream, &log.Message, &fields, &log.Timestamp)
 		if err != nil {
 			return nil, err
 		}
+		if fields.Valid && fields.String != "" {
+			_ = json.Unmarshal([]byte(fields.String), &log.Fields)
+		}
 		logs = append(logs, log)
 	}
+	return logs, rows.Err()
+}
+
+// GetByProjectID retorna uma página de logs do projeto, da mais recente para
+// trás a partir de query.Before, com as entradas em ordem cronológica.
+func (r *LogRepository) GetByProjectID(projectID string, query domain.LogQuery) (*domain.LogPage, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultLogPageSize
+	}
+	if limit > maxLogPageSize {
+		limit = maxLogPageSize
+	}
+
+	where := []string{"project_id = ?"}
+	args := []interface{}{projectID}
+
+	if query.Before > 0 {
+		where = append(where, "id < ?")
+		args = append(args, query.Before)
+	}
+	if query.RunID != "" {
+		where = append(where, "run_id = ?")
+		args = append(args, query.RunID)
+	}
+	if query.Level != "" {
+		where = append(where, "level = ?")
+		args = append(args, query.Level)
+	}
+	if search := strings.TrimSpace(query.Search); search != "" {
+		if r.db.logSearch {
+			where = append(where, "id IN (SELECT rowid FROM logs_fts WHERE logs_fts MATCH ?)")
+			args = append(args, ftsQuery(search))
+		} else {
+			for _, term := range strings.Fields(search) {
+				where = append(where, "message LIKE ? ESCAPE '\\'")
+				args = append(args, "%"+escapeLike(term)+"%")
+			}
+		}
+	}
+
+	// Busca uma linha a mais para saber se existe página anterior.
+	args = append(args, limit+1)
+	rows, err := r.db.conn.Query(
+		`SELECT `+logColumns+` FROM logs WHERE `+strings.Join(where, " AND ")+` ORDER BY id DESC LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs, err := scanLogs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.LogPage{}
+	if len(logs) > limit {
+		logs = logs[:limit]
+		page.NextCursor = logs[limit-1].ID
+	}
 
 	for i := len(logs)/2 - 1; i >= 0; i-- {
 		opp := len(logs) - 1 - i
 		logs[i], logs[opp] = logs[opp], logs[i]
 	}
+	page.Entries = logs
+
+	return page, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *LogRepository) GetSince(projectID string, afterID int64, limit int) ([]domain.LogEntry, error) {
+	query := `
+		SELECT ` + logColumns + `
+		FROM logs WHERE project_id = ? AND id > ?
+		ORDER BY id ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.conn.Query(query, projectID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar logs: %w", err)
+	}
+	defer rows.Close()
+
+	return scanLogs(rows)
+}
+
+// CountSince conta os logs do projeto com ID maior que afterID, parando em
+// limit para não varrer um histórico grande.
+func (r *LogRepository) CountSince(projectID string, afterID int64, limit int) (int, error) {
+	var count int
+	err := r.db.conn.QueryRow(
+		`SELECT COUNT(*) FROM (SELECT 1 FROM logs WHERE project_id = ? AND id > ? LIMIT ?)`,
+		projectID, afterID, limit,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao contar logs: %w", err)
+	}
+	return count, nil
+}
+
+// ListRuns resume as execuções registradas do projeto, da mais recente para a
+// mais antiga.
+func (r *LogRepository) ListRuns(projectID string, limit int) ([]domain.LogRun, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := r.db.conn.Query(`
+		SELECT run_id, MIN(timestamp), MAX(timestamp), COUNT(*), SUM(CASE WHEN level = 'error' THEN 1 ELSE 0 END)
+		FROM logs WHERE project_id = ? AND run_id != ''
+		GROUP BY run_id
+		ORDER BY MIN(id) DESC
+		LIMIT ?
+	`, projectID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar execuções: %w", err)
+	}
+	defer rows.Close()
 
-	return logs, nil
+	runs := []domain.LogRun{}
+	for rows.Next() {
+		var run domain.LogRun
+		if err := rows.Scan(&run.RunID, &run.StartedAt, &run.EndedAt, &run.Lines, &run.Errors); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
 }
 
-func (r *LogRepository) DeleteOldLogs(olderThan time.Time) error {
-	query := `DELETE FROM logs WHERE timestamp < ?`
-	_, err := r.db.conn.Exec(query, olderThan)
-	return err
+// DeleteOldLogs remove os logs gravados antes de olderThan.
+func (r *LogRepository) DeleteOldLogs(olderThan time.Time) (int64, error) {
+	result, err := r.db.conn.Exec(`DELETE FROM logs WHERE julianday(timestamp) < julianday(?)`, olderThan.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao remover logs antigos: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// TrimToSize remove os logs mais antigos até que o texto armazenado caiba em
+// maxBytes.
+func (r *LogRepository) TrimToSize(maxBytes int64) (int64, error) {
+	var total int64
+	err := r.db.conn.QueryRow(`SELECT COALESCE(SUM(length(message) + COALESCE(length(fields), 0)), 0) FROM logs`).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao medir logs: %w", err)
+	}
+	if total <= maxBytes {
+		return 0, nil
+	}
+
+	// Libera um pouco além do excedente para não podar a cada ciclo.
+	excess := total - maxBytes + maxBytes/10
+
+	rows, err := r.db.conn.Query(`SELECT id, length(message) + COALESCE(length(fields), 0) FROM logs ORDER BY id ASC`)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao medir logs: %w", err)
+	}
+
+	var cutoff, freed int64
+	for rows.Next() && freed < excess {
+		var size int64
+		if err := rows.Scan(&cutoff, &size); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		freed += size
+	}
+	rows.Close()
+
+	result, err := r.db.conn.Exec(`DELETE FROM logs WHERE id <= ?`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao podar logs: %w", err)
+	}
+	return result.RowsAffected()
 }
 
 func (r *LogRepository) DeleteByProjectID(projectID string) error {
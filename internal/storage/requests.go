@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+)
+
+// maxProjectRequests é quantas requisições ficam guardadas por projeto.
+const maxProjectRequests = 1000
+
+const (
+	defaultRequestPageSize = 100
+	maxRequestPageSize     = 1000
+)
+
+// RequestRepository guarda as requisições atendidas pelo proxy para cada
+// projeto.
+type RequestRepository struct {
+	db *DB
+}
+
+func NewRequestRepository(db *DB) *RequestRepository {
+	return &RequestRepository{db: db}
+}
+
+const requestColumns = `id, project_id, route, method, scheme, host, path, status, duration_ms, upstream,
+	request_size, response_size, headers, created_at`
+
+// CreateBatch grava as requisições numa única transação e descarta, nos
+// projetos afetados, as mais antigas que maxProjectRequests.
+func (r *RequestRepository) CreateBatch(requests []domain.ProxyRequest) error {
+	if len(requests) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao registrar requisições: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO requests (project_id, route, method, scheme, host, path, status, duration_ms, upstream,
+			request_size, response_size, headers, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar requisições: %w", err)
+	}
+	defer stmt.Close()
+
+	projects := map[string]bool{}
+	for _, req := range requests {
+		var headers sql.NullString
+		if len(req.Headers) > 0 {
+			data, err := json.Marshal(req.Headers)
+			if err != nil {
+				return fmt.Errorf("erro ao serializar cabeçalhos: %w", err)
+			}
+			headers = sql.NullString{String: string(data), Valid: true}
+		}
+		if _, err := stmt.Exec(
+			req.ProjectID, req.Route, req.Method, req.Scheme, req.Host, req.Path, req.Status, req.DurationMs,
+			req.Upstream, req.RequestSize, req.ResponseSize, headers, req.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("erro ao registrar requisição: %w", err)
+		}
+		projects[req.ProjectID] = true
+	}
+
+	for projectID := range projects {
+		if _, err := tx.Exec(`
+			DELETE FROM requests WHERE project_id = ? AND id <= (
+				SELECT id FROM requests WHERE project_id = ? ORDER BY id DESC LIMIT 1 OFFSET ?
+			)`, projectID, projectID, maxProjectRequests,
+		); err != nil {
+			return fmt.Errorf("erro ao limpar requisições antigas: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// List retorna as requisições do projeto da mais recente para a mais antiga,
+// a partir de query.Before.
+func (r *RequestRepository) List(projectID string, query domain.RequestQuery) ([]domain.ProxyRequest, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultRequestPageSize
+	}
+	if limit > maxRequestPageSize {
+		limit = maxRequestPageSize
+	}
+
+	where := []string{"project_id = ?"}
+	args := []interface{}{projectID}
+
+	if query.Before > 0 {
+		where = append(where, "id < ?")
+		args = append(args, query.Before)
+	}
+	if status := strings.ToLower(strings.TrimSpace(query.Status)); status != "" && status != "all" {
+		clause, statusArgs, err := statusFilter(status)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, clause)
+		args = append(args, statusArgs...)
+	}
+	for _, term := range strings.Fields(query.Search) {
+		where = append(where, "path LIKE ? ESCAPE '\\'")
+		args = append(args, "%"+escapeLike(term)+"%")
+	}
+
+	args = append(args, limit)
+	rows, err := r.db.conn.Query(
+		`SELECT `+requestColumns+` FROM requests WHERE `+strings.Join(where, " AND ")+` ORDER BY id DESC LIMIT ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar requisições: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []domain.ProxyRequest{}
+	for rows.Next() {
+		req, err := scanRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *req)
+	}
+	return requests, rows.Err()
+}
+
+// Get retorna uma requisição do projeto pelo ID.
+func (r *RequestRepository) Get(projectID string, id int64) (*domain.ProxyRequest, error) {
+	row := r.db.conn.QueryRow(`SELECT `+requestColumns+` FROM requests WHERE project_id = ? AND id = ?`, projectID, id)
+	req, err := scanRequest(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("requisição %d não encontrada", id)
+	}
+	return req, err
+}
+
+func scanRequest(row rowScanner) (*domain.ProxyRequest, error) {
+	var req domain.ProxyRequest
+	var headers sql.NullString
+	if err := row.Scan(
+		&req.ID, &req.ProjectID, &req.Route, &req.Method, &req.Scheme, &req.Host, &req.Path, &req.Status,
+		&req.DurationMs, &req.Upstream, &req.RequestSize, &req.ResponseSize, &headers, &req.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("erro ao ler requisição: %w", err)
+	}
+	if headers.Valid {
+		_ = json.Unmarshal([]byte(headers.String), &req.Headers)
+	}
+	return &req, nil
+}
+
+// statusFilter traduz o filtro de status de RequestQuery numa condição SQL.
+func statusFilter(status string) (string, []interface{}, error) {
+	if status == "errors" {
+		return "status >= 400", nil, nil
+	}
+	if len(status) == 3 && strings.HasSuffix(status, "xx") && status[0] >= '1' && status[0] <= '5' {
+		class := int(status[0]-'0') * 100
+		return "status BETWEEN ? AND ?", []interface{}{class, class + 99}, nil
+	}
+	if code, err := strconv.Atoi(status); err == nil && code >= 100 && code <= 599 {
+		return "status = ?", []interface{}{code}, nil
+	}
+	return "", nil, fmt.Errorf("filtro de status inválido: %s (use um código, 2xx..5xx ou errors)", status)
+}
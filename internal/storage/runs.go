@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Maycon-Santos/relief/internal/domain"
+)
+
+// maxProjectRuns é quantas execuções ficam guardadas por projeto, com seus eventos.
+const maxProjectRuns = 200
+
+// runTimeFormat guarda milissegundos, para que tempo até ficar pronto e
+// duração saiam certos do julianday.
+const runTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// RunRepository guarda as execuções dos projetos e o diário de eventos do
+// ciclo de vida (start, ready, stop, exit...).
+type RunRepository struct {
+	db *DB
+}
+
+func NewRunRepository(db *DB) *RunRepository {
+	return &RunRepository{db: db}
+}
+
+// Create registra o início de uma execução e descarta as mais antigas que
+// maxProjectRuns.
+func (r *RunRepository) Create(run *domain.Run, startedAt time.Time) error {
+	run.StartedAt = startedAt.Format(runTimeFormat)
+	if run.Status == "" {
+		run.Status = domain.StatusStarting
+	}
+
+	if _, err := r.db.conn.Exec(`
+		INSERT INTO runs (id, project_id, runner, script, git_branch, git_commit, status, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.ID, run.ProjectID, run.Runner, run.Script, run.GitBranch, run.GitCommit, run.Status, run.StartedAt); err != nil {
+		return fmt.Errorf("erro ao registrar execução: %w", err)
+	}
+
+	if _, err := r.db.conn.Exec(`
+		DELETE FROM events WHERE project_id = ? AND run_id != '' AND run_id NOT IN (
+			SELECT id FROM runs WHERE project_id = ? ORDER BY started_at DESC LIMIT ?
+		)`, run.ProjectID, run.ProjectID, maxProjectRuns,
+	); err != nil {
+		return fmt.Errorf("erro ao limpar eventos antigos: %w", err)
+	}
+	if _, err := r.db.conn.Exec(`
+		DELETE FROM runs WHERE project_id = ? AND id NOT IN (
+			SELECT id FROM runs WHERE project_id = ? ORDER BY started_at DESC LIMIT ?
+		)`, run.ProjectID, run.ProjectID, maxProjectRuns,
+	); err != nil {
+		return fmt.Errorf("erro ao limpar execuções antigas: %w", err)
+	}
+	return nil
+}
+
+// MarkReady registra quando a execução ficou pronta e quanto tempo levou. Uma
+// execução já encerrada não muda; o retorno indica se esta foi atualizada.
+func (r *RunRepository) MarkReady(projectID, runID string, readyAt time.Time) (bool, error) {
+	at := readyAt.Format(runTimeFormat)
+	result, err := r.db.conn.Exec(`
+		UPDATE runs SET status = ?, ready_at = ?,
+			ready_ms = CAST(ROUND((julianday(?) - julianday(started_at)) * 86400000) AS INTEGER)
+		WHERE project_id = ? AND id = ? AND ended_at IS NULL
+	`, domain.StatusRunning, at, at, projectID, runID)
+	if err != nil {
+		return false, fmt.Errorf("erro ao atualizar execução: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// SetError marca com erro uma execução que continua aberta, como quando o
+// readiness probe expira com o processo ainda de pé.
+func (r *RunRepository) SetError(projectID, runID, message string) error {
+	if _, err := r.db.conn.Exec(
+		`UPDATE runs SET status = ?, error = ? WHERE project_id = ? AND id = ? AND ended_at IS NULL`,
+		domain.StatusError, message, projectID, runID,
+	); err != nil {
+		return fmt.Errorf("erro ao atualizar execução: %w", err)
+	}
+	return nil
+}
+
+// Finish encerra a execução com o status, o motivo, o erro e o código de
+// saída ou sinal de run. Uma execução já encerrada não muda; o retorno
+// indica se esta chamada a encerrou.
+func (r *RunRepository) Finish(run *domain.Run, endedAt time.Time) (bool, error) {
+	at := endedAt.Format(runTimeFormat)
+	var exitCode sql.NullInt64
+	if run.ExitCode != nil {
+		exitCode = sql.NullInt64{Int64: int64(*run.ExitCode), Valid: true}
+	}
+
+	result, err := r.db.conn.Exec(`
+		UPDATE runs SET status = ?, end_reason = ?, error = CASE WHEN ? != '' THEN ? ELSE error END,
+			ended_at = ?, exit_code = ?, signal = ?,
+			duration_ms = CAST(ROUND((julianday(?) - julianday(started_at)) * 86400000) AS INTEGER)
+		WHERE project_id = ? AND id = ? AND ended_at IS NULL
+	`, run.Status, run.EndReason, run.Error, run.Error, at, exitCode, run.Signal, at, run.ProjectID, run.ID)
+	if err != nil {
+		return false, fmt.Errorf("erro ao encerrar execução: %w", err)
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// Interrupt encerra as execuções do projeto que ficaram abertas de uma sessão
+// anterior do Relief. O fim considerado é o último log ou marco da execução,
+// não o momento da limpeza. Retorna os run IDs encerrados.
+func (r *RunRepository) Interrupt(projectID string) ([]string, error) {
+	rows, err := r.db.conn.Query(`SELECT id FROM runs WHERE project_id = ? AND ended_at IS NULL`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar execuções abertas: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	if _, err := r.db.conn.Exec(`
+		UPDATE runs SET status = ?, end_reason = ?,
+			ended_at = (
+				SELECT CASE WHEN MAX(julianday(logs.timestamp)) > julianday(COALESCE(runs.ready_at, runs.started_at))
+					THEN MAX(logs.timestamp) ELSE COALESCE(runs.ready_at, runs.started_at) END
+				FROM logs WHERE logs.project_id = runs.project_id AND logs.run_id = runs.id
+			)
+		WHERE project_id = ? AND ended_at IS NULL
+	`, domain.StatusStopped, domain.RunEndInterrupted, projectID); err != nil {
+		return nil, fmt.Errorf("erro ao encerrar execuções abertas: %w", err)
+	}
+	if _, err := r.db.conn.Exec(`
+		UPDATE runs SET duration_ms = MAX(0, CAST(ROUND((julianday(ended_at) - julianday(started_at)) * 86400000) AS INTEGER))
+		WHERE project_id = ? AND end_reason = ? AND duration_ms IS NULL
+	`, projectID, domain.RunEndInterrupted); err != nil {
+		return nil, fmt.Errorf("erro ao encerrar execuções abertas: %w", err)
+	}
+	return ids, nil
+}
+
+// LatestOpen retorna o run ID da execução do projeto ainda aberta, ou vazio.
+// Serve a quem não iniciou o projeto, como a CLI parando um projeto que a
+// janela iniciou.
+func (r *RunRepository) LatestOpen(projectID string) (string, error) {
+	var id string
+	err := r.db.conn.QueryRow(
+		`SELECT id FROM runs WHERE project_id = ? AND ended_at IS NULL ORDER BY started_at DESC LIMIT 1`,
+		projectID,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("erro ao buscar execução aberta: %w", err)
+	}
+	return id, nil
+}
+
+// AddEvent grava uma entrada no diário do projeto.
+func (r *RunRepository) AddEvent(event *domain.Event) error {
+	if event.CreatedAt == "" {
+		event.CreatedAt = time.Now().Format(runTimeFormat)
+	}
+	result, err := r.db.conn.Exec(
+		`INSERT INTO events (project_id, run_id, type, message, created_at) VALUES (?, ?, ?, ?, ?)`,
+		event.ProjectID, event.RunID, event.Type, event.Message, event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar evento: %w", err)
+	}
+	event.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// History lista as execuções do projeto, da mais recente para a mais antiga,
+// cada uma com seus eventos em ordem cronológica.
+func (r *RunRepository) History(projectID string, limit int) ([]domain.Run, error) {
+	if limit <= 0 || limit > maxProjectRuns {
+		limit = 50
+	}
+
+	rows, err := r.db.conn.Query(`
+		SELECT id, project_id, runner, script, git_branch, git_commit, status, end_reason, error,
+			started_at, ready_at, ready_ms, ended_at, exit_code, signal, duration_ms
+		FROM runs WHERE project_id = ? ORDER BY started_at DESC LIMIT ?
+	`, projectID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar execuções: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []domain.Run{}
+	index := map[string]int{}
+	for rows.Next() {
+		var run domain.Run
+		var readyAt, endedAt sql.NullString
+		var readyMs, exitCode, durationMs sql.NullInt64
+		if err := rows.Scan(
+			&run.ID, &run.ProjectID, &run.Runner, &run.Script, &run.GitBranch, &run.GitCommit,
+			&run.Status, &run.EndReason, &run.Error, &run.StartedAt, &readyAt, &readyMs,
+			&endedAt, &exitCode, &run.Signal, &durationMs,
+		); err != nil {
+			return nil, fmt.Errorf("erro ao ler execução: %w", err)
+		}
+		run.ReadyAt = readyAt.String
+		run.ReadyMs = readyMs.Int64
+		run.EndedAt = endedAt.String
+		run.DurationMs = durationMs.Int64
+		if exitCode.Valid {
+			code := int(exitCode.Int64)
+			run.ExitCode = &code
+		}
+		run.Events = []domain.Event{}
+		index[run.ID] = len(runs)
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return runs, nil
+	}
+
+	events, err := r.db.conn.Query(`
+		SELECT id, project_id, run_id, type, message, created_at
+		FROM events WHERE project_id = ? AND run_id >= ? ORDER BY id
+	`, projectID, runs[len(runs)-1].ID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar eventos: %w", err)
+	}
+	defer events.Close()
+
+	for events.Next() {
+		var e domain.Event
+		if err := events.Scan(&e.ID, &e.ProjectID, &e.RunID, &e.Type, &e.Message, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("erro ao ler evento: %w", err)
+		}
+		if i, ok := index[e.RunID]; ok {
+			runs[i].Events = append(runs[i].Events, e)
+		}
+	}
+	return runs, events.Err()
+}
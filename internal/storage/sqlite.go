@@ -5,7 +5,6 @@ import (
 	"embed"
 	"fmt"
 	"path/filepath"
-	"sort"
 
 	"github.com/Maycon-Santos/relief/pkg/fileutil"
 	"github.com/Maycon-Santos/relief/pkg/logger"
@@ -18,9 +17,33 @@ var migrationsFS embed.FS
 type DB struct {
 	conn   *sql.DB
 	logger *logger.Logger
+	path   string
+
+	// logSearch indica se a busca full-text (FTS5) está disponível. Binários
+	// compilados sem a tag sqlite_fts5 caem para LIKE.
+	logSearch bool
 }
 
 func NewDB(log *logger.Logger) (*DB, error) {
+	db, err := OpenDB(log)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("erro ao executar migrations: %w", err)
+	}
+
+	log.Info("Banco de dados inicializado", map[string]interface{}{
+		"path": db.path,
+	})
+
+	return db, nil
+}
+
+// OpenDB abre o banco sem aplicar migrations, para inspecioná-las ou desfazê-las.
+func OpenDB(log *logger.Logger) (*DB, error) {
 	dataDir, err := fileutil.GetReliefSubDir("data")
 	if err != nil {
 		return nil, fmt.Errorf("erro ao criar diretório de dados: %w", err)
@@ -39,22 +62,15 @@ func NewDB(log *logger.Logger) (*DB, error) {
 	conn.SetMaxIdleConns(5)
 
 	if err := conn.Ping(); err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("erro ao conectar ao banco: %w", err)
 	}
 
 	db := &DB{
 		conn:   conn,
 		logger: log,
+		path:   dbPath,
 	}
-
-	if err := db.migrate(); err != nil {
-		return nil, fmt.Errorf("erro ao executar migrations: %w", err)
-	}
-
-	log.Info("Banco de dados inicializado", map[string]interface{}{
-		"path": dbPath,
-	})
-
 	return db, nil
 }
 
@@ -69,45 +85,12 @@ func (db *DB) GetConn() *sql.DB {
 	return db.conn
 }
 
-func (db *DB) migrate() error {
-	entries, err := migrationsFS.ReadDir("migrations")
-	if err != nil {
-		return fmt.Errorf("erro ao ler diretório de migrations: %w", err)
-	}
-
-	var migrationFiles []string
-	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".sql" {
-			migrationFiles = append(migrationFiles, entry.Name())
-		}
-	}
-	sort.Strings(migrationFiles)
-
-	for _, filename := range migrationFiles {
-		db.logger.Info("Executando migration", map[string]interface{}{
-			"file": filename,
-		})
-
-		content, err := migrationsFS.ReadFile(filepath.Join("migrations", filename))
-		if err != nil {
-			return fmt.Errorf("erro ao ler migration %s: %w", filename, err)
-		}
-
-		if _, err := db.conn.Exec(string(content)); err != nil {
-			return fmt.Errorf("erro ao executar migration %s: %w", filename, err)
-		}
-	}
-
-	db.logger.Info("Migrations executadas com sucesso", nil)
-	return nil
-}
-
 func (db *DB) BeginTx() (*sql.Tx, error) {
 	return db.conn.Begin()
 }
 
 func (db *DB) ClearAllData() error {
-	tables := []string{"dependencies", "logs", "projects", "settings"}
+	tables := []string{"dependencies", "logs", "port_allocations", "project_fields", "project_changes", "runs", "events", "requests", "projects", "settings"}
 
 	for _, table := range tables {
 		if _, err := db.conn.Exec(fmt.Sprintf("DELETE FROM %s", table)); err != nil {
@@ -0,0 +1,323 @@
+// Package dotenv lê e escreve arquivos .env no formato usado por docker
+// compose e pelas bibliotecas dotenv: KEY=valor, "export" opcional, aspas
+// simples (literais) e duplas (com \n, \t, \" e \\), valores de várias linhas
+// entre aspas e comentários iniciados por #.
+//
+// Document guarda o texto original de cada linha, de forma que alterar uma
+// chave reescreve apenas ela: comentários, linhas em branco, ordem, aspas e
+// prefixos export do resto do arquivo ficam como estavam.
+package dotenv
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Entry é uma variável do arquivo. Quote guarda a aspa usada no valor (zero
+// quando não há), porque valores entre aspas simples não são interpolados.
+type Entry struct {
+	Key    string
+	Value  string
+	Quote  byte
+	Export bool
+	Line   int
+}
+
+// node é um trecho do arquivo: uma variável (entry != nil) ou texto que não é
+// variável (comentário, linha em branco, linha sem "="). raw é o texto exato,
+// sem a quebra de linha final.
+type node struct {
+	raw   string
+	entry *Entry
+	// prefix é o texto até o início do valor ("export KEY="); suffix, o que
+	// vem depois dele (aspas de fechamento já excluídas), como " # comentário".
+	prefix string
+	suffix string
+}
+
+// Document é um .env editável que preserva o texto original.
+type Document struct {
+	nodes        []*node
+	finalNewline bool
+	crlf         bool
+}
+
+// Read lê o arquivo em path. Um arquivo inexistente retorna os.ErrNotExist.
+func Read(path string) ([]Entry, error) {
+	doc, err := ReadDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Entries(), nil
+}
+
+// ReadDocument lê o arquivo em path como Document.
+func ReadDocument(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := ParseDocument(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Parse interpreta o conteúdo de um .env. Linhas sem "=" são ignoradas; uma
+// chave repetida aparece mais de uma vez, e a última vence.
+func Parse(data string) ([]Entry, error) {
+	doc, err := ParseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Entries(), nil
+}
+
+// ParseDocument interpreta o conteúdo de um .env guardando o texto original.
+func ParseDocument(data string) (*Document, error) {
+	doc := &Document{crlf: strings.Contains(data, "\r\n")}
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	doc.finalNewline = data == "" || strings.HasSuffix(data, "\n")
+	var lines []string
+	if data != "" {
+		lines = strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+	}
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		raw := lines[i]
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			doc.nodes = append(doc.nodes, &node{raw: raw})
+			continue
+		}
+
+		indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+		body := raw[indent:]
+		export := false
+		if strings.HasPrefix(body, "export ") {
+			export = true
+			trimmed := strings.TrimLeft(body[len("export "):], " \t")
+			indent += len(body) - len(trimmed)
+			body = trimmed
+		}
+
+		eq := strings.IndexByte(body, '=')
+		if eq <= 0 {
+			doc.nodes = append(doc.nodes, &node{raw: raw})
+			continue
+		}
+		key := strings.TrimSpace(body[:eq])
+		valueStart := indent + eq + 1
+		valueStart += len(raw[valueStart:]) - len(strings.TrimLeft(raw[valueStart:], " \t"))
+		rest := raw[valueStart:]
+
+		entry := &Entry{Key: key, Export: export, Line: lineNo}
+		n := &node{entry: entry, prefix: raw[:valueStart]}
+
+		if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			quote := rest[0]
+			// Valores entre aspas podem ocupar várias linhas.
+			value := rest[1:]
+			end := closingQuote(value, quote)
+			for end < 0 && i+1 < len(lines) {
+				i++
+				raw += "\n" + lines[i]
+				value += "\n" + lines[i]
+				end = closingQuote(value, quote)
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("linha %d: aspas de %s não fechadas", lineNo, key)
+			}
+
+			entry.Quote = quote
+			entry.Value = value[:end]
+			if quote == '"' {
+				entry.Value = unescape(entry.Value)
+			}
+			n.suffix = value[end+1:]
+		} else {
+			// Em valores sem aspas, " #" começa um comentário.
+			value := rest
+			if idx := strings.Index(rest, " #"); idx >= 0 {
+				value = rest[:idx]
+			}
+			entry.Value = strings.TrimRight(value, " \t")
+			n.suffix = rest[len(entry.Value):]
+		}
+
+		n.raw = raw
+		doc.nodes = append(doc.nodes, n)
+	}
+	return doc, nil
+}
+
+// Entries lista as variáveis na ordem do arquivo.
+func (d *Document) Entries() []Entry {
+	entries := []Entry{}
+	for _, n := range d.nodes {
+		if n.entry != nil {
+			entries = append(entries, *n.entry)
+		}
+	}
+	return entries
+}
+
+// Get retorna o valor da última ocorrência da chave.
+func (d *Document) Get(key string) (string, bool) {
+	if n := d.find(key); n != nil {
+		return n.entry.Value, true
+	}
+	return "", false
+}
+
+// Set altera a última ocorrência da chave, mantendo export, aspas e
+// comentário da linha, ou acrescenta a chave ao fim do arquivo. Retorna se o
+// documento mudou.
+func (d *Document) Set(key, value string) bool {
+	if n := d.find(key); n != nil {
+		if n.entry.Value == value {
+			return false
+		}
+		quote := n.entry.Quote
+		if !canQuote(value, quote) {
+			quote = '"'
+		}
+		n.entry.Value = value
+		n.entry.Quote = quote
+		n.raw = n.prefix + encode(value, quote) + n.suffix
+		return true
+	}
+
+	quote := byte(0)
+	if !canQuote(value, 0) {
+		quote = '"'
+	}
+	entry := &Entry{Key: key, Value: value, Quote: quote}
+	prefix := key + "="
+	d.nodes = append(d.nodes, &node{raw: prefix + encode(value, quote), entry: entry, prefix: prefix})
+	return true
+}
+
+// AddComment acrescenta uma linha de comentário ao fim do arquivo.
+func (d *Document) AddComment(text string) {
+	d.nodes = append(d.nodes, &node{raw: "# " + text})
+}
+
+// String devolve o arquivo, idêntico ao original nas partes não alteradas
+// (inclusive quebras de linha \r\n).
+func (d *Document) String() string {
+	newline := "\n"
+	if d.crlf {
+		newline = "\r\n"
+	}
+
+	var sb strings.Builder
+	for i, n := range d.nodes {
+		if i > 0 {
+			sb.WriteString(newline)
+		}
+		sb.WriteString(strings.ReplaceAll(n.raw, "\n", newline))
+	}
+	if len(d.nodes) > 0 && d.finalNewline {
+		sb.WriteString(newline)
+	}
+	return sb.String()
+}
+
+// HasComment indica se o arquivo tem a linha de comentário "# text".
+func (d *Document) HasComment(text string) bool {
+	for _, n := range d.nodes {
+		if n.entry == nil && strings.TrimSpace(n.raw) == "# "+text {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Document) find(key string) *node {
+	for i := len(d.nodes) - 1; i >= 0; i-- {
+		if e := d.nodes[i].entry; e != nil && e.Key == key {
+			return d.nodes[i]
+		}
+	}
+	return nil
+}
+
+// ToMap reduz as entradas a um mapa, com a última ocorrência de cada chave.
+func ToMap(entries []Entry) map[string]string {
+	values := make(map[string]string, len(entries))
+	for _, e := range entries {
+		values[e.Key] = e.Value
+	}
+	return values
+}
+
+// canQuote indica se value pode ser escrito com a aspa quote (0 = sem aspas)
+// e lido de volta igual.
+func canQuote(value string, quote byte) bool {
+	switch quote {
+	case '\'':
+		return !strings.ContainsAny(value, "'\n")
+	case '"':
+		return true
+	default:
+		return value != "" && !strings.ContainsAny(value, " \t\n\r\"'\\#")
+	}
+}
+
+func encode(value string, quote byte) string {
+	switch quote {
+	case '\'':
+		return "'" + value + "'"
+	case '"':
+		r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+		return `"` + r.Replace(value) + `"`
+	default:
+		return value
+	}
+}
+
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case '"', '\\':
+			sb.WriteByte(s[i])
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+// Package instancelock garante que só uma instância do Relief por vez
+// gerencie os processos dos projetos. O lock é de sistema operacional (flock
+// no Unix, arquivo aberto sem compartilhamento no Windows) e some junto com o
+// processo que o tem, então um lock ocupado sempre pertence a uma instância
+// viva.
+package instancelock
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Lock é o lock exclusivo tomado por Acquire.
+type Lock struct {
+	file *os.File
+}
+
+// HeldError indica que outra instância viva tem o lock. PID é 0 quando o
+// sistema não deixa lê-lo.
+type HeldError struct {
+	PID int
+}
+
+func (e *HeldError) Error() string {
+	if e.PID > 0 {
+		return fmt.Sprintf("outra instância do Relief (PID %d) está em execução", e.PID)
+	}
+	return "outra instância do Relief está em execução"
+}
+
+// Acquire toma o lock em path sem esperar e grava nele o PID do processo. Com
+// o lock ocupado, retorna *HeldError.
+func Acquire(path string) (*Lock, error) {
+	file, err := lockFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := file.Truncate(0); err == nil {
+		_, _ = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &Lock{file: file}, nil
+}
+
+// Release solta o lock. O arquivo fica no lugar: removê-lo deixaria uma
+// instância que acabou de abri-lo com um lock que ninguém mais vê.
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
+
+func holderPID(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid
+}
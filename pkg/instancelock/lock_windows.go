@@ -0,0 +1,37 @@
+//go:build windows
+
+package instancelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// errorSharingViolation é o ERROR_SHARING_VIOLATION, que o pacote syscall
+// não define.
+const errorSharingViolation syscall.Errno = 32
+
+// lockFile abre o arquivo sem compartilhamento: enquanto ele estiver aberto,
+// outro processo não consegue abri-lo. Por isso o PID de quem tem o lock não
+// pode ser lido e HeldError vem sem ele.
+func lockFile(path string) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	handle, err := syscall.CreateFile(name, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil,
+		syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		if errors.Is(err, errorSharingViolation) {
+			return nil, &HeldError{}
+		}
+		return nil, fmt.Errorf("erro ao tomar o lock da instância: %w", err)
+	}
+	return os.NewFile(uintptr(handle), path), nil
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
@@ -0,0 +1,160 @@
+package portowner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// tcpListen é o estado TCP_LISTEN na coluna "st" de /proc/net/tcp.
+const tcpListen = "0A"
+
+// procBackend lê /proc/net/tcp{,6} para achar os inodes dos sockets em LISTEN
+// e depois /proc/<pid>/fd para saber quais processos têm esses inodes abertos.
+type procBackend struct {
+	root string
+}
+
+func newPlatformBackend() Backend {
+	return &procBackend{root: "/proc"}
+}
+
+func (b *procBackend) Listeners(pids ...int) ([]Listener, error) {
+	inodes := map[string]int{}
+	for _, file := range []string{"net/tcp", "net/tcp6"} {
+		if err := b.readListenInodes(filepath.Join(b.root, file), inodes); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if len(inodes) == 0 {
+		return nil, nil
+	}
+
+	scanAll := len(pids) == 0
+	if scanAll {
+		var err error
+		if pids, err = b.pids(); err != nil {
+			return nil, err
+		}
+	}
+
+	owned := map[string]bool{}
+	seen := map[Listener]bool{}
+	listeners := []Listener{}
+	for _, pid := range pids {
+		fdDir := filepath.Join(b.root, strconv.Itoa(pid), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")
+			port, ok := inodes[inode]
+			if !ok {
+				continue
+			}
+			owned[inode] = true
+			if l := (Listener{Port: port, PID: pid}); !seen[l] {
+				seen[l] = true
+				listeners = append(listeners, l)
+			}
+		}
+	}
+
+	// Sockets que nenhum processo visível possui continuam ocupando a porta.
+	if scanAll {
+		for inode, port := range inodes {
+			if l := (Listener{Port: port}); !owned[inode] && !seen[l] {
+				seen[l] = true
+				listeners = append(listeners, l)
+			}
+		}
+	}
+	return listeners, nil
+}
+
+func (b *procBackend) readListenInodes(path string, inodes map[string]int) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // cabeçalho
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListen {
+			continue
+		}
+		colon := strings.LastIndexByte(fields[1], ':')
+		if colon < 0 {
+			continue
+		}
+		port, err := strconv.ParseInt(fields[1][colon+1:], 16, 32)
+		if err != nil {
+			continue
+		}
+		// Inode 0 é um socket já fechado esperando o kernel liberar.
+		if fields[9] != "0" {
+			inodes[fields[9]] = int(port)
+		}
+	}
+	return scanner.Err()
+}
+
+func (b *procBackend) pids() ([]int, error) {
+	entries, err := os.ReadDir(b.root)
+	if err != nil {
+		return nil, err
+	}
+	pids := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
+
+func (b *procBackend) Processes() ([]Process, error) {
+	pids, err := b.pids()
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make([]Process, 0, len(pids))
+	for _, pid := range pids {
+		dir := filepath.Join(b.root, strconv.Itoa(pid))
+		stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+		if err != nil {
+			continue
+		}
+
+		// O nome do comando vem entre parênteses e pode conter espaços.
+		line := string(stat)
+		open, end := strings.IndexByte(line, '('), strings.LastIndexByte(line, ')')
+		if open < 0 || end < open {
+			continue
+		}
+		fields := strings.Fields(line[end+1:])
+		if len(fields) < 2 {
+			continue
+		}
+		ppid, _ := strconv.Atoi(fields[1])
+
+		command := "[" + line[open+1:end] + "]"
+		if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil && len(cmdline) > 0 {
+			command = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+		}
+
+		processes = append(processes, Process{PID: pid, PPID: ppid, Command: command})
+	}
+	return processes, nil
+}
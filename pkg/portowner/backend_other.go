@@ -0,0 +1,85 @@
+//go:build !linux && !windows
+
+package portowner
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// lsofBackend cobre macOS e BSDs, onde não há /proc: os sockets vêm do lsof
+// (que acompanha o sistema no macOS) e a árvore de processos do ps.
+type lsofBackend struct{}
+
+func newPlatformBackend() Backend {
+	return lsofBackend{}
+}
+
+func (lsofBackend) Listeners(pids ...int) ([]Listener, error) {
+	args := []string{"-nP", "-iTCP", "-sTCP:LISTEN", "-F", "pn"}
+	if len(pids) > 0 {
+		list := make([]string, len(pids))
+		for i, pid := range pids {
+			list[i] = strconv.Itoa(pid)
+		}
+		args = append(args, "-a", "-p", strings.Join(list, ","))
+	}
+
+	// lsof sai com status 1 quando nenhum socket combina com o filtro.
+	output, err := exec.Command("lsof", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+			return nil, err
+		}
+	}
+
+	seen := map[Listener]bool{}
+	listeners := []Listener{}
+	pid := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			pid, _ = strconv.Atoi(line[1:])
+		case 'n':
+			colon := strings.LastIndexByte(line, ':')
+			if colon < 0 {
+				continue
+			}
+			port, err := strconv.Atoi(line[colon+1:])
+			if err != nil {
+				continue
+			}
+			if l := (Listener{Port: port, PID: pid}); !seen[l] {
+				seen[l] = true
+				listeners = append(listeners, l)
+			}
+		}
+	}
+	return listeners, nil
+}
+
+func (lsofBackend) Processes() ([]Process, error) {
+	output, err := exec.Command("ps", "-axo", "pid=,ppid=,command=").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	processes := []Process{}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		ppid, _ := strconv.Atoi(fields[1])
+		processes = append(processes, Process{PID: pid, PPID: ppid, Command: strings.Join(fields[2:], " ")})
+	}
+	return processes, nil
+}
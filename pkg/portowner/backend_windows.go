@@ -0,0 +1,92 @@
+package portowner
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// netstatBackend usa o netstat e o CIM do PowerShell, que existem em qualquer
+// Windows suportado. A coluna de estado do netstat é traduzida conforme o
+// idioma do sistema, então o LISTEN é reconhecido pelo endereço remoto vazio.
+type netstatBackend struct{}
+
+func newPlatformBackend() Backend {
+	return netstatBackend{}
+}
+
+func (netstatBackend) Listeners(pids ...int) ([]Listener, error) {
+	filter := map[int]bool{}
+	for _, pid := range pids {
+		filter[pid] = true
+	}
+
+	seen := map[Listener]bool{}
+	listeners := []Listener{}
+	for _, proto := range []string{"TCP", "TCPv6"} {
+		output, err := exec.Command("netstat", "-ano", "-p", proto).Output()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, line := range strings.Split(string(output), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 5 || !strings.HasPrefix(fields[0], "TCP") {
+				continue
+			}
+			if remote := fields[2]; remote != "0.0.0.0:0" && remote != "[::]:0" {
+				continue
+			}
+			colon := strings.LastIndexByte(fields[1], ':')
+			if colon < 0 {
+				continue
+			}
+			port, err := strconv.Atoi(fields[1][colon+1:])
+			if err != nil {
+				continue
+			}
+			pid, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil {
+				continue
+			}
+			if len(filter) > 0 && !filter[pid] {
+				continue
+			}
+			if l := (Listener{Port: port, PID: pid}); !seen[l] {
+				seen[l] = true
+				listeners = append(listeners, l)
+			}
+		}
+	}
+	return listeners, nil
+}
+
+func (netstatBackend) Processes() ([]Process, error) {
+	output, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command",
+		"Get-CimInstance Win32_Process | Select-Object ProcessId,ParentProcessId,CommandLine,Name | ConvertTo-Json -Compress",
+	).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []struct {
+		ProcessID       int    `json:"ProcessId"`
+		ParentProcessID int    `json:"ParentProcessId"`
+		CommandLine     string `json:"CommandLine"`
+		Name            string `json:"Name"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, err
+	}
+
+	processes := make([]Process, 0, len(raw))
+	for _, p := range raw {
+		command := p.CommandLine
+		if command == "" {
+			command = p.Name
+		}
+		processes = append(processes, Process{PID: p.ProcessID, PPID: p.ParentProcessID, Command: command})
+	}
+	return processes, nil
+}
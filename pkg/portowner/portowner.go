@@ -0,0 +1,125 @@
+// Package portowner descobre quais processos estão escutando em uma porta TCP.
+// Cada sistema tem o seu Backend: no Linux tudo vem do /proc, sem lsof/ps; no
+// Windows do netstat; no macOS e BSDs, que não têm /proc, do lsof e do ps.
+package portowner
+
+import (
+	"sort"
+)
+
+// Backend é a fonte de dados de uma plataforma.
+type Backend interface {
+	// Listeners lista os sockets TCP em LISTEN. Com pids, apenas os sockets
+	// desses processos. PID 0 indica um socket cujo dono não pôde ser lido
+	// (processo de outro usuário, por exemplo).
+	Listeners(pids ...int) ([]Listener, error)
+
+	// Processes lista os processos do sistema com PID do pai e linha de comando.
+	Processes() ([]Process, error)
+}
+
+type Listener struct {
+	Port int `json:"port"`
+	PID  int `json:"pid"`
+}
+
+type Process struct {
+	PID     int    `json:"pid"`
+	PPID    int    `json:"ppid"`
+	Command string `json:"command"`
+}
+
+// Owner é um processo escutando na porta, com quem o iniciou (do pai até o
+// init) e tudo o que ele iniciou.
+type Owner struct {
+	Process
+	Ancestors   []Process `json:"ancestors,omitempty"`
+	Descendants []Process `json:"descendants,omitempty"`
+}
+
+var platform Backend = newPlatformBackend()
+
+// Lookup retorna todos os donos da porta, em ordem de PID.
+func Lookup(port int) ([]Owner, error) {
+	return LookupWith(platform, port)
+}
+
+// Listeners lista os sockets em LISTEN usando o backend da plataforma.
+func Listeners(pids ...int) ([]Listener, error) {
+	return platform.Listeners(pids...)
+}
+
+func LookupWith(backend Backend, port int) ([]Owner, error) {
+	listeners, err := backend.Listeners()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[int]bool{}
+	pids := []int{}
+	for _, l := range listeners {
+		if l.Port == port && !seen[l.PID] {
+			seen[l.PID] = true
+			pids = append(pids, l.PID)
+		}
+	}
+	if len(pids) == 0 {
+		return nil, nil
+	}
+	sort.Ints(pids)
+
+	// Sem a lista de processos ainda dá para informar os PIDs.
+	processes, _ := backend.Processes()
+	byPID := make(map[int]Process, len(processes))
+	children := map[int][]int{}
+	for _, p := range processes {
+		byPID[p.PID] = p
+		if p.PPID != p.PID {
+			children[p.PPID] = append(children[p.PPID], p.PID)
+		}
+	}
+
+	owners := make([]Owner, 0, len(pids))
+	for _, pid := range pids {
+		owner := Owner{Process: Process{PID: pid}}
+		if p, ok := byPID[pid]; ok && pid > 0 {
+			owner.Process = p
+			owner.Ancestors = ancestors(byPID, p)
+			owner.Descendants = descendants(byPID, children, pid)
+		}
+		owners = append(owners, owner)
+	}
+	return owners, nil
+}
+
+func ancestors(byPID map[int]Process, p Process) []Process {
+	chain := []Process{}
+	visited := map[int]bool{p.PID: true}
+	for ppid := p.PPID; ppid > 0 && !visited[ppid]; {
+		parent, ok := byPID[ppid]
+		if !ok {
+			break
+		}
+		visited[ppid] = true
+		chain = append(chain, parent)
+		ppid = parent.PPID
+	}
+	return chain
+}
+
+func descendants(byPID map[int]Process, children map[int][]int, pid int) []Process {
+	result := []Process{}
+	visited := map[int]bool{pid: true}
+	queue := append([]int(nil), children[pid]...)
+	for len(queue) > 0 {
+		child := queue[0]
+		queue = queue[1:]
+		if visited[child] {
+			continue
+		}
+		visited[child] = true
+		result = append(result, byPID[child])
+		queue = append(queue, children[child]...)
+	}
+	return result
+}